@@ -0,0 +1,58 @@
+package keeper
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/gokulsan/MoorApp/x/moorchat/types"
+)
+
+// RegisterInvariants registers all moorchat invariants with ir.
+func RegisterInvariants(k Keeper, ir sdk.InvariantRegistry) {
+	ir.RegisterRoute(types.ModuleName, "message-channel-exists", MessageChannelExistsInvariant(k))
+	ir.RegisterRoute(types.ModuleName, "message-counter-monotone", MessageCounterMonotoneInvariant(k))
+}
+
+// MessageChannelExistsInvariant checks that every stored message references a channel that
+// still exists, ie. that a channel is never deleted out from under messages already posted
+// to it.
+func MessageChannelExistsInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		var broken []string
+
+		k.IterateMessages(ctx, func(msg types.ChatMessage) bool {
+			if !k.HasChannel(ctx, msg.Channel) {
+				broken = append(broken, fmt.Sprintf("message from %s references missing channel %q", msg.Sender, msg.Channel))
+			}
+			return false
+		})
+
+		return sdk.FormatInvariant(types.ModuleName, "message-channel-exists",
+			fmt.Sprintf("%d messages reference a missing channel\n%s", len(broken), broken)), len(broken) != 0
+	}
+}
+
+// MessageCounterMonotoneInvariant checks that every sender's per-user message counter is at
+// least as large as the number of messages from them actually found in the store, ie. that
+// the counter is never decremented or skipped past an increment.
+func MessageCounterMonotoneInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		counts := make(map[string]uint64)
+
+		k.IterateMessages(ctx, func(msg types.ChatMessage) bool {
+			counts[msg.Sender]++
+			return false
+		})
+
+		var broken []string
+		for sender, count := range counts {
+			if counter := k.GetMessageCounter(ctx, sender); counter < count {
+				broken = append(broken, fmt.Sprintf("%s sent %d messages but counter reads %d", sender, count, counter))
+			}
+		}
+
+		return sdk.FormatInvariant(types.ModuleName, "message-counter-monotone",
+			fmt.Sprintf("%d senders have a stale message counter\n%s", len(broken), broken)), len(broken) != 0
+	}
+}
@@ -92,7 +92,9 @@ func (AppModule) Name() string {
 }
 
 // RegisterInvariants registers the moorchat module invariants.
-func (am AppModule) RegisterInvariants(_ sdk.InvariantRegistry) {}
+func (am AppModule) RegisterInvariants(ir sdk.InvariantRegistry) {
+	keeper.RegisterInvariants(am.keeper, ir)
+}
 
 // Route returns the message routing key for the moorchat module.
 func (AppModule) Route() string {
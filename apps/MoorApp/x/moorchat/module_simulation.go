@@ -0,0 +1,60 @@
+package moorchat
+
+import (
+	"math/rand"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	sim "github.com/cosmos/cosmos-sdk/x/simulation"
+
+	"github.com/gokulsan/MoorApp/x/moorchat/simulation"
+	"github.com/gokulsan/MoorApp/x/moorchat/types"
+)
+
+// Type check to ensure AppModule properly implements AppModuleSimulation.
+var _ module.AppModuleSimulation = AppModule{}
+
+// GenerateGenesisState creates a randomized GenesisState for moorchat, given simState's rng
+// and the number of accounts it provides.
+func (AppModule) GenerateGenesisState(simState *module.SimulationState) {
+	channels := make([]types.Channel, 0, len(simState.Accounts)/2+1)
+	for i := 0; i < len(simState.Accounts)/2+1; i++ {
+		acc := simState.Accounts[i%len(simState.Accounts)]
+		channels = append(channels, types.Channel{
+			Name:    sim.RandStringOfLength(simState.Rand, 10),
+			Creator: acc.Address.String(),
+		})
+	}
+
+	genesisState := types.GenesisState{
+		Channels: channels,
+	}
+
+	simState.GenState[ModuleName] = types.ModuleCdc.MustMarshalJSON(genesisState)
+}
+
+// ProposalContents returns no governance proposal contents for moorchat: a chat channel isn't
+// governance-parameterized, so there's nothing for the simulator to propose.
+func (AppModule) ProposalContents(_ module.SimulationState) []sim.WeightedProposalContent {
+	return nil
+}
+
+// RandomizedParams returns no randomized params for moorchat: the module has none to
+// perturb via a param-change proposal.
+func (AppModule) RandomizedParams(_ *rand.Rand) []sim.ParamChange {
+	return nil
+}
+
+// RegisterStoreDecoder registers a decoder for every store prefix the moorchat keeper
+// writes, so the simulator's crisis-check diff between two KVStore values can render
+// moorchat's raw bytes as something readable instead of a hex blob.
+func (AppModule) RegisterStoreDecoder(sdr sdk.StoreDecoderRegistry) {
+	sdr[StoreKey] = simulation.DecodeStore
+}
+
+// WeightedOperations returns the weighted simulator operations moorchat contributes: posting a
+// chat message and creating a channel, each via the same keeper.Keeper methods the real
+// message handler goes through.
+func (am AppModule) WeightedOperations(simState module.SimulationState) []sim.WeightedOperation {
+	return simulation.WeightedOperations(simState.AppParams, simState.Cdc, am.keeper)
+}
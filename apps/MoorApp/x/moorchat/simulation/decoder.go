@@ -0,0 +1,38 @@
+package simulation
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	tmkv "github.com/tendermint/tendermint/libs/kv"
+
+	"github.com/gokulsan/MoorApp/x/moorchat/types"
+)
+
+// DecodeStore unmarshals the value half of a moorchat KVStore pair as a readable string, keyed
+// off the prefix byte in kvA's key, for the simulator's TestAppStateDeterminism store diff.
+func DecodeStore(cdc *codec.Codec, kvA, kvB tmkv.Pair) string {
+	switch {
+	case bytes.Equal(kvA.Key[:1], types.ChannelKey):
+		var channelA, channelB types.Channel
+		cdc.MustUnmarshalBinaryBare(kvA.Value, &channelA)
+		cdc.MustUnmarshalBinaryBare(kvB.Value, &channelB)
+		return fmt.Sprintf("%v\n%v", channelA, channelB)
+
+	case bytes.Equal(kvA.Key[:1], types.MessageKey):
+		var msgA, msgB types.ChatMessage
+		cdc.MustUnmarshalBinaryBare(kvA.Value, &msgA)
+		cdc.MustUnmarshalBinaryBare(kvB.Value, &msgB)
+		return fmt.Sprintf("%v\n%v", msgA, msgB)
+
+	case bytes.Equal(kvA.Key[:1], types.MessageCounterKey):
+		counterA := sdk.BigEndianToUint64(kvA.Value)
+		counterB := sdk.BigEndianToUint64(kvB.Value)
+		return fmt.Sprintf("%d\n%d", counterA, counterB)
+
+	default:
+		panic(fmt.Sprintf("invalid moorchat key prefix %X", kvA.Key[:1]))
+	}
+}
@@ -0,0 +1,49 @@
+package simulation_test
+
+import (
+	"fmt"
+	"testing"
+
+	tmkv "github.com/tendermint/tendermint/libs/kv"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gokulsan/MoorApp/x/moorchat/simulation"
+	"github.com/gokulsan/MoorApp/x/moorchat/types"
+)
+
+func TestDecodeStore(t *testing.T) {
+	cdc := types.ModuleCdc
+
+	channel := types.Channel{Name: "general", Creator: "cosmos1abc"}
+	msg := types.ChatMessage{Channel: "general", Sender: "cosmos1abc", Body: "hi", Timestamp: 1}
+
+	kvPairs := []tmkv.Pair{
+		tmkv.Pair{Key: types.ChannelKey, Value: types.ModuleCdc.MustMarshalBinaryBare(channel)},
+		tmkv.Pair{Key: types.MessageKey, Value: types.ModuleCdc.MustMarshalBinaryBare(msg)},
+		tmkv.Pair{Key: types.MessageCounterKey, Value: []byte{0, 0, 0, 0, 0, 0, 0, 3}},
+		tmkv.Pair{Key: []byte{0xff}, Value: []byte{}},
+	}
+
+	tests := []struct {
+		name        string
+		expectedLog string
+		panics      bool
+	}{
+		{"Channel", fmt.Sprintf("%v\n%v", channel, channel), false},
+		{"ChatMessage", fmt.Sprintf("%v\n%v", msg, msg), false},
+		{"MessageCounter", fmt.Sprintf("%d\n%d", 3, 3), false},
+		{"other", "", true},
+	}
+
+	for i, tt := range tests {
+		i, tt := i, tt
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.panics {
+				require.Panics(t, func() { simulation.DecodeStore(cdc, kvPairs[i], kvPairs[i]) }, tt.name)
+			} else {
+				require.Equal(t, tt.expectedLog, simulation.DecodeStore(cdc, kvPairs[i], kvPairs[i]), tt.name)
+			}
+		})
+	}
+}
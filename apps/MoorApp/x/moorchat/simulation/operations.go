@@ -0,0 +1,87 @@
+package simulation
+
+import (
+	"math/rand"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/simulation"
+
+	"github.com/gokulsan/MoorApp/x/moorchat/keeper"
+	"github.com/gokulsan/MoorApp/x/moorchat/types"
+)
+
+const (
+	opWeightMsgSendChat      = "op_weight_msg_send_chat"
+	opWeightMsgCreateChannel = "op_weight_msg_create_channel"
+
+	defaultWeightMsgSendChat      = 100
+	defaultWeightMsgCreateChannel = 20
+)
+
+// WeightedOperations returns the weighted set of operations the moorchat simulator runs:
+// creating channels and sending chat messages to them, weighted so a run sees far more
+// messages than channel creations, the way a real chain would.
+func WeightedOperations(appParams simulation.AppParams, cdc *codec.Codec, k keeper.Keeper) simulation.WeightedOperations {
+	var weightMsgSendChat, weightMsgCreateChannel int
+
+	appParams.GetOrGenerate(cdc, opWeightMsgSendChat, &weightMsgSendChat, nil,
+		func(_ *rand.Rand) { weightMsgSendChat = defaultWeightMsgSendChat })
+
+	appParams.GetOrGenerate(cdc, opWeightMsgCreateChannel, &weightMsgCreateChannel, nil,
+		func(_ *rand.Rand) { weightMsgCreateChannel = defaultWeightMsgCreateChannel })
+
+	return simulation.WeightedOperations{
+		simulation.NewWeightedOperation(weightMsgCreateChannel, SimulateMsgCreateChannel(k)),
+		simulation.NewWeightedOperation(weightMsgSendChat, SimulateMsgSendChat(k)),
+	}
+}
+
+// SimulateMsgCreateChannel generates a MsgCreateChannel with a random creator and a random
+// name, and delivers it through the keeper exactly as the real message handler would.
+func SimulateMsgCreateChannel(k keeper.Keeper) simulation.Operation {
+	return func(
+		r *rand.Rand, _ *baseapp.BaseApp, ctx sdk.Context, accs []simulation.Account, _ string,
+	) (simulation.OperationMsg, []simulation.FutureOperation, error) {
+		simAccount, _ := simulation.RandomAcc(r, accs)
+		name := simulation.RandStringOfLength(r, 10)
+
+		if k.HasChannel(ctx, name) {
+			return simulation.NoOpMsg(types.ModuleName), nil, nil
+		}
+
+		msg := types.NewMsgCreateChannel(simAccount.Address.String(), name)
+
+		if err := k.CreateChannel(ctx, msg); err != nil {
+			return simulation.NoOpMsg(types.ModuleName), nil, err
+		}
+
+		return simulation.NewOperationMsg(msg, true, ""), nil, nil
+	}
+}
+
+// SimulateMsgSendChat picks a random existing channel and a random sender, and delivers a
+// MsgSendChat to it through the keeper exactly as the real message handler would.
+func SimulateMsgSendChat(k keeper.Keeper) simulation.Operation {
+	return func(
+		r *rand.Rand, _ *baseapp.BaseApp, ctx sdk.Context, accs []simulation.Account, _ string,
+	) (simulation.OperationMsg, []simulation.FutureOperation, error) {
+		channels := k.GetAllChannels(ctx)
+		if len(channels) == 0 {
+			return simulation.NoOpMsg(types.ModuleName), nil, nil
+		}
+
+		channel := channels[r.Intn(len(channels))]
+		simAccount, _ := simulation.RandomAcc(r, accs)
+		body := simulation.RandStringOfLength(r, 50)
+
+		msg := types.NewMsgSendChat(simAccount.Address.String(), channel.Name, body)
+
+		if err := k.SendChat(ctx, msg); err != nil {
+			return simulation.NoOpMsg(types.ModuleName), nil, err
+		}
+
+		return simulation.NewOperationMsg(msg, true, ""), nil, nil
+	}
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/params"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+
+	evm "github.com/cosmos/ethermint/x/evm"
+	evmkeeper "github.com/cosmos/ethermint/x/evm/keeper"
+	evmtypes "github.com/cosmos/ethermint/x/evm/types"
+
+	aragontypes "github.com/aragon/aragon-chain/types"
+	"github.com/aragon/aragon-chain/version"
+)
+
+const appName = "AragonChain"
+
+// ModuleBasics collects the basic, non-dependent parts of every module the app runs, used
+// for codec and genesis registration before the app's keepers exist
+var ModuleBasics = module.NewBasicManager(
+	auth.AppModuleBasic{},
+	bank.AppModuleBasic{},
+	supply.AppModuleBasic{},
+	params.AppModuleBasic{},
+	evm.AppModuleBasic{},
+	// TODO: staking, mint, distribution, gov, slashing, crisis and evidence modules
+)
+
+// App is the Aragon Chain ABCI application: a Cosmos SDK chain with an embedded Ethermint
+// EVM, so that ARA balances and contract state are both settled by the same set of blocks.
+type App struct {
+	*baseapp.BaseApp
+
+	cdc *codec.Codec
+
+	keys  map[string]*sdk.KVStoreKey
+	tkeys map[string]*sdk.TransientStoreKey
+
+	ParamsKeeper  params.Keeper
+	AccountKeeper auth.AccountKeeper
+	BankKeeper    bank.Keeper
+	SupplyKeeper  supply.Keeper
+	EvmKeeper     evmkeeper.Keeper
+
+	// EVMBankKeeper scales BankKeeper's baseDecimals ARA balances up to the evmDecimals the
+	// EVM expects. This ethermint version's CommitStateDB reads/writes balances straight off
+	// the auth.Account via AccountKeeper, with no bank-keeper extension point to plug into, so
+	// EVMBankKeeper isn't wired into EvmKeeper below; it exists for app-level callers (e.g. a
+	// future JSON-RPC balance query) that need an evmDecimals view of the same ARA balance.
+	EVMBankKeeper *EVMBankKeeper
+
+	mm *module.Manager
+
+	// TODO: staking, mint, distribution, gov, slashing, crisis and evidence keepers
+}
+
+// NewApp builds an Aragon Chain App from the given logger, database and codec
+func NewApp(logger tmlog.Logger, db dbm.DB, traceStore io.Writer, cdc *codec.Codec) *App {
+	bApp := baseapp.NewBaseApp(appName, logger, db, auth.DefaultTxDecoder(cdc))
+	bApp.SetAppVersion(version.Version)
+
+	keys := sdk.NewKVStoreKeys(
+		baseapp.MainStoreKey,
+		auth.StoreKey,
+		supply.StoreKey,
+		params.StoreKey,
+		evmtypes.StoreKey,
+	)
+	tkeys := sdk.NewTransientStoreKeys(params.TStoreKey)
+
+	app := &App{
+		BaseApp: bApp,
+		cdc:     cdc,
+		keys:    keys,
+		tkeys:   tkeys,
+	}
+
+	app.ParamsKeeper = params.NewKeeper(cdc, keys[params.StoreKey], tkeys[params.TStoreKey])
+	app.AccountKeeper = auth.NewAccountKeeper(
+		cdc, keys[auth.StoreKey], app.ParamsKeeper.Subspace(auth.DefaultParamspace), auth.ProtoBaseAccount,
+	)
+	app.BankKeeper = bank.NewBaseKeeper(
+		app.AccountKeeper, app.ParamsKeeper.Subspace(bank.DefaultParamspace), nil,
+	)
+	app.SupplyKeeper = supply.NewKeeper(
+		cdc, keys[supply.StoreKey], app.AccountKeeper, app.BankKeeper, nil,
+	)
+
+	app.EVMBankKeeper = NewEVMBankKeeper(app.BankKeeper, aragontypes.ARA)
+	app.EvmKeeper = evmkeeper.NewKeeper(
+		cdc, keys[evmtypes.StoreKey], app.ParamsKeeper.Subspace(evmtypes.DefaultParamspace),
+		app.AccountKeeper,
+	)
+
+	app.mm = module.NewManager(
+		auth.NewAppModule(app.AccountKeeper),
+		bank.NewAppModule(app.BankKeeper, app.AccountKeeper),
+		supply.NewAppModule(app.SupplyKeeper, app.AccountKeeper),
+		evm.NewAppModule(app.EvmKeeper, app.AccountKeeper),
+		// TODO: staking, mint, distribution, gov, slashing, crisis and evidence modules
+	)
+
+	app.mm.SetOrderBeginBlockers(evmtypes.ModuleName)
+	app.mm.SetOrderEndBlockers(evmtypes.ModuleName)
+	app.mm.SetOrderInitGenesis(
+		auth.ModuleName, bank.ModuleName, supply.ModuleName, evmtypes.ModuleName,
+	)
+
+	app.mm.RegisterRoutes(app.Router(), app.QueryRouter())
+
+	app.MountKVStores(keys)
+	app.MountTransientStores(tkeys)
+
+	app.SetInitChainer(app.InitChainer)
+	app.SetBeginBlocker(app.mm.BeginBlock)
+	app.SetEndBlocker(app.mm.EndBlock)
+
+	if err := app.LoadLatestVersion(app.keys[baseapp.MainStoreKey]); err != nil {
+		panic(err)
+	}
+
+	return app
+}
+
+// InitChainer runs the app's modules' genesis initialization
+func (app *App) InitChainer(ctx sdk.Context, req abci.RequestInitChain) abci.ResponseInitChain {
+	var genesisState map[string]json.RawMessage
+	app.cdc.MustUnmarshalJSON(req.AppStateBytes, &genesisState)
+	return app.mm.InitGenesis(ctx, genesisState)
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"math/big"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+)
+
+const (
+	// evmDecimals is the number of decimal places the EVM (go-ethereum) state transition
+	// expects account balances to be denominated in
+	evmDecimals = 18
+	// baseDecimals is the number of decimal places the bank module stores the ARA balance in
+	baseDecimals = 6
+)
+
+// decimalConversionFactor scales a baseDecimals-denominated amount up to evmDecimals, and
+// divides an evmDecimals-denominated amount back down to baseDecimals
+var decimalConversionFactor = sdk.NewIntWithDecimal(1, evmDecimals-baseDecimals)
+
+// EVMBankKeeper adapts the chain's x/bank keeper, which stores the ARA balance in
+// baseDecimals units, to the evmDecimals-denominated balances the EVM works in. It is not
+// wired into the Ethermint EVM keeper, which reads and writes balances through AccountKeeper;
+// it gives app-level callers an evmDecimals view of the same balance without each one doing
+// its own ad-hoc 10^12 scaling at the call site.
+type EVMBankKeeper struct {
+	bankKeeper bank.Keeper
+	evmDenom   string
+}
+
+// NewEVMBankKeeper creates an EVMBankKeeper that scales evmDenom balances tracked by
+// bankKeeper between the chain's baseDecimals storage unit and the EVM's evmDecimals unit
+func NewEVMBankKeeper(bankKeeper bank.Keeper, evmDenom string) *EVMBankKeeper {
+	return &EVMBankKeeper{
+		bankKeeper: bankKeeper,
+		evmDenom:   evmDenom,
+	}
+}
+
+// GetBalance returns addr's evmDenom balance, scaled up to the evmDecimals the EVM expects
+func (k *EVMBankKeeper) GetBalance(ctx sdk.Context, addr sdk.AccAddress) *big.Int {
+	amount := k.bankKeeper.GetCoins(ctx, addr).AmountOf(k.evmDenom)
+	return baseToEVM(amount).BigInt()
+}
+
+// SetBalance sets addr's evmDenom balance to amount, an evmDecimals-denominated value,
+// rounding down to the nearest baseDecimals unit the bank module can store
+func (k *EVMBankKeeper) SetBalance(ctx sdk.Context, addr sdk.AccAddress, amount *big.Int) error {
+	newBalance := evmToBase(sdk.NewIntFromBigInt(amount))
+
+	coins := k.bankKeeper.GetCoins(ctx, addr)
+	coins = coins.Sub(sdk.NewCoins(sdk.NewCoin(k.evmDenom, coins.AmountOf(k.evmDenom))))
+	coins = coins.Add(sdk.NewCoin(k.evmDenom, newBalance))
+
+	return k.bankKeeper.SetCoins(ctx, addr, coins)
+}
+
+// baseToEVM scales a baseDecimals-denominated amount up to evmDecimals
+func baseToEVM(amount sdk.Int) sdk.Int {
+	return amount.Mul(decimalConversionFactor)
+}
+
+// evmToBase scales an evmDecimals-denominated amount down to baseDecimals, truncating any
+// precision the bank module can't represent
+func evmToBase(amount sdk.Int) sdk.Int {
+	return amount.Quo(decimalConversionFactor)
+}
@@ -0,0 +1,86 @@
+package sr25519
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/cosmos/go-bip39"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+// SigningAlgo names a key-derivation/signing algorithm an sr25519 account was created with.
+// It shares its string shape with cosmos-sdk's own keys.SigningAlgo, but cosmos-sdk v0.39.1's
+// crypto/keys.Keybase dispatches "keys add --algo" through a private, hardcoded switch over
+// secp256k1/ed25519 with no pluggable extension point, so Name below cannot be registered
+// with that flag the way a Stargate-era keyring.SignatureAlgo/hd.Algo could be. Wiring an
+// "--algo sr25519" CLI flag through to Keybase.CreateAccount is out of scope until the chain
+// upgrades off Launchpad onto a keyring package that supports custom signing algorithms; until
+// then, callers that need an sr25519 account construct one directly via DeriveKey/PrivKeyGen
+// and manage storage themselves, the same way this package's own tests do.
+type SigningAlgo string
+
+// Name identifies this package's signing algorithm wherever an app-level SigningAlgo value is
+// needed (e.g. AccountConfig.Algo), independent of whether the underlying keyring supports it
+const Name = SigningAlgo("sr25519")
+
+// masterKeyLabel is mixed into the HMAC that derives an account's master key from its BIP39
+// seed, the sr25519 analogue of the "Bitcoin seed" label cosmos-sdk's secp256k1 HD path uses
+const masterKeyLabel = "aragon-chain sr25519 seed"
+
+// DeriveKey derives the 32-byte sr25519 mini secret key seed for mnemonic/bip39Passphrase at
+// hdPath, so that the same mnemonic and path recover the same key on any node; an empty
+// hdPath returns the master key's first PrivKeySize bytes
+func DeriveKey(mnemonic, bip39Passphrase, hdPath string) ([]byte, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, bip39Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("sr25519: generating bip39 seed: %w", err)
+	}
+
+	mac := hmac.New(sha512.New, []byte(masterKeyLabel))
+	if _, err := mac.Write(seed); err != nil {
+		return nil, fmt.Errorf("sr25519: deriving master key: %w", err)
+	}
+	key := mac.Sum(nil)
+
+	if hdPath == "" {
+		return key[:PrivKeySize], nil
+	}
+	return deriveAtPath(key, hdPath)
+}
+
+// deriveAtPath walks key through every segment of hdPath (e.g. "44'/118'/0'/0/0"), treating
+// each one as hardened whether or not it carries a trailing "'",
+// HMAC-ing the running key and chain code at every step the same way BIP32 hardened child
+// keys are derived, so a single mnemonic can produce many independent sr25519 accounts
+func deriveAtPath(key []byte, hdPath string) ([]byte, error) {
+	privKey, chainCode := key[:32], key[32:]
+
+	for _, segment := range strings.Split(hdPath, "/") {
+		segment = strings.TrimSuffix(segment, "'")
+		var index uint32
+		if _, err := fmt.Sscanf(segment, "%d", &index); err != nil {
+			return nil, fmt.Errorf("sr25519: invalid hd path segment %q: %w", segment, err)
+		}
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write([]byte{0x00})
+		mac.Write(privKey)
+		var indexBz [4]byte
+		binary.BigEndian.PutUint32(indexBz[:], index|0x80000000) // always hardened, like sr25519 hard junctions
+		mac.Write(indexBz[:])
+
+		sum := mac.Sum(nil)
+		privKey, chainCode = sum[:32], sum[32:]
+	}
+	return privKey, nil
+}
+
+// PrivKeyGen builds the sr25519 PrivKey for the 32-byte seed DeriveKey returned
+func PrivKeyGen(bz []byte) crypto.PrivKey {
+	var privKey PrivKey
+	copy(privKey[:], bz)
+	return privKey
+}
@@ -0,0 +1,62 @@
+package sr25519
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	privKey := GenPrivKey()
+	pubKey := privKey.PubKey()
+
+	msg := []byte("send 10ara to cosmos1...")
+	sig, err := privKey.Sign(msg)
+	require.NoError(t, err)
+
+	require.True(t, pubKey.VerifyBytes(msg, sig))
+	require.False(t, pubKey.VerifyBytes([]byte("send 11ara to cosmos1..."), sig))
+}
+
+func TestPrivKeyEquals(t *testing.T) {
+	privKey := GenPrivKey()
+
+	require.True(t, privKey.Equals(privKey))
+	require.False(t, privKey.Equals(GenPrivKey()))
+}
+
+func TestPubKeyAddress(t *testing.T) {
+	privKey := GenPrivKey()
+	pubKey := privKey.PubKey()
+
+	require.Len(t, pubKey.Address().Bytes(), 20)
+}
+
+func TestDeriveKeyIsDeterministic(t *testing.T) {
+	const mnemonic = "equip will roof matter pink blind book anxiety banner elbow sun young"
+
+	key1, err := DeriveKey(mnemonic, "", "44'/118'/0'/0/0")
+	require.NoError(t, err)
+	key2, err := DeriveKey(mnemonic, "", "44'/118'/0'/0/0")
+	require.NoError(t, err)
+	require.Equal(t, key1, key2)
+
+	otherKey, err := DeriveKey(mnemonic, "", "44'/118'/0'/0/1")
+	require.NoError(t, err)
+	require.NotEqual(t, key1, otherKey)
+}
+
+func TestPrivKeyGenRoundTrip(t *testing.T) {
+	const mnemonic = "equip will roof matter pink blind book anxiety banner elbow sun young"
+
+	seed, err := DeriveKey(mnemonic, "", "44'/118'/0'/0/0")
+	require.NoError(t, err)
+
+	privKey := PrivKeyGen(seed)
+	pubKey := privKey.PubKey()
+
+	msg := []byte("stake 1000ara")
+	sig, err := privKey.Sign(msg)
+	require.NoError(t, err)
+	require.True(t, pubKey.VerifyBytes(msg, sig))
+}
@@ -0,0 +1,63 @@
+package keeper
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gokulsan/MoorPay/x/moorfly/types"
+)
+
+var _ types.QueryServer = Keeper{}
+
+// Params implements the Query/Params gRPC method
+func (k Keeper) Params(c context.Context, req *types.QueryParamsRequest) (*types.QueryParamsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	ctx := sdk.UnwrapSDKContext(c)
+	return &types.QueryParamsResponse{Params: k.GetParams(ctx)}, nil
+}
+
+// MoorflyRecord implements the Query/MoorflyRecord gRPC method
+func (k Keeper) MoorflyRecord(c context.Context, req *types.QueryMoorflyRecordRequest) (*types.QueryMoorflyRecordResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	ctx := sdk.UnwrapSDKContext(c)
+
+	record, found := k.GetMoorflyRecord(ctx, req.Id)
+	if !found {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrKeyNotFound, "record %d", req.Id)
+	}
+	return &types.QueryMoorflyRecordResponse{Record: record}, nil
+}
+
+// MoorflyRecords implements the Query/MoorflyRecords gRPC method
+func (k Keeper) MoorflyRecords(c context.Context, req *types.QueryMoorflyRecordsRequest) (*types.QueryMoorflyRecordsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	ctx := sdk.UnwrapSDKContext(c)
+
+	var records []types.MoorflyRecord
+	store := k.GetRecordsStore(ctx)
+
+	pageRes, err := query.Paginate(store, req.Pagination, func(_, value []byte) error {
+		var record types.MoorflyRecord
+		if err := k.cdc.UnmarshalBinaryBare(value, &record); err != nil {
+			return err
+		}
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryMoorflyRecordsResponse{Records: records, Pagination: pageRes}, nil
+}
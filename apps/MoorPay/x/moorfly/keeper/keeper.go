@@ -0,0 +1,67 @@
+package keeper
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store/prefix"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/gokulsan/MoorPay/x/moorfly/types"
+)
+
+// Keeper stores and retrieves moorfly records and module parameters in the store under
+// storeKey
+type Keeper struct {
+	storeKey sdk.StoreKey
+	cdc      codec.BinaryMarshaler
+}
+
+// NewKeeper creates a moorfly Keeper backed by storeKey, using cdc to (de)serialize records
+func NewKeeper(cdc codec.BinaryMarshaler, storeKey sdk.StoreKey) Keeper {
+	return Keeper{
+		storeKey: storeKey,
+		cdc:      cdc,
+	}
+}
+
+// GetParams returns the moorfly module's current parameters
+func (k Keeper) GetParams(ctx sdk.Context) types.Params {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.ParamsKey)
+	if bz == nil {
+		return types.Params{}
+	}
+	var params types.Params
+	k.cdc.MustUnmarshalBinaryBare(bz, &params)
+	return params
+}
+
+// SetParams sets the moorfly module's parameters
+func (k Keeper) SetParams(ctx sdk.Context, params types.Params) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.ParamsKey, k.cdc.MustMarshalBinaryBare(&params))
+}
+
+// GetMoorflyRecord returns the record stored under id, and whether it was found
+func (k Keeper) GetMoorflyRecord(ctx sdk.Context, id uint64) (types.MoorflyRecord, bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.RecordKey(id))
+	if bz == nil {
+		return types.MoorflyRecord{}, false
+	}
+	var record types.MoorflyRecord
+	k.cdc.MustUnmarshalBinaryBare(bz, &record)
+	return record, true
+}
+
+// SetMoorflyRecord stores record under its own id
+func (k Keeper) SetMoorflyRecord(ctx sdk.Context, record types.MoorflyRecord) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.RecordKey(record.Id), k.cdc.MustMarshalBinaryBare(&record))
+}
+
+// GetRecordsStore returns the store holding every MoorflyRecord, prefixed so iteration never
+// sees the Params entry
+func (k Keeper) GetRecordsStore(ctx sdk.Context) sdk.KVStore {
+	store := ctx.KVStore(k.storeKey)
+	return prefix.NewStore(store, types.RecordsKeyPrefix)
+}
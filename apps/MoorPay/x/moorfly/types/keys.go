@@ -0,0 +1,31 @@
+package types
+
+import "encoding/binary"
+
+const (
+	// ModuleName is the name of the moorfly module
+	ModuleName = "moorfly"
+
+	// StoreKey is the string store representation
+	StoreKey = ModuleName
+
+	// RouterKey is the message route for the moorfly module
+	RouterKey = ModuleName
+
+	// QuerierRoute is the querier route for the moorfly module
+	QuerierRoute = ModuleName
+)
+
+// RecordsKeyPrefix is the prefix under which every MoorflyRecord is stored
+var RecordsKeyPrefix = []byte{0x01}
+
+// RecordKey returns the store key for the MoorflyRecord with the given id, big-endian
+// encoded so that iterating the prefix yields records in ascending id order
+func RecordKey(id uint64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, id)
+	return append(RecordsKeyPrefix, bz...)
+}
+
+// ParamsKey is the key Params are stored under
+var ParamsKey = []byte{0x02}
@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"net"
+	"net/http"
+)
+
+// ServerConfig controls which parts of the moorwallet module's HTTP surface are reachable from
+// outside the local machine. It mirrors the node's familiar --unsafe-rpc-external /
+// --ws-external style flags: routes named in UnsafeMethods are refused by default, and once
+// enabled are loopback-only unless opened up explicitly, since they cover key generation,
+// signing, and arbitrary tx submission.
+type ServerConfig struct {
+	// RPCUnsafe enables the unsafe HTTP routes at all. If false, they are still registered but
+	// answer every call with 403.
+	RPCUnsafe bool
+	// RPCUnsafeExternal allows unsafe HTTP routes to be called from a non-loopback remote
+	// address. Ignored if RPCUnsafe is false.
+	RPCUnsafeExternal bool
+	// WSExternal allows the WebSocket subscription endpoint to accept connections from a
+	// non-loopback remote address at all.
+	WSExternal bool
+	// WSUnsafeExternal allows the WebSocket subscription endpoint to serve methods named in
+	// UnsafeMethods to non-loopback connections. Ignored if WSExternal is false.
+	WSUnsafeExternal bool
+	// UnsafeMethods is the allow-list of module method names treated as unsafe.
+	UnsafeMethods []string
+}
+
+// DefaultServerConfig returns the conservative default: unsafe routes are disabled outright, and
+// nothing is reachable from outside the local machine.
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		RPCUnsafe:         false,
+		RPCUnsafeExternal: false,
+		WSExternal:        false,
+		WSUnsafeExternal:  false,
+		UnsafeMethods:     []string{"generateKey", "signTx", "submitTx"},
+	}
+}
+
+// isUnsafe reports whether method is on cfg's unsafe allow-list.
+func (cfg ServerConfig) isUnsafe(method string) bool {
+	for _, m := range cfg.UnsafeMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// allowHTTP reports whether an HTTP call to method from remoteAddr should be let through.
+func (cfg ServerConfig) allowHTTP(method, remoteAddr string) bool {
+	if !cfg.isUnsafe(method) {
+		return true
+	}
+	if !cfg.RPCUnsafe {
+		return false
+	}
+	return cfg.RPCUnsafeExternal || isLoopback(remoteAddr)
+}
+
+// allowWS reports whether a WebSocket subscription to method from remoteAddr should be let
+// through.
+func (cfg ServerConfig) allowWS(method, remoteAddr string) bool {
+	if !cfg.isUnsafe(method) {
+		return cfg.WSExternal || isLoopback(remoteAddr)
+	}
+	if !cfg.WSExternal {
+		return false
+	}
+	return cfg.WSUnsafeExternal || isLoopback(remoteAddr)
+}
+
+// isLoopback reports whether addr (an http.Request.RemoteAddr, "host:port") resolves to a
+// loopback address. A malformed addr is treated as non-loopback, the safer default.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// unsafeGated wraps next so that calls failing cfg.allowHTTP for method are rejected with 403
+// before next ever runs.
+func unsafeGated(cfg ServerConfig, method string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.allowHTTP(method, r.RemoteAddr) {
+			http.Error(w, "RPC call is unsafe and unsafe RPC calls are not allowed from this address", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,51 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/gorilla/mux"
+)
+
+// RegisterRoutes mounts the moorwallet module's REST routes on r. Any route whose method name
+// appears in cfg.UnsafeMethods is gated by cfg's RPCUnsafe/RPCUnsafeExternal flags via
+// unsafeGated; all other routes are mounted unconditionally.
+func RegisterRoutes(cliCtx context.CLIContext, r *mux.Router, cfg ServerConfig) {
+	registerQueryRoutes(cliCtx, r, cfg)
+	registerTxRoutes(cliCtx, r, cfg)
+	registerWSRoutes(r, cfg)
+}
+
+// registerQueryRoutes mounts the module's read-only routes. None of them are unsafe today.
+func registerQueryRoutes(cliCtx context.CLIContext, r *mux.Router, cfg ServerConfig) {
+	// TODO: Add query routes
+}
+
+// registerTxRoutes mounts the module's state-changing routes, gating the ones named in
+// cfg.UnsafeMethods.
+func registerTxRoutes(cliCtx context.CLIContext, r *mux.Router, cfg ServerConfig) {
+	r.HandleFunc("/moorwallet/keys", unsafeGated(cfg, "generateKey", generateKeyHandler(cliCtx))).Methods("POST")
+	r.HandleFunc("/moorwallet/sign", unsafeGated(cfg, "signTx", signTxHandler(cliCtx))).Methods("POST")
+	r.HandleFunc("/moorwallet/txs", unsafeGated(cfg, "submitTx", submitTxHandler(cliCtx))).Methods("POST")
+}
+
+// generateKeyHandler handles POST /moorwallet/keys, which mints a new wallet keypair.
+func generateKeyHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// TODO: Add key generation
+	}
+}
+
+// signTxHandler handles POST /moorwallet/sign, which signs an unsigned tx with a wallet key.
+func signTxHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// TODO: Add tx signing
+	}
+}
+
+// submitTxHandler handles POST /moorwallet/txs, which broadcasts an arbitrary signed tx.
+func submitTxHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// TODO: Add tx submission
+	}
+}
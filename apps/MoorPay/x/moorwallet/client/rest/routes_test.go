@@ -0,0 +1,152 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	loopbackAddr = "127.0.0.1:54321"
+	remoteAddr   = "203.0.113.7:54321"
+)
+
+func newTestRouter(cfg ServerConfig) *mux.Router {
+	r := mux.NewRouter()
+	RegisterRoutes(context.CLIContext{}, r, cfg)
+	return r
+}
+
+func TestRegisterRoutesUnsafeGating(t *testing.T) {
+	cases := []struct {
+		name       string
+		cfg        ServerConfig
+		remoteAddr string
+		wantStatus int
+	}{
+		{
+			name:       "unsafe route, RPCUnsafe disabled, loopback",
+			cfg:        DefaultServerConfig(),
+			remoteAddr: loopbackAddr,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "unsafe route, RPCUnsafe enabled, loopback",
+			cfg: ServerConfig{
+				RPCUnsafe:     true,
+				UnsafeMethods: []string{"generateKey"},
+			},
+			remoteAddr: loopbackAddr,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "unsafe route, RPCUnsafe enabled, remote, external disabled",
+			cfg: ServerConfig{
+				RPCUnsafe:     true,
+				UnsafeMethods: []string{"generateKey"},
+			},
+			remoteAddr: remoteAddr,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "unsafe route, RPCUnsafe and RPCUnsafeExternal enabled, remote",
+			cfg: ServerConfig{
+				RPCUnsafe:         true,
+				RPCUnsafeExternal: true,
+				UnsafeMethods:     []string{"generateKey"},
+			},
+			remoteAddr: remoteAddr,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newTestRouter(tc.cfg)
+
+			req := httptest.NewRequest("POST", "/moorwallet/keys", nil)
+			req.RemoteAddr = tc.remoteAddr
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestRegisterRoutesSafeRouteIsOnlyWSGated(t *testing.T) {
+	cfg := DefaultServerConfig()
+	r := newTestRouter(cfg)
+
+	req := httptest.NewRequest("GET", "/moorwallet/subscribe/walletUpdates", nil)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	// The request is rejected by the WS gate (default cfg has WSExternal disabled), not because
+	// the route itself is unsafe-gated away.
+	require.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestSubscribeWSHandlerDialer(t *testing.T) {
+	cases := []struct {
+		name        string
+		cfg         ServerConfig
+		simRemote   bool
+		wantUpgrade bool
+	}{
+		{
+			name:        "WSExternal disabled, loopback dial allowed",
+			cfg:         DefaultServerConfig(),
+			simRemote:   false,
+			wantUpgrade: true,
+		},
+		{
+			name:        "WSExternal disabled, remote dial refused",
+			cfg:         DefaultServerConfig(),
+			simRemote:   true,
+			wantUpgrade: false,
+		},
+		{
+			name:        "WSExternal enabled, remote dial allowed",
+			cfg:         ServerConfig{WSExternal: true},
+			simRemote:   true,
+			wantUpgrade: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			registerWSRoutes(r, tc.cfg)
+
+			// httptest.NewServer always dials itself from 127.0.0.1; to exercise the "remote"
+			// cases, wrap the router and spoof RemoteAddr on the request before delegating.
+			handler := http.Handler(r)
+			if tc.simRemote {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					req.RemoteAddr = remoteAddr
+					r.ServeHTTP(w, req)
+				})
+			}
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			wsURL := "ws" + srv.URL[len("http"):] + "/moorwallet/subscribe/walletUpdates"
+			_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+
+			if tc.wantUpgrade {
+				require.NoError(t, err)
+				require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+			} else {
+				require.Error(t, err)
+				require.Equal(t, http.StatusForbidden, resp.StatusCode)
+			}
+		})
+	}
+}
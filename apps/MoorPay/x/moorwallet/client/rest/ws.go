@@ -0,0 +1,37 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// upgrader uses gorilla/websocket's defaults for buffer sizing and accepts any Origin, matching
+// the CLI-facing REST servers elsewhere in the SDK that have no browser CORS story to enforce.
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// registerWSRoutes mounts the module's WebSocket subscription routes, gating each by cfg's
+// WSExternal/WSUnsafeExternal flags via subscribeWSHandler.
+func registerWSRoutes(r *mux.Router, cfg ServerConfig) {
+	r.HandleFunc("/moorwallet/subscribe/walletUpdates", subscribeWSHandler(cfg, "walletUpdates")).Methods("GET")
+}
+
+// subscribeWSHandler upgrades the request to a WebSocket connection and streams updates for
+// method, refusing the upgrade outright if cfg.allowWS rejects method from r.RemoteAddr.
+func subscribeWSHandler(cfg ServerConfig, method string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.allowWS(method, r.RemoteAddr) {
+			http.Error(w, "WebSocket subscription is not allowed from this address", http.StatusForbidden)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// TODO: stream subscription updates
+	}
+}
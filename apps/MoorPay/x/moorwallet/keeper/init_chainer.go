@@ -0,0 +1,30 @@
+package keeper
+
+import (
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/gokulsan/MoorPay/x/moorwallet/types"
+)
+
+// InitChainer consumes the moorwallet module's app-state from req, validates it, and
+// initializes the module's wallets from it. It always returns an empty validator set: the
+// moorwallet module does not manage validators. This replaces AppModule's previous ad-hoc
+// InitGenesis wiring with a single entry point an app can also wire directly via
+// baseapp.SetInitChainer when it needs InitChainer's abci.RequestInitChain/ResponseInitChain
+// shape instead of AppModule's json.RawMessage one.
+func (k Keeper) InitChainer(ctx sdk.Context, req abci.RequestInitChain) abci.ResponseInitChain {
+	var genesisState types.GenesisState
+	k.cdc.MustUnmarshalJSON(req.AppStateBytes, &genesisState)
+
+	if err := types.ValidateGenesis(genesisState); err != nil {
+		panic(err)
+	}
+
+	for _, wallet := range genesisState.Wallets {
+		k.SetWallet(ctx, wallet)
+	}
+
+	return abci.ResponseInitChain{}
+}
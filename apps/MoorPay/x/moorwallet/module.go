@@ -24,7 +24,20 @@ var (
 )
 
 // AppModuleBasic defines the basic application module used by the moorwallet module.
-type AppModuleBasic struct{}
+type AppModuleBasic struct {
+	// restConfig gates which parts of the module's REST surface are reachable, and from where.
+	// A value with nil UnsafeMethods, such as the zero value constructed by
+	// module.NewBasicManager, falls back to rest.DefaultServerConfig() in RegisterRESTRoutes,
+	// the conservative default.
+	restConfig rest.ServerConfig
+}
+
+// WithRESTConfig sets the REST unsafe/external-exposure gating policy on mb and returns it, for
+// chaining off an AppModuleBasic literal before it is handed to module.NewBasicManager.
+func (mb AppModuleBasic) WithRESTConfig(cfg rest.ServerConfig) AppModuleBasic {
+	mb.restConfig = cfg
+	return mb
+}
 
 // Name returns the moorwallet module's name.
 func (AppModuleBasic) Name() string {
@@ -52,9 +65,15 @@ func (AppModuleBasic) ValidateGenesis(bz json.RawMessage) error {
 	return types.ValidateGenesis(data)
 }
 
-// RegisterRESTRoutes registers the REST routes for the moorwallet module.
-func (AppModuleBasic) RegisterRESTRoutes(ctx context.CLIContext, rtr *mux.Router) {
-	rest.RegisterRoutes(ctx, rtr)
+// RegisterRESTRoutes registers the REST routes for the moorwallet module, gated by mb's
+// restConfig. If restConfig.UnsafeMethods is nil, whether or not WithRESTConfig was called,
+// rest.DefaultServerConfig() is used in its place and restConfig's other fields are ignored.
+func (mb AppModuleBasic) RegisterRESTRoutes(ctx context.CLIContext, rtr *mux.Router) {
+	cfg := mb.restConfig
+	if cfg.UnsafeMethods == nil {
+		cfg = rest.DefaultServerConfig()
+	}
+	rest.RegisterRoutes(ctx, rtr, cfg)
 }
 
 // GetTxCmd returns the root tx command for the moorwallet module.
@@ -73,8 +92,13 @@ func (AppModuleBasic) GetQueryCmd(cdc *codec.Codec) *cobra.Command {
 type AppModule struct {
 	AppModuleBasic
 
-	keeper        keeper.Keeper
+	keeper keeper.Keeper
 	// TODO: Add keepers that your application depends on
+
+	// AnteHandler carries an ante handler (fees, auth, etc.) that a downstream app can read off
+	// the module and install ahead of wallet message handling without forking the module. The
+	// module itself only stores it: nothing in the module installs or invokes it.
+	AnteHandler sdk.AnteHandler
 }
 
 // NewAppModule creates a new AppModule object
@@ -86,6 +110,12 @@ func NewAppModule(k keeper.Keeper, /*TODO: Add Keepers that your application dep
 	}
 }
 
+// WithAnteHandler sets AnteHandler on am and returns it, for chaining off NewAppModule.
+func (am AppModule) WithAnteHandler(ah sdk.AnteHandler) AppModule {
+	am.AnteHandler = ah
+	return am
+}
+
 // Name returns the moorwallet module's name.
 func (AppModule) Name() string {
 	return types.ModuleName
@@ -104,6 +134,13 @@ func (am AppModule) NewHandler() sdk.Handler {
 	return NewHandler(am.keeper)
 }
 
+// Router returns an sdk.Router with the moorwallet module's route already registered, for apps
+// that assemble their baseapp router module-by-module instead of calling Route/NewHandler
+// themselves.
+func (am AppModule) Router() sdk.Router {
+	return sdk.NewRouter().AddRoute(sdk.NewRoute(types.RouterKey, NewHandler(am.keeper)))
+}
+
 // QuerierRoute returns the moorwallet module's querier route name.
 func (AppModule) QuerierRoute() string {
 	return types.QuerierRoute
@@ -114,13 +151,13 @@ func (am AppModule) NewQuerierHandler() sdk.Querier {
 	return types.NewQuerier(am.keeper)
 }
 
-// InitGenesis performs genesis initialization for the moorwallet module. It returns
-// no validator updates.
+// InitGenesis performs genesis initialization for the moorwallet module. It delegates to
+// keeper.Keeper.InitChainer, which takes the same abci.RequestInitChain/ResponseInitChain shape
+// baseapp.SetInitChainer expects, so apps that wire the keeper in directly get identical genesis
+// handling whether InitChainer is reached through AppModule or through baseapp.
 func (am AppModule) InitGenesis(ctx sdk.Context, data json.RawMessage) []abci.ValidatorUpdate {
-	var genesisState GenesisState
-	types.ModuleCdc.MustUnmarshalJSON(data, &genesisState)
-	InitGenesis(ctx, am.keeper, genesisState)
-	return []abci.ValidatorUpdate{}
+	res := am.keeper.InitChainer(ctx, abci.RequestInitChain{AppStateBytes: data})
+	return res.Validators
 }
 
 // ExportGenesis returns the exported genesis state as raw bytes for the moorwallet
@@ -0,0 +1,112 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package dot
+
+import (
+	"encoding/json"
+
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/genesis"
+
+	log "github.com/ChainSafe/log15"
+)
+
+// BuildSpec represents a chain specification, built either from a human-readable
+// genesis JSON file or from an already-initialised node's state database. It's the
+// in-memory form operators work with before re-emitting a spec in the human-readable
+// or raw format consumed by NewGenesisFromJSON/NewGenesisFromJSONRaw.
+type BuildSpec struct {
+	genesis *genesis.Genesis
+}
+
+// BuildFromGenesis builds a BuildSpec from a human-readable genesis JSON file at path,
+// generating authorities many BABE/GRANDPA authority keys for a dev/test chain.
+func BuildFromGenesis(path string, authorities int) (*BuildSpec, error) {
+	gen, err := genesis.NewGenesisFromJSON(path, authorities)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BuildSpec{genesis: gen}, nil
+}
+
+// BuildFromDB builds a BuildSpec from the genesis data and storage trie recorded in an
+// already-initialised node's state database at basepath. Only the fields state.LoadGenesisData
+// persists (Name, ID, Bootnodes, ProtocolID) can be recovered this way: ChainType, Properties,
+// ForkBlocks and BadBlocks aren't part of the genesis data table and come back empty, so forking
+// a running chain this way is best paired with hand-editing those fields back in afterwards.
+func BuildFromDB(basepath string) (*BuildSpec, error) {
+	stateSrvc := state.NewService(basepath, log.LvlInfo)
+
+	db, err := stateSrvc.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	gendata, err := state.LoadGenesisData(db)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := stateSrvc.Block.BestBlockHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := stateSrvc.Storage.Entries(&header.StateRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	gen := &genesis.Genesis{
+		Name:       gendata.Name,
+		ID:         gendata.ID,
+		Bootnodes:  common.BytesToStringArray(gendata.Bootnodes),
+		ProtocolID: gendata.ProtocolID,
+		Genesis: genesis.Fields{
+			Raw: map[int]map[string]string{
+				0: rawEntriesToHex(entries),
+			},
+		},
+	}
+
+	return &BuildSpec{genesis: gen}, nil
+}
+
+// ToJSON returns the human-readable JSON encoding of the spec.
+func (bs *BuildSpec) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(bs.genesis, "", "\t")
+}
+
+// ToJSONRaw returns the raw ("Raw" hex-encoded key/value) JSON encoding of the spec, the
+// format accepted by NewGenesisFromJSONRaw.
+func (bs *BuildSpec) ToJSONRaw() ([]byte, error) {
+	raw := *bs.genesis
+	raw.Genesis = genesis.Fields{Raw: bs.genesis.Genesis.Raw}
+	return json.MarshalIndent(raw, "", "\t")
+}
+
+// rawEntriesToHex hex-encodes a raw trie key/value dump into the form genesis.Fields.Raw
+// expects.
+func rawEntriesToHex(entries map[string][]byte) map[string]string {
+	raw := make(map[string]string, len(entries))
+	for k, v := range entries {
+		raw[common.BytesToHex([]byte(k))] = common.BytesToHex(v)
+	}
+	return raw
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package dot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/genesis"
+	"github.com/ChainSafe/gossamer/lib/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildFromGenesis_ToJSONRaw_RoundTrip checks that a raw genesis file fed through
+// BuildFromGenesis and back out through ToJSONRaw produces a spec that NewGenesisFromJSONRaw
+// parses into the same genesis.Data and raw storage, mirroring TestInitNode_LoadGenesisData's
+// use of the same fixture.
+func TestBuildFromGenesis_ToJSONRaw_RoundTrip(t *testing.T) {
+	genPath := NewTestGenesisAndRuntime(t)
+	require.NotNil(t, genPath)
+
+	defer utils.RemoveTestDir(t)
+
+	expected, err := genesis.NewGenesisFromJSONRaw(genPath)
+	require.NoError(t, err)
+
+	bs, err := BuildFromGenesis(genPath, 0)
+	require.NoError(t, err)
+
+	rawJSON, err := bs.ToJSONRaw()
+	require.NoError(t, err)
+
+	rawFile, err := ioutil.TempFile("", "build-spec-raw-*.json")
+	require.NoError(t, err)
+	_, err = rawFile.Write(rawJSON)
+	require.NoError(t, err)
+	require.NoError(t, rawFile.Close())
+
+	actual, err := genesis.NewGenesisFromJSONRaw(rawFile.Name())
+	require.NoError(t, err)
+
+	require.Equal(t, expected.GenesisData(), actual.GenesisData())
+	require.Equal(t, expected.GenesisFields().Raw[0], actual.GenesisFields().Raw[0])
+}
+
+// TestBuildSpec_ToJSON_IsValidJSON checks that ToJSON produces well-formed JSON carrying the
+// human-readable genesis fields through unchanged.
+func TestBuildSpec_ToJSON_IsValidJSON(t *testing.T) {
+	genPath := NewTestGenesisAndRuntime(t)
+	require.NotNil(t, genPath)
+
+	defer utils.RemoveTestDir(t)
+
+	bs, err := BuildFromGenesis(genPath, 0)
+	require.NoError(t, err)
+
+	out, err := bs.ToJSON()
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(out, &decoded))
+}
@@ -0,0 +1,22 @@
+package toml
+
+// AppConfig is the typed, already-resolved view of Config's newer sections, the shape a
+// declarative module-composition flow (analogous to cosmos-sdk's app_v2.yaml/depinject)
+// would read to wire up a node's consensus, state sync and per-module settings without each
+// module having to parse TOML itself
+type AppConfig struct {
+	Consensus ConsensusConfig
+	StateSync StateSyncConfig
+	Modules   ModulesConfig
+}
+
+// BuildAppConfig extracts the consensus, state sync and module sections of cfg into an
+// AppConfig, the seam a module registry plugs into to compose a node declaratively instead
+// of every call site reaching back into the raw Config
+func BuildAppConfig(cfg *Config) *AppConfig {
+	return &AppConfig{
+		Consensus: cfg.Consensus,
+		StateSync: cfg.StateSync,
+		Modules:   cfg.Modules,
+	}
+}
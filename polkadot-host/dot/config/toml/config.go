@@ -16,15 +16,24 @@
 
 package toml
 
+import (
+	"reflect"
+
+	"github.com/naoina/toml"
+)
+
 // Config is a collection of configurations throughout the system
 type Config struct {
-	Global  GlobalConfig  `toml:"global,omitempty"`
-	Log     LogConfig     `toml:"log,omitempty"`
-	Init    InitConfig    `toml:"init,omitempty"`
-	Account AccountConfig `toml:"account,omitempty"`
-	Core    CoreConfig    `toml:"core,omitempty"`
-	Network NetworkConfig `toml:"network,omitempty"`
-	RPC     RPCConfig     `toml:"rpc,omitempty"`
+	Global    GlobalConfig    `toml:"global,omitempty"`
+	Log       LogConfig       `toml:"log,omitempty"`
+	Init      InitConfig      `toml:"init,omitempty"`
+	Account   AccountConfig   `toml:"account,omitempty"`
+	Core      CoreConfig      `toml:"core,omitempty"`
+	Network   NetworkConfig   `toml:"network,omitempty"`
+	RPC       RPCConfig       `toml:"rpc,omitempty"`
+	Consensus ConsensusConfig `toml:"consensus,omitempty"`
+	StateSync StateSyncConfig `toml:"state-sync,omitempty"`
+	Modules   ModulesConfig   `toml:"modules,omitempty"`
 }
 
 // GlobalConfig is to marshal/unmarshal toml global config vars
@@ -56,6 +65,9 @@ type InitConfig struct {
 type AccountConfig struct {
 	Key    string `toml:"key,omitempty"`
 	Unlock string `toml:"unlock,omitempty"`
+	// Algo names the signing algorithm Key was generated with (e.g. "sr25519", "ed25519");
+	// empty means the node's default, which mirrors how Key/Unlock were already optional
+	Algo string `toml:"algo,omitempty"`
 }
 
 // NetworkConfig is to marshal/unmarshal toml network config vars
@@ -79,10 +91,71 @@ type CoreConfig struct {
 
 // RPCConfig is to marshal/unmarshal toml RPC config vars
 type RPCConfig struct {
-	Enabled   bool     `toml:"enabled,omitempty"`
-	Port      uint32   `toml:"port,omitempty"`
-	Host      string   `toml:"host,omitempty"`
-	Modules   []string `toml:"modules,omitempty"`
-	WSPort    uint32   `toml:"ws-port,omitempty"`
-	WSEnabled bool     `toml:"ws-enabled,omitempty"`
+	Enabled     bool              `toml:"enabled,omitempty"`
+	Port        uint32            `toml:"port,omitempty"`
+	Host        string            `toml:"host,omitempty"`
+	Modules     []string          `toml:"modules,omitempty"`
+	WSPort      uint32            `toml:"ws-port,omitempty"`
+	WSEnabled   bool              `toml:"ws-enabled,omitempty"`
+	GRPC        GRPCConfig        `toml:"grpc,omitempty"`
+	GRPCGateway GRPCGatewayConfig `toml:"grpc-gateway,omitempty"`
+}
+
+// GRPCConfig is to marshal/unmarshal toml gRPC config vars for the node's gRPC query
+// surface, the one left standing now that legacy queriers and REST routes are gone
+type GRPCConfig struct {
+	Enabled        bool     `toml:"enabled,omitempty"`
+	Address        string   `toml:"address,omitempty"`
+	MaxRecvMsgSize int      `toml:"max-recv-msg-size,omitempty"`
+	MaxSendMsgSize int      `toml:"max-send-msg-size,omitempty"`
+	Reflection     bool     `toml:"reflection,omitempty"`
+	Modules        []string `toml:"modules,omitempty"`
+}
+
+// GRPCGatewayConfig is to marshal/unmarshal toml config vars for the HTTP/JSON gateway
+// that proxies REST requests onto the gRPC query surface above
+type GRPCGatewayConfig struct {
+	Enabled bool   `toml:"enabled,omitempty"`
+	Address string `toml:"address,omitempty"`
+}
+
+// ConsensusConfig is to marshal/unmarshal toml consensus config vars: the block and
+// evidence limits a cometbft-style consensus engine enforces, and how long it waits after a
+// block before proposing the next one
+type ConsensusConfig struct {
+	BlockMaxBytes int64  `toml:"block-max-bytes,omitempty"`
+	BlockMaxGas   int64  `toml:"block-max-gas,omitempty"`
+	MaxEvidenceAge string `toml:"max-evidence-age,omitempty"`
+	TimeoutCommit string `toml:"timeout-commit,omitempty"`
+}
+
+// StateSyncConfig is to marshal/unmarshal toml state sync config vars, controlling how
+// often the node snapshots its state and how many of those snapshots it retains for peers
+// bootstrapping via state sync instead of replaying the full chain
+type StateSyncConfig struct {
+	Enabled            bool   `toml:"enabled,omitempty"`
+	SnapshotInterval   uint64 `toml:"snapshot-interval,omitempty"`
+	SnapshotKeepRecent uint32 `toml:"snapshot-keep-recent,omitempty"`
+}
+
+// ModulesConfig holds each optional module's settings as a raw, undecoded TOML table keyed
+// by module name, so a module can land its own [modules.x] section without this package
+// needing to know its shape up front; a module decodes its own entry with Decode
+type ModulesConfig map[string]toml.Primitive
+
+// moduleTOML mirrors the decoder settings cmd/gossamer uses to load Config itself, so a
+// module's table decodes under the same field-matching rules as the rest of the file
+var moduleTOML = toml.Config{
+	NormFieldName: func(rt reflect.Type, key string) string { return key },
+	FieldToKey:    func(rt reflect.Type, field string) string { return field },
+}
+
+// Decode decodes the named module's raw TOML table into out, leaving out untouched if the
+// module has no [modules.name] section present in the file
+func (m ModulesConfig) Decode(name string, out interface{}) error {
+	prim, ok := m[name]
+	if !ok {
+		return nil
+	}
+	return moduleTOML.PrimitiveDecode(prim, out)
 }
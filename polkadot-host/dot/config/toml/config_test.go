@@ -0,0 +1,66 @@
+package toml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_EmptyRoundTrip(t *testing.T) {
+	var cfg Config
+
+	raw, err := toml.Marshal(cfg)
+	require.NoError(t, err)
+
+	var decoded Config
+	require.NoError(t, toml.Unmarshal(raw, &decoded))
+	require.Equal(t, cfg, decoded)
+}
+
+func TestConfig_NewSectionsRoundTrip(t *testing.T) {
+	cfg := Config{
+		Consensus: ConsensusConfig{
+			BlockMaxBytes:  22020096,
+			BlockMaxGas:    -1,
+			MaxEvidenceAge: "48h",
+			TimeoutCommit:  "3s",
+		},
+		StateSync: StateSyncConfig{
+			Enabled:            true,
+			SnapshotInterval:   1000,
+			SnapshotKeepRecent: 2,
+		},
+	}
+
+	raw, err := toml.Marshal(cfg)
+	require.NoError(t, err)
+
+	var decoded Config
+	require.NoError(t, toml.Unmarshal(raw, &decoded))
+	require.Equal(t, cfg, decoded)
+}
+
+func TestModulesConfig_Decode(t *testing.T) {
+	type moorflyModuleConfig struct {
+		MaxRecords int `toml:"max-records"`
+	}
+
+	raw := []byte(`
+[modules.moorfly]
+max-records = 100
+`)
+
+	var cfg Config
+	require.NoError(t, moduleTOML.NewDecoder(bytes.NewReader(raw)).Decode(&cfg))
+
+	var decoded moorflyModuleConfig
+	require.NoError(t, cfg.Modules.Decode("moorfly", &decoded))
+	require.Equal(t, 100, decoded.MaxRecords)
+
+	// a module with no section present is left untouched rather than erroring
+	var absent moorflyModuleConfig
+	require.NoError(t, cfg.Modules.Decode("does-not-exist", &absent))
+	require.Zero(t, absent.MaxRecords)
+}
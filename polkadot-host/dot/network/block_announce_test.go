@@ -28,15 +28,6 @@ import (
 )
 
 func TestBlockAnnounceDecoder(t *testing.T) {
-	srv := &Service{
-		blockAnnounceHandshakes: make(map[peer.ID]*blockAnnounceData),
-	}
-
-	testPeerID := peer.ID("QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ")
-	srv.blockAnnounceHandshakes[testPeerID] = &blockAnnounceData{
-		received: false,
-	}
-
 	testHandshake := &BlockAnnounceHandshake{
 		Roles:           4,
 		BestBlockNumber: 77,
@@ -47,9 +38,9 @@ func TestBlockAnnounceDecoder(t *testing.T) {
 	enc, err := testHandshake.Encode()
 	require.NoError(t, err)
 
-	msg, err := srv.blockAnnounceDecoder(enc, testPeerID)
+	hs, err := decodeBlockAnnounceHandshake(enc)
 	require.NoError(t, err)
-	require.Equal(t, testHandshake, msg)
+	require.Equal(t, testHandshake, hs)
 
 	testBlockAnnounce := &BlockAnnounceMessage{
 		ParentHash:     common.Hash{1},
@@ -62,8 +53,8 @@ func TestBlockAnnounceDecoder(t *testing.T) {
 	enc, err = testBlockAnnounce.Encode()
 	require.NoError(t, err)
 
-	srv.blockAnnounceHandshakes[testPeerID].received = true
-	msg, err = srv.blockAnnounceDecoder(enc, testPeerID)
+	testPeerID := peer.ID("QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ")
+	msg, err := decodeBlockAnnounceMessage(enc, testPeerID)
 	require.NoError(t, err)
 	require.Equal(t, testBlockAnnounce, msg)
 }
@@ -91,7 +82,7 @@ func TestHandleBlockAnnounceMessage_BlockAnnounce(t *testing.T) {
 	}
 
 	s.handleBlockAnnounceMessage(peerID, msg)
-	require.True(t, s.requestTracker.hasRequestedBlockID(99))
+	require.True(t, s.syncingEngine.requestTracker.hasRequestedBlockID(99))
 }
 
 func TestHandleBlockAnnounceMessage_BlockAnnounceHandshake(t *testing.T) {
@@ -110,6 +101,7 @@ func TestHandleBlockAnnounceMessage_BlockAnnounceHandshake(t *testing.T) {
 	}
 
 	s := createTestService(t, config)
+	np := s.notificationsProtocols[BlockAnnounceMsgType]
 
 	testPeerID := peer.ID("noot")
 	testHandshake := &BlockAnnounceHandshake{
@@ -119,9 +111,12 @@ func TestHandleBlockAnnounceMessage_BlockAnnounceHandshake(t *testing.T) {
 		GenesisHash:     common.Hash{2},
 	}
 
-	s.handleBlockAnnounceMessage(testPeerID, testHandshake)
-	require.True(t, s.blockAnnounceHandshakes[testPeerID].received)
-	require.False(t, s.blockAnnounceHandshakes[testPeerID].validated)
+	// a handshake with the wrong genesis hash fails validation and is evicted outright
+	err := s.handleNotificationsMessage(np, testPeerID, testHandshake, nil)
+	require.Equal(t, errCannotValidateHandshake, err)
+	data, has := s.peerManager.getHandshakeData(testPeerID, np.protocolID)
+	require.False(t, has)
+	require.Nil(t, data)
 
 	testHandshake = &BlockAnnounceHandshake{
 		Roles:           4,
@@ -130,7 +125,9 @@ func TestHandleBlockAnnounceMessage_BlockAnnounceHandshake(t *testing.T) {
 		GenesisHash:     s.blockState.GenesisHash(),
 	}
 
-	s.handleBlockAnnounceMessage(testPeerID, testHandshake)
-	require.True(t, s.blockAnnounceHandshakes[testPeerID].received)
-	require.True(t, s.blockAnnounceHandshakes[testPeerID].validated)
+	s.handleNotificationsMessage(np, testPeerID, testHandshake, nil) //nolint
+	data, has = s.peerManager.getHandshakeData(testPeerID, np.protocolID)
+	require.True(t, has)
+	require.True(t, data.received)
+	require.True(t, data.validated)
 }
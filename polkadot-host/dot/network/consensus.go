@@ -0,0 +1,174 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ChainSafe/gossamer/lib/scale"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	// ConsensusMsgType is the message type for a ConsensusMessage
+	ConsensusMsgType = 5
+
+	// consensusID is the protocol ID for the consensus notifications sub-protocol, used to
+	// gossip GRANDPA and BABE messages between peers
+	consensusID = "/paritytech/grandpa/1"
+)
+
+// ConsensusEngineID identifies which consensus engine a ConsensusMessage belongs to
+type ConsensusEngineID [4]byte
+
+var (
+	// GrandpaEngineID identifies GRANDPA vote and neighbour messages
+	GrandpaEngineID = ConsensusEngineID{'F', 'R', 'N', 'K'}
+	// BabeEngineID identifies BABE messages, eg. equivocation reports
+	BabeEngineID = ConsensusEngineID{'B', 'A', 'B', 'E'}
+)
+
+// ConsensusMessage is a gossip message for a consensus engine (GRANDPA or BABE); Data is
+// opaque to the network package and is passed to the engine identified by ConsensusEngineID
+type ConsensusMessage struct {
+	ConsensusEngineID ConsensusEngineID
+	Data              []byte
+}
+
+// String formats a ConsensusMessage as a string
+func (cm *ConsensusMessage) String() string {
+	return fmt.Sprintf("ConsensusMessage ConsensusEngineID=%v Data=%x", cm.ConsensusEngineID, cm.Data)
+}
+
+// Encode encodes a ConsensusMessage using SCALE
+func (cm *ConsensusMessage) Encode() ([]byte, error) {
+	return scale.Encode(cm)
+}
+
+// Decode decodes a SCALE-encoded ConsensusMessage from r
+func (cm *ConsensusMessage) Decode(r io.Reader) error {
+	sd := scale.Decoder{Reader: r}
+	_, err := sd.Decode(cm)
+	return err
+}
+
+// Type returns ConsensusMsgType
+func (cm *ConsensusMessage) Type() int {
+	return ConsensusMsgType
+}
+
+// IDString returns the empty string, since ConsensusMessages aren't deduplicated by ID
+func (cm *ConsensusMessage) IDString() string {
+	return ""
+}
+
+// ConsensusHandshake is exchanged by nodes that are beginning the consensus notifications
+// sub-protocol
+type ConsensusHandshake struct {
+	Roles byte
+}
+
+// String formats a ConsensusHandshake as a string
+func (hs *ConsensusHandshake) String() string {
+	return fmt.Sprintf("ConsensusHandshake Roles=%d", hs.Roles)
+}
+
+// Encode encodes a ConsensusHandshake message using SCALE
+func (hs *ConsensusHandshake) Encode() ([]byte, error) {
+	return scale.Encode(hs)
+}
+
+// Decode the message into a ConsensusHandshake
+func (hs *ConsensusHandshake) Decode(r io.Reader) error {
+	sd := scale.Decoder{Reader: r}
+	_, err := sd.Decode(hs)
+	return err
+}
+
+// Type ...
+func (hs *ConsensusHandshake) Type() int {
+	return -1
+}
+
+// IDString ...
+func (hs *ConsensusHandshake) IDString() string {
+	return ""
+}
+
+// IsValid always returns true, since a ConsensusHandshake has no invariant beyond being
+// decodable
+func (hs *ConsensusHandshake) IsValid() bool {
+	return true
+}
+
+func decodeConsensusHandshake(in []byte) (Handshake, error) {
+	r := &bytes.Buffer{}
+	if _, err := r.Write(in); err != nil {
+		return nil, err
+	}
+
+	hs := new(ConsensusHandshake)
+	return hs, hs.Decode(r)
+}
+
+func decodeConsensusMessage(in []byte, _ peer.ID) (Message, error) {
+	r := &bytes.Buffer{}
+	if _, err := r.Write(in); err != nil {
+		return nil, err
+	}
+
+	cm := new(ConsensusMessage)
+	return cm, cm.Decode(r)
+}
+
+func (s *Service) getConsensusHandshake() (Handshake, error) {
+	return &ConsensusHandshake{
+		Roles: s.cfg.Roles,
+	}, nil
+}
+
+func (s *Service) validateConsensusHandshake(_ peer.ID, hs Handshake) error {
+	_, ok := hs.(*ConsensusHandshake)
+	if !ok {
+		return errors.New("invalid handshake type")
+	}
+
+	return nil
+}
+
+// handleConsensusMessage forwards a gossiped ConsensusMessage to the core service, which
+// dispatches it to the consensus engine identified by ConsensusEngineID
+func (s *Service) handleConsensusMessage(peer peer.ID, msg Message) error {
+	cm, ok := msg.(*ConsensusMessage)
+	if !ok {
+		return nil
+	}
+
+	logger.Trace("received ConsensusMessage", "peer", peer, "message", cm)
+
+	if s.messageHandler == nil {
+		logger.Crit("Failed to handle consensus message", "error", "message handler is nil")
+		return nil
+	}
+
+	s.messageHandler.HandleMessage(cm)
+	return nil
+}
@@ -0,0 +1,304 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/scale"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	// LightRequestMsgType is the message type for a LightRequest
+	LightRequestMsgType = 6
+	// LightResponseMsgType is the message type for a LightResponse
+	LightResponseMsgType = 7
+)
+
+// LightProvider answers remote light-client requests using the local block and storage
+// state, analogous to how Syncer answers full-node block requests
+type LightProvider interface {
+	// CreateLightResponse builds the response to a light-client request received over
+	// the /light/2 protocol
+	CreateLightResponse(*LightRequest) (*LightResponse, error)
+}
+
+// RemoteCallRequest asks a full node to execute the runtime function Method with
+// parameter Data against the state at block Block
+type RemoteCallRequest struct {
+	Block  common.Hash
+	Method string
+	Data   []byte
+}
+
+// RemoteReadRequest asks a full node for the values of Keys in storage at block Block
+type RemoteReadRequest struct {
+	Block common.Hash
+	Keys  [][]byte
+}
+
+// RemoteReadChildRequest asks a full node for the values of Keys in the child storage
+// trie rooted at StorageKey, at block Block
+type RemoteReadChildRequest struct {
+	Block      common.Hash
+	StorageKey []byte
+	Keys       [][]byte
+}
+
+// RemoteHeaderRequest asks a full node for the header of Block
+type RemoteHeaderRequest struct {
+	Block common.Hash
+}
+
+// LightRequest is sent over the /light/2 protocol; exactly one field is set, mirroring
+// Substrate's light client request enum
+type LightRequest struct {
+	RemoteCallRequest      *RemoteCallRequest
+	RemoteReadRequest      *RemoteReadRequest
+	RemoteReadChildRequest *RemoteReadChildRequest
+	RemoteHeaderRequest    *RemoteHeaderRequest
+}
+
+// RemoteCallResponse is the SCALE-encoded return value of a RemoteCallRequest, with a
+// proof of its inclusion in the trie at the requested block
+type RemoteCallResponse struct {
+	Proof []byte
+}
+
+// RemoteReadResponse is the SCALE-encoded value(s) read from storage for a
+// RemoteReadRequest or RemoteReadChildRequest, with a proof of their inclusion in the
+// trie at the requested block
+type RemoteReadResponse struct {
+	Proof []byte
+}
+
+// RemoteHeaderResponse is the requested header, with a proof of its inclusion in the
+// chain at the requested block
+type RemoteHeaderResponse struct {
+	Header *types.Header
+	Proof  []byte
+}
+
+// LightResponse is sent in response to a LightRequest; the populated field matches the
+// variant of the request it answers
+type LightResponse struct {
+	RemoteCallResponse   *RemoteCallResponse
+	RemoteReadResponse   *RemoteReadResponse
+	RemoteHeaderResponse *RemoteHeaderResponse
+}
+
+// String formats a LightRequest as a string
+func (lr *LightRequest) String() string {
+	return fmt.Sprintf("LightRequest RemoteCallRequest=%v RemoteReadRequest=%v RemoteReadChildRequest=%v RemoteHeaderRequest=%v",
+		lr.RemoteCallRequest, lr.RemoteReadRequest, lr.RemoteReadChildRequest, lr.RemoteHeaderRequest)
+}
+
+// Encode encodes a LightRequest using SCALE, prefixed with a byte identifying which
+// variant is populated
+func (lr *LightRequest) Encode() ([]byte, error) {
+	switch {
+	case lr.RemoteCallRequest != nil:
+		enc, err := scale.Encode(lr.RemoteCallRequest)
+		return append([]byte{0}, enc...), err
+	case lr.RemoteReadRequest != nil:
+		enc, err := scale.Encode(lr.RemoteReadRequest)
+		return append([]byte{1}, enc...), err
+	case lr.RemoteReadChildRequest != nil:
+		enc, err := scale.Encode(lr.RemoteReadChildRequest)
+		return append([]byte{2}, enc...), err
+	case lr.RemoteHeaderRequest != nil:
+		enc, err := scale.Encode(lr.RemoteHeaderRequest)
+		return append([]byte{3}, enc...), err
+	default:
+		return nil, fmt.Errorf("LightRequest has no variant set")
+	}
+}
+
+// Decode decodes a SCALE-encoded LightRequest from r
+func (lr *LightRequest) Decode(r io.Reader) error {
+	variant := make([]byte, 1)
+	if _, err := r.Read(variant); err != nil {
+		return err
+	}
+
+	sd := scale.Decoder{Reader: r}
+
+	switch variant[0] {
+	case 0:
+		req := new(RemoteCallRequest)
+		if _, err := sd.Decode(req); err != nil {
+			return err
+		}
+		lr.RemoteCallRequest = req
+	case 1:
+		req := new(RemoteReadRequest)
+		if _, err := sd.Decode(req); err != nil {
+			return err
+		}
+		lr.RemoteReadRequest = req
+	case 2:
+		req := new(RemoteReadChildRequest)
+		if _, err := sd.Decode(req); err != nil {
+			return err
+		}
+		lr.RemoteReadChildRequest = req
+	case 3:
+		req := new(RemoteHeaderRequest)
+		if _, err := sd.Decode(req); err != nil {
+			return err
+		}
+		lr.RemoteHeaderRequest = req
+	default:
+		return fmt.Errorf("invalid LightRequest variant %d", variant[0])
+	}
+
+	return nil
+}
+
+// Type returns LightRequestMsgType
+func (lr *LightRequest) Type() int {
+	return LightRequestMsgType
+}
+
+// IDString returns the empty string, since LightRequests aren't deduplicated by ID
+func (lr *LightRequest) IDString() string {
+	return ""
+}
+
+// String formats a LightResponse as a string
+func (lr *LightResponse) String() string {
+	return fmt.Sprintf("LightResponse RemoteCallResponse=%v RemoteReadResponse=%v RemoteHeaderResponse=%v",
+		lr.RemoteCallResponse, lr.RemoteReadResponse, lr.RemoteHeaderResponse)
+}
+
+// Encode encodes a LightResponse using SCALE, prefixed with a byte identifying which
+// variant is populated
+func (lr *LightResponse) Encode() ([]byte, error) {
+	switch {
+	case lr.RemoteCallResponse != nil:
+		enc, err := scale.Encode(lr.RemoteCallResponse)
+		return append([]byte{0}, enc...), err
+	case lr.RemoteReadResponse != nil:
+		enc, err := scale.Encode(lr.RemoteReadResponse)
+		return append([]byte{1}, enc...), err
+	case lr.RemoteHeaderResponse != nil:
+		enc, err := scale.Encode(lr.RemoteHeaderResponse)
+		return append([]byte{2}, enc...), err
+	default:
+		return nil, fmt.Errorf("LightResponse has no variant set")
+	}
+}
+
+// Decode decodes a SCALE-encoded LightResponse from r
+func (lr *LightResponse) Decode(r io.Reader) error {
+	variant := make([]byte, 1)
+	if _, err := r.Read(variant); err != nil {
+		return err
+	}
+
+	sd := scale.Decoder{Reader: r}
+
+	switch variant[0] {
+	case 0:
+		resp := new(RemoteCallResponse)
+		if _, err := sd.Decode(resp); err != nil {
+			return err
+		}
+		lr.RemoteCallResponse = resp
+	case 1:
+		resp := new(RemoteReadResponse)
+		if _, err := sd.Decode(resp); err != nil {
+			return err
+		}
+		lr.RemoteReadResponse = resp
+	case 2:
+		resp := new(RemoteHeaderResponse)
+		if _, err := sd.Decode(resp); err != nil {
+			return err
+		}
+		lr.RemoteHeaderResponse = resp
+	default:
+		return fmt.Errorf("invalid LightResponse variant %d", variant[0])
+	}
+
+	return nil
+}
+
+// Type returns LightResponseMsgType
+func (lr *LightResponse) Type() int {
+	return LightResponseMsgType
+}
+
+// IDString returns the empty string, since LightResponses aren't deduplicated by ID
+func (lr *LightResponse) IDString() string {
+	return ""
+}
+
+func decodeLightMessage(in []byte, _ peer.ID) (Message, error) {
+	r := &bytes.Buffer{}
+	if _, err := r.Write(in); err != nil {
+		return nil, err
+	}
+
+	msg := new(LightRequest)
+	return msg, msg.Decode(r)
+}
+
+// handleLightStream handles streams with the <protocol-id>/light/2 protocol ID
+func (s *Service) handleLightStream(stream libp2pnetwork.Stream) {
+	conn := stream.Conn()
+	if conn == nil {
+		logger.Error("Failed to get connection from stream")
+		return
+	}
+
+	peer := conn.RemotePeer()
+	s.readStream(stream, peer, decodeLightMessage, s.handleLightMessage, maxLightMessageSize)
+}
+
+// handleLightMessage answers a LightRequest using s.lightProvider and sends the
+// resulting LightResponse back to the requesting peer
+func (s *Service) handleLightMessage(peer peer.ID, msg Message) error {
+	req, ok := msg.(*LightRequest)
+	if !ok {
+		return nil
+	}
+
+	if s.lightProvider == nil {
+		logger.Debug("Ignoring LightRequest, node does not serve light clients", "peer", peer)
+		return nil
+	}
+
+	resp, err := s.lightProvider.CreateLightResponse(req)
+	if err != nil {
+		logger.Debug("cannot create response for LightRequest", "peer", peer, "error", err)
+		return nil
+	}
+
+	if err := s.host.send(peer, lightID, resp); err != nil {
+		logger.Error("failed to send LightResponse message", "peer", peer)
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"errors"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+var errCannotValidateHandshake = errors.New("failed to validate handshake")
+
+// Handshake is exchanged by nodes that are beginning a notifications sub-protocol
+type Handshake interface {
+	Message
+	IsValid() bool
+}
+
+// handshakeData is the handshake state for a single peer on a single notifications
+// sub-protocol. It's only ever constructed by PeerManager.Begin and mutated through
+// PeerManager.MarkValidated, so its fields stay consistent with the timer PeerManager arms
+// alongside them.
+type handshakeData struct {
+	received  bool
+	validated bool
+	handshake Handshake
+
+	// stream is the inbound stream the handshake arrived on, if any; see PeerManager.Begin.
+	stream libp2pnetwork.Stream
+	// timer evicts this entry if it's still unvalidated when handshakeTimeout elapses.
+	timer *time.Timer
+}
+
+// notificationsProtocol represents a notifications sub-protocol, eg. the block-announce
+// or GRANDPA neighbour-packet protocols. It carries everything the generic stream handler
+// and SendMessage dispatch need to run the protocol's handshake and exchange its messages,
+// so that adding a new sub-protocol doesn't require forking readStream or SendMessage.
+// Per-peer handshake state is tracked by Service's peerManager, keyed by protocolID, rather
+// than on the notificationsProtocol itself.
+type notificationsProtocol struct {
+	protocolID         string
+	getHandshake       func() (Handshake, error)
+	handshakeDecoder   func([]byte) (Handshake, error)
+	handshakeValidator func(peer.ID, Handshake) error
+	messageDecoder     messageDecoder
+	messageHandler     messageHandler
+}
+
+// RegisterNotificationsProtocol registers a notifications sub-protocol with the network
+// service, so that s.SendMessage can dispatch messages of messageID to it and so that
+// incoming streams on protocolID are handshaken and decoded using the given functions.
+// getHandshake returns this node's own handshake; handshakeDecoder and handshakeValidator
+// decode and validate a handshake received from a peer; messageDecoder and messageHandler
+// decode and process the notification messages exchanged once a peer's handshake has been
+// validated.
+func (s *Service) RegisterNotificationsProtocol(
+	protocolID string,
+	messageID byte,
+	getHandshake func() (Handshake, error),
+	handshakeDecoder func([]byte) (Handshake, error),
+	handshakeValidator func(peer.ID, Handshake) error,
+	messageDecoder messageDecoder,
+	messageHandler messageHandler,
+) error {
+	if _, has := s.notificationsProtocols[messageID]; has {
+		return errors.New("notifications protocol with message type already exists")
+	}
+
+	np := &notificationsProtocol{
+		protocolID:         protocolID,
+		getHandshake:       getHandshake,
+		handshakeDecoder:   handshakeDecoder,
+		handshakeValidator: handshakeValidator,
+		messageDecoder:     messageDecoder,
+		messageHandler:     messageHandler,
+	}
+
+	s.notificationsProtocols[messageID] = np
+	s.host.registerStreamHandler(protocolID, s.createNotificationsMessageHandler(np))
+	return nil
+}
+
+// createNotificationsMessageHandler returns a stream handler for a notifications
+// sub-protocol: it decodes each peer's handshake before the handshake has been received
+// and otherwise decodes a notification message, handing either to handleNotificationsMessage.
+func (s *Service) createNotificationsMessageHandler(np *notificationsProtocol) func(libp2pnetwork.Stream) {
+	return func(stream libp2pnetwork.Stream) {
+		conn := stream.Conn()
+		if conn == nil {
+			logger.Error("Failed to get connection from stream")
+			return
+		}
+
+		p := conn.RemotePeer()
+
+		decoder := func(in []byte, peer peer.ID) (Message, error) {
+			data, has := s.peerManager.getHandshakeData(peer, np.protocolID)
+			if !has || !data.received {
+				return np.handshakeDecoder(in)
+			}
+
+			return np.messageDecoder(in, peer)
+		}
+
+		handler := func(peer peer.ID, msg Message) error {
+			return s.handleNotificationsMessage(np, peer, msg, stream)
+		}
+
+		s.readStream(stream, p, decoder, handler, maxNotificationsMessageSize)
+	}
+}
+
+// handleNotificationsMessage routes an incoming notifications sub-protocol message to the
+// generic handshake state machine, or, once the handshake is complete, to np.messageHandler.
+// stream is the stream msg arrived on; it's only used if msg turns out to be a Handshake, so
+// handleHandshake can arm PeerManager's timeout eviction with something to reset.
+func (s *Service) handleNotificationsMessage(np *notificationsProtocol, peer peer.ID, msg Message, stream libp2pnetwork.Stream) error {
+	if hs, ok := msg.(Handshake); ok {
+		return s.handleHandshake(np, peer, hs, stream)
+	}
+
+	return np.messageHandler(peer, msg)
+}
+
+// handleHandshake implements the generic handshake state machine shared by every
+// notifications sub-protocol: validate a handshake the first time it's seen from a peer,
+// replying with our own handshake if we're the receiver. s.peerManager.Begin tracks which
+// side of this race we're on and arms a timeout that resets stream if the other side never
+// completes its half.
+func (s *Service) handleHandshake(np *notificationsProtocol, peer peer.ID, hs Handshake, stream libp2pnetwork.Stream) error {
+	data, isNew := s.peerManager.Begin(peer, np.protocolID, stream)
+
+	// we are the receiver and haven't received the peer's handshake already: validate it
+	if isNew {
+		logger.Trace("receiver: validating handshake", "protocol", np.protocolID, "peer", peer)
+
+		if err := np.handshakeValidator(peer, hs); err != nil {
+			logger.Error("failed to validate handshake", "protocol", np.protocolID, "peer", peer, "error", err)
+			s.peerManager.Delete(peer, np.protocolID)
+			return errCannotValidateHandshake
+		}
+
+		s.peerManager.MarkValidated(peer, np.protocolID, hs)
+
+		resp, err := np.getHandshake()
+		if err != nil {
+			logger.Error("failed to get handshake", "protocol", np.protocolID, "error", err)
+			return nil
+		}
+
+		if err := s.host.send(peer, np.protocolID, resp); err != nil {
+			logger.Error("failed to send handshake", "protocol", np.protocolID, "peer", peer, "error", err)
+		}
+
+		logger.Trace("receiver: sent handshake", "protocol", np.protocolID, "peer", peer)
+		return nil
+	}
+
+	// we are the initiator and haven't validated the peer's handshake already
+	if !data.validated {
+		logger.Trace("sender: validating handshake", "protocol", np.protocolID, "peer", peer)
+
+		if err := np.handshakeValidator(peer, hs); err != nil {
+			logger.Error("failed to validate handshake", "protocol", np.protocolID, "peer", peer, "error", err)
+			s.peerManager.Delete(peer, np.protocolID)
+			return errCannotValidateHandshake
+		}
+
+		s.peerManager.MarkValidated(peer, np.protocolID, hs)
+		logger.Trace("sender: validated handshake", "protocol", np.protocolID, "peer", peer)
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"sync"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// defaultHandshakeTimeout is how long PeerManager waits for a handshake begun with Begin to
+// be completed via MarkValidated before evicting it and resetting its stream, if one is set.
+const defaultHandshakeTimeout = 10 * time.Second
+
+// PeerManager tracks per-peer, per-protocol handshake state for every notifications
+// sub-protocol. Previously each notificationsProtocol kept its own sync.Map of peer
+// state, so a disconnecting peer had to be cleaned up protocol-by-protocol; PeerManager
+// gives Service a single place to do that.
+type PeerManager struct {
+	mu               sync.RWMutex
+	peers            map[peer.ID]map[string]*handshakeData // peer -> protocolID -> handshake state
+	handshakeTimeout time.Duration
+}
+
+// newPeerManager creates an empty PeerManager with the default handshake timeout. Use
+// SetHandshakeTimeout to override it, eg. in tests that need a shorter deadline.
+func newPeerManager() *PeerManager {
+	return &PeerManager{
+		peers:            make(map[peer.ID]map[string]*handshakeData),
+		handshakeTimeout: defaultHandshakeTimeout,
+	}
+}
+
+// SetHandshakeTimeout overrides the deadline Begin enforces on handshakes started after this
+// call. It does not affect handshakes already in flight.
+func (pm *PeerManager) SetHandshakeTimeout(d time.Duration) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.handshakeTimeout = d
+}
+
+// getHandshakeData returns the handshake state tracked for p on protocolID, if any
+func (pm *PeerManager) getHandshakeData(p peer.ID, protocolID string) (*handshakeData, bool) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	protocols, has := pm.peers[p]
+	if !has {
+		return nil, false
+	}
+
+	data, has := protocols[protocolID]
+	return data, has
+}
+
+// Begin starts tracking a handshake with p on protocolID, if one isn't already in flight, and
+// arms a handshakeTimeout deadline for it: if the handshake isn't completed via MarkValidated
+// before the deadline fires, the entry is evicted and stream is reset, if non-nil. stream is
+// the inbound stream the handshake arrived on, if any; the outbound initiator in
+// Service.SendMessage doesn't have one to hand over, since it's host.send's to manage, so it
+// passes nil and that side of a handshake can only be evicted by timing out, not reset.
+// Begin returns the tracked state and whether this call created it; a false isNew means a
+// handshake with p on protocolID was already begun, by either side of the race between an
+// inbound stream and an outbound SendMessage targeting the same peer.
+func (pm *PeerManager) Begin(p peer.ID, protocolID string, stream libp2pnetwork.Stream) (data *handshakeData, isNew bool) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	protocols, has := pm.peers[p]
+	if !has {
+		protocols = make(map[string]*handshakeData)
+		pm.peers[p] = protocols
+	}
+
+	if existing, has := protocols[protocolID]; has {
+		return existing, false
+	}
+
+	data = &handshakeData{stream: stream}
+	data.timer = time.AfterFunc(pm.handshakeTimeout, func() {
+		pm.timeout(p, protocolID)
+	})
+	protocols[protocolID] = data
+	return data, true
+}
+
+// MarkValidated records hs as p's validated handshake on protocolID and disarms the timeout
+// timer Begin armed for it, if it hasn't already fired.
+func (pm *PeerManager) MarkValidated(p peer.ID, protocolID string, hs Handshake) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	data, has := pm.peers[p][protocolID]
+	if !has {
+		return
+	}
+
+	if data.timer != nil {
+		data.timer.Stop()
+	}
+	data.received = true
+	data.validated = true
+	data.handshake = hs
+}
+
+// Delete clears the handshake state tracked for p on protocolID, eg. after a failed
+// validation, disarming its timeout timer if it hasn't already fired.
+func (pm *PeerManager) Delete(p peer.ID, protocolID string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	protocols, has := pm.peers[p]
+	if !has {
+		return
+	}
+
+	if data, has := protocols[protocolID]; has && data.timer != nil {
+		data.timer.Stop()
+	}
+	delete(protocols, protocolID)
+}
+
+// timeout is invoked by a handshake's deadline timer, armed in Begin, if it fires before
+// MarkValidated or Delete won the race. time.Timer.Stop does not guarantee a timer's function
+// won't still run after Stop returns if it had already begun firing, so timeout re-checks
+// data.validated under pm.mu rather than trusting that MarkValidated's Stop call prevented this
+// call from happening at all: without that check, a handshake validated just as its deadline
+// fired could still be evicted and its stream reset here, after MarkValidated already released
+// the lock. It evicts the entry and resets the stream the handshake arrived on, if Begin was
+// given one.
+func (pm *PeerManager) timeout(p peer.ID, protocolID string) {
+	pm.mu.Lock()
+	protocols, has := pm.peers[p]
+	var data *handshakeData
+	if has {
+		data, has = protocols[protocolID]
+		if has {
+			if data.validated {
+				has = false
+			} else {
+				delete(protocols, protocolID)
+			}
+		}
+	}
+	pm.mu.Unlock()
+
+	if !has {
+		return
+	}
+
+	logger.Debug("handshake timed out, evicting peer", "peer", p, "protocol", protocolID)
+	if data.stream != nil {
+		_ = data.stream.Reset()
+	}
+}
+
+// removePeer clears all per-protocol state tracked for p, eg. when its connection closes,
+// stopping any handshake timers still pending so they don't fire for a peer that's already gone.
+func (pm *PeerManager) removePeer(p peer.ID) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	for _, data := range pm.peers[p] {
+		if data.timer != nil {
+			data.timer.Stop()
+		}
+	}
+	delete(pm.peers, p)
+}
+
+// newPeerManagerNotifiee returns a libp2pnetwork.Notifiee that evicts a peer's handshake state
+// via removePeer as soon as its connection closes, so PeerManager.peers doesn't grow without
+// bound as peers churn. It's registered with the host in Service.Start.
+func newPeerManagerNotifiee(pm *PeerManager) libp2pnetwork.Notifiee {
+	return &libp2pnetwork.NotifyBundle{
+		DisconnectedF: func(_ libp2pnetwork.Network, conn libp2pnetwork.Conn) {
+			pm.removePeer(conn.RemotePeer())
+		},
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+const testProtocolID = "/test/1"
+
+// TestPeerManagerBegin_ConcurrentHandshakes simulates an inbound and an outbound handshake
+// to the same peer racing each other: only one Begin call may win and create the entry, and
+// every caller (inbound or outbound) must observe the same state afterwards.
+func TestPeerManagerBegin_ConcurrentHandshakes(t *testing.T) {
+	pm := newPeerManager()
+	testPeerID := peer.ID("alice")
+
+	const attempts = 50
+	var wg sync.WaitGroup
+	newCount := make(chan bool, attempts)
+
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, isNew := pm.Begin(testPeerID, testProtocolID, nil)
+			newCount <- isNew
+		}()
+	}
+
+	wg.Wait()
+	close(newCount)
+
+	winners := 0
+	for isNew := range newCount {
+		if isNew {
+			winners++
+		}
+	}
+	require.Equal(t, 1, winners)
+
+	data, has := pm.getHandshakeData(testPeerID, testProtocolID)
+	require.True(t, has)
+	require.False(t, data.validated)
+}
+
+// TestPeerManagerMarkValidated_DisarmsTimeout ensures a handshake marked validated before its
+// deadline is never evicted, even after the deadline would otherwise have elapsed.
+func TestPeerManagerMarkValidated_DisarmsTimeout(t *testing.T) {
+	pm := newPeerManager()
+	pm.SetHandshakeTimeout(25 * time.Millisecond)
+	testPeerID := peer.ID("bob")
+
+	_, isNew := pm.Begin(testPeerID, testProtocolID, nil)
+	require.True(t, isNew)
+
+	pm.MarkValidated(testPeerID, testProtocolID, &BlockAnnounceHandshake{})
+
+	time.Sleep(75 * time.Millisecond)
+
+	data, has := pm.getHandshakeData(testPeerID, testProtocolID)
+	require.True(t, has)
+	require.True(t, data.validated)
+}
+
+// TestPeerManagerTimeout_DoesNotEvictAlreadyValidatedHandshake simulates the deadline timer
+// firing just after MarkValidated won the race: timeout is called directly (rather than relying
+// on SetHandshakeTimeout's real timer) so the race is deterministic instead of relying on
+// scheduling luck.
+func TestPeerManagerTimeout_DoesNotEvictAlreadyValidatedHandshake(t *testing.T) {
+	pm := newPeerManager()
+	testPeerID := peer.ID("frank")
+
+	pm.Begin(testPeerID, testProtocolID, nil)
+	pm.MarkValidated(testPeerID, testProtocolID, &BlockAnnounceHandshake{})
+
+	// The timer's AfterFunc had already started running by the time MarkValidated's Stop call
+	// ran, so it still reaches timeout even though the handshake is now validated.
+	pm.timeout(testPeerID, testProtocolID)
+
+	data, has := pm.getHandshakeData(testPeerID, testProtocolID)
+	require.True(t, has, "timeout must not evict a handshake MarkValidated already won the race on")
+	require.True(t, data.validated)
+}
+
+// TestPeerManagerBegin_TimeoutEvicts ensures a handshake that's never validated is evicted
+// once SetHandshakeTimeout's deadline elapses.
+func TestPeerManagerBegin_TimeoutEvicts(t *testing.T) {
+	pm := newPeerManager()
+	pm.SetHandshakeTimeout(25 * time.Millisecond)
+	testPeerID := peer.ID("carol")
+
+	pm.Begin(testPeerID, testProtocolID, nil)
+
+	require.Eventually(t, func() bool {
+		_, has := pm.getHandshakeData(testPeerID, testProtocolID)
+		return !has
+	}, time.Second, 5*time.Millisecond)
+}
+
+// TestPeerManagerDelete_DisarmsTimeout ensures a handshake rejected outright (eg. a
+// genesis-hash mismatch) is evicted immediately and its timer doesn't fire a second, spurious
+// eviction afterwards.
+func TestPeerManagerDelete_DisarmsTimeout(t *testing.T) {
+	pm := newPeerManager()
+	pm.SetHandshakeTimeout(25 * time.Millisecond)
+	testPeerID := peer.ID("dave")
+
+	pm.Begin(testPeerID, testProtocolID, nil)
+	pm.Delete(testPeerID, testProtocolID)
+
+	_, has := pm.getHandshakeData(testPeerID, testProtocolID)
+	require.False(t, has)
+
+	time.Sleep(75 * time.Millisecond)
+
+	_, has = pm.getHandshakeData(testPeerID, testProtocolID)
+	require.False(t, has)
+}
+
+// TestPeerManagerRemovePeer_StopsPendingTimer ensures removePeer (invoked by the Notifiee on
+// disconnect) stops a still-pending handshake timer rather than leaving it to fire later
+// against an already-evicted peer.
+func TestPeerManagerRemovePeer_StopsPendingTimer(t *testing.T) {
+	pm := newPeerManager()
+	pm.SetHandshakeTimeout(25 * time.Millisecond)
+	testPeerID := peer.ID("eve")
+
+	pm.Begin(testPeerID, testProtocolID, nil)
+	pm.removePeer(testPeerID)
+
+	_, has := pm.getHandshakeData(testPeerID, testProtocolID)
+	require.False(t, has)
+}
@@ -20,7 +20,6 @@ import (
 	"bufio"
 	"context"
 	"errors"
-	"math/big"
 	"os"
 	"time"
 
@@ -39,6 +38,14 @@ const (
 	// the following are sub-protocols used by the node
 	syncID          = "/sync/2"
 	blockAnnounceID = "/block-announces/1"
+	lightID         = "/light/2"
+
+	// the following are the maximum sizes, in bytes, of a single message readStream will
+	// accept for each sub-protocol, to bound how much memory a peer can make us allocate
+	// for one message
+	maxBlockResponseSize        = 1 << 22 // 4 MiB; BlockResponses can carry many blocks
+	maxLightMessageSize         = 1 << 20 // 1 MiB; light responses carry storage proofs
+	maxNotificationsMessageSize = 1 << 16 // 64 KiB; handshakes and gossip messages
 )
 
 var (
@@ -60,19 +67,26 @@ type Service struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	cfg                     *Config
-	host                    *host
-	mdns                    *mdns
-	status                  *status
-	gossip                  *gossip
-	requestTracker          *requestTracker
-	errCh                   chan<- error
-	blockAnnounceHandshakes map[peer.ID]*blockAnnounceData
+	cfg    *Config
+	host   *host
+	mdns   *mdns
+	status *status
+	gossip *gossip
+	errCh  chan<- error
+
+	// notificationsProtocols tracks each registered notifications sub-protocol (eg.
+	// block-announce), keyed by the message type it carries. see RegisterNotificationsProtocol.
+	notificationsProtocols map[byte]*notificationsProtocol
+
+	// peerManager tracks per-peer, per-protocol handshake state for every notifications
+	// sub-protocol registered above
+	peerManager *PeerManager
 
 	// Service interfaces
-	blockState   BlockState
-	networkState NetworkState
-	syncer       Syncer
+	blockState    BlockState
+	networkState  NetworkState
+	syncingEngine *SyncingEngine
+	lightProvider LightProvider
 
 	// Interface for inter-process communication
 	messageHandler MessageHandler
@@ -110,26 +124,53 @@ func NewService(cfg *Config) (*Service, error) {
 	}
 
 	network := &Service{
-		ctx:                     ctx,
-		cancel:                  cancel,
-		cfg:                     cfg,
-		host:                    host,
-		mdns:                    newMDNS(host),
-		status:                  newStatus(host),
-		gossip:                  newGossip(host),
-		requestTracker:          newRequestTracker(logger),
-		blockState:              cfg.BlockState,
-		networkState:            cfg.NetworkState,
-		messageHandler:          cfg.MessageHandler,
-		noBootstrap:             cfg.NoBootstrap,
-		noMDNS:                  cfg.NoMDNS,
-		noStatus:                cfg.NoStatus,
-		syncer:                  cfg.Syncer,
-		errCh:                   cfg.ErrChan,
-		blockAnnounceHandshakes: make(map[peer.ID]*blockAnnounceData),
+		ctx:                    ctx,
+		cancel:                 cancel,
+		cfg:                    cfg,
+		host:                   host,
+		mdns:                   newMDNS(host),
+		status:                 newStatus(host),
+		gossip:                 newGossip(host),
+		blockState:             cfg.BlockState,
+		networkState:           cfg.NetworkState,
+		lightProvider:          cfg.LightProvider,
+		messageHandler:         cfg.MessageHandler,
+		noBootstrap:            cfg.NoBootstrap,
+		noMDNS:                 cfg.NoMDNS,
+		noStatus:               cfg.NoStatus,
+		syncingEngine:          NewSyncingEngine(cfg.BlockState, cfg.Syncer, host.send),
+		errCh:                  cfg.ErrChan,
+		notificationsProtocols: make(map[byte]*notificationsProtocol),
+		peerManager:            newPeerManager(),
+	}
+
+	err = network.RegisterNotificationsProtocol(
+		blockAnnounceID,
+		BlockAnnounceMsgType,
+		network.getBlockAnnounceHandshake,
+		decodeBlockAnnounceHandshake,
+		network.validateBlockAnnounceHandshake,
+		decodeBlockAnnounceMessage,
+		network.handleBlockAnnounceMessage,
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	return network, err
+	err = network.RegisterNotificationsProtocol(
+		consensusID,
+		ConsensusMsgType,
+		network.getConsensusHandshake,
+		decodeConsensusHandshake,
+		network.validateConsensusHandshake,
+		decodeConsensusMessage,
+		network.handleConsensusMessage,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return network, nil
 }
 
 // Start starts the network service
@@ -142,9 +183,14 @@ func (s *Service) Start() error {
 	go s.updateNetworkState()
 
 	s.host.registerConnHandler(s.handleConn)
+	// evict a peer's handshake state as soon as its connection closes, so peerManager.peers
+	// doesn't grow without bound as peers come and go
+	s.host.registerNotifiee(newPeerManagerNotifiee(s.peerManager))
 	s.host.registerStreamHandler("", s.handleStream)
 	s.host.registerStreamHandler(syncID, s.handleSyncStream)
-	s.host.registerStreamHandler(blockAnnounceID, s.handleBlockAnnounceStream)
+	s.host.registerStreamHandler(lightID, s.handleLightStream)
+	// notifications sub-protocols (eg. blockAnnounceID) register their own stream
+	// handlers when they're added via RegisterNotificationsProtocol
 
 	// log listening addresses to console
 	for _, addr := range s.host.multiaddrs() {
@@ -223,39 +269,39 @@ func (s *Service) SendMessage(msg Message) {
 		"type", msg.Type(),
 	)
 
-	switch msg.Type() {
-	case BlockAnnounceMsgType:
-		// create handshake and send to all peers that haven't already completed the handshake
-		hs, err := s.getBlockAnnounceHandshake()
-		if err != nil {
-			logger.Error("failed to get BlockAnnounceHandshake", "error", err)
-			return
-		}
-
-		for _, peer := range s.host.peers() { // TODO: check if stream is open, if not, open and send handshake
-			if _, has := s.blockAnnounceHandshakes[peer]; !has {
-				s.blockAnnounceHandshakes[peer] = &blockAnnounceData{
-					validated: false,
-					msg:       msg.(*BlockAnnounceMessage),
-				}
+	np, has := s.notificationsProtocols[byte(msg.Type())]
+	if !has {
+		// broadcast message to connected peers
+		s.host.broadcast(msg)
+		return
+	}
 
-				logger.Trace("sending BlockAnnounceHandshake", "peer", peer, "message", hs)
-				err = s.host.send(peer, blockAnnounceID, hs)
-			} else {
-				// we've already completed the handshake with the peer, send BlockAnnounce directly
-				err = s.host.send(peer, blockAnnounceID, msg)
-			}
+	// create handshake and send to all peers that haven't already completed the handshake
+	hs, err := np.getHandshake()
+	if err != nil {
+		logger.Error("failed to get handshake", "protocol", np.protocolID, "error", err)
+		return
+	}
 
-			if err != nil {
-				logger.Error("failed to send message to peer", "peer", peer, "error", err)
-			}
+	for _, peer := range s.host.peers() { // TODO: check if stream is open, if not, open and send handshake
+		data, has := s.peerManager.getHandshakeData(peer, np.protocolID)
+		if !has || !data.validated {
+			// we're the initiator, so we have no inbound stream to hand PeerManager for a
+			// timeout reset; a handshake that never completes here can only be evicted by
+			// timing out, not by resetting a stream we don't have.
+			s.peerManager.Begin(peer, np.protocolID, nil)
+
+			logger.Trace("sending handshake", "protocol", np.protocolID, "peer", peer, "message", hs)
+			err = s.host.send(peer, np.protocolID, hs)
+		} else {
+			// we've already completed the handshake with the peer, send the message directly
+			err = s.host.send(peer, np.protocolID, msg)
 		}
 
-		return
+		if err != nil {
+			logger.Error("failed to send message to peer", "peer", peer, "error", err)
+		}
 	}
-
-	// broadcast message to connected peers
-	s.host.broadcast(msg)
 }
 
 // handleConn starts processes that manage the connection
@@ -299,7 +345,7 @@ func (s *Service) handleStream(stream libp2pnetwork.Stream) {
 	}
 
 	peer := conn.RemotePeer()
-	s.readStream(stream, peer, decodeMessageBytes, s.handleMessage)
+	s.readStream(stream, peer, decodeMessageBytes, s.handleMessage, maxNotificationsMessageSize)
 	// the stream stays open until closed or reset
 }
 
@@ -312,11 +358,12 @@ func (s *Service) handleSyncStream(stream libp2pnetwork.Stream) {
 	}
 
 	peer := conn.RemotePeer()
-	s.readStream(stream, peer, decodeMessageBytes, s.handleSyncMessage)
+	s.readStream(stream, peer, decodeMessageBytes, s.syncingEngine.HandleSyncMessage, maxBlockResponseSize)
 	// the stream stays open until closed or reset
 }
 
-func (s *Service) readStream(stream libp2pnetwork.Stream, peer peer.ID, decoder messageDecoder, handler messageHandler) {
+func (s *Service) readStream(stream libp2pnetwork.Stream, peer peer.ID, decoder messageDecoder,
+	handler messageHandler, maxMessageSize uint64) {
 	// create buffer stream for non-blocking read
 	r := bufio.NewReader(stream)
 
@@ -333,6 +380,13 @@ func (s *Service) readStream(stream libp2pnetwork.Stream, peer peer.ID, decoder
 			continue
 		}
 
+		if length > maxMessageSize {
+			logger.Error("Message size exceeds protocol limit", "length", length, "max", maxMessageSize)
+			_ = stream.Close()
+			s.errCh <- errors.New("message size exceeds protocol limit")
+			return
+		}
+
 		msgBytes := make([]byte, length)
 		tot := uint64(0)
 		for i := 0; i < maxReads; i++ {
@@ -380,43 +434,6 @@ func (s *Service) readStream(stream libp2pnetwork.Stream, peer peer.ID, decoder
 	}
 }
 
-// handleSyncMessage handles synchronization message types (BlockRequest and BlockResponse)
-func (s *Service) handleSyncMessage(peer peer.ID, msg Message) error {
-	if msg == nil {
-		return nil
-	}
-
-	// if it's a BlockResponse with an ID corresponding to a BlockRequest we sent, forward
-	// message to the sync service
-	if resp, ok := msg.(*BlockResponseMessage); ok && s.requestTracker.hasRequestedBlockID(resp.ID) {
-		s.requestTracker.removeRequestedBlockID(resp.ID)
-		req := s.syncer.HandleBlockResponse(resp)
-		if req != nil {
-			s.requestTracker.addRequestedBlockID(req.ID)
-			err := s.host.send(peer, syncID, req)
-			if err != nil {
-				logger.Error("failed to send BlockRequest message", "peer", peer)
-			}
-		}
-	}
-
-	// if it's a BlockRequest, call core for processing
-	if req, ok := msg.(*BlockRequestMessage); ok {
-		resp, err := s.syncer.CreateBlockResponse(req)
-		if err != nil {
-			logger.Debug("cannot create response for request", "id", req.ID)
-			return nil
-		}
-
-		err = s.host.send(peer, syncID, resp)
-		if err != nil {
-			logger.Error("failed to send BlockResponse message", "peer", peer)
-		}
-	}
-
-	return nil
-}
-
 // handleMessage handles the message based on peer status and message type
 // TODO: deprecate this handler, messages will be handled via their sub-protocols
 func (s *Service) handleMessage(peer peer.ID, msg Message) error {
@@ -449,14 +466,9 @@ func (s *Service) handleMessage(peer peer.ID, msg Message) error {
 			// check if peer status confirmed
 			if s.status.confirmed(peer) {
 
-				// send a block request message if peer best block number is greater than host best block number
-				req := s.handleStatusMesssage(msg.(*StatusMessage))
-				if req != nil {
-					s.requestTracker.addRequestedBlockID(req.ID)
-					err := s.host.send(peer, syncID, req)
-					if err != nil {
-						logger.Error("failed to send BlockRequest message", "peer", peer)
-					}
+				// request blocks from the peer if its best block number is greater than ours
+				if err := s.syncingEngine.HandleStatusMessage(peer, msg.(*StatusMessage)); err != nil {
+					logger.Error("failed to handle status message", "peer", peer, "error", err)
 				}
 			}
 		}
@@ -465,27 +477,6 @@ func (s *Service) handleMessage(peer peer.ID, msg Message) error {
 	return nil
 }
 
-// handleStatusMesssage returns a block request message if peer best block
-// number is greater than host best block number
-func (s *Service) handleStatusMesssage(statusMessage *StatusMessage) *BlockRequestMessage {
-	// get latest block header from block state
-	latestHeader, err := s.blockState.BestBlockHeader()
-	if err != nil {
-		logger.Error("Failed to get best block header from block state", "error", err)
-		return nil
-	}
-
-	bestBlockNum := big.NewInt(int64(statusMessage.BestBlockNumber))
-
-	// check if peer block number is greater than host block number
-	if latestHeader.Number.Cmp(bestBlockNum) == -1 {
-		logger.Debug("sending new block to syncer", "number", statusMessage.BestBlockNumber)
-		return s.syncer.HandleSeenBlocks(bestBlockNum)
-	}
-
-	return nil
-}
-
 // Health returns information about host needed for the rpc server
 func (s *Service) Health() common.Health {
 	return common.Health{
@@ -533,7 +524,7 @@ func (s *Service) NodeRoles() byte {
 	return s.cfg.Roles
 }
 
-//SetMessageHandler sets the given MessageHandler for this service
+// SetMessageHandler sets the given MessageHandler for this service
 func (s *Service) SetMessageHandler(handler MessageHandler) {
 	s.messageHandler = handler
 }
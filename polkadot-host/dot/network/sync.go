@@ -0,0 +1,124 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package network
+
+import (
+	"math/big"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// SyncingEngine drives block synchronisation over the /sync/2 protocol: it tracks
+// outstanding BlockRequests, forwards BlockResponses to the syncer, and decides when a
+// peer's status message warrants requesting blocks from them. Pulling this out of Service
+// lets the sync protocol be exercised and reasoned about on its own.
+type SyncingEngine struct {
+	blockState     BlockState
+	syncer         Syncer
+	requestTracker *requestTracker
+	send           func(peer.ID, string, Message) error
+}
+
+// NewSyncingEngine creates a SyncingEngine that looks up the local chain head in
+// blockState, drives block production decisions through syncer, and delivers
+// BlockRequest/BlockResponse messages to peers via send
+func NewSyncingEngine(blockState BlockState, syncer Syncer, send func(peer.ID, string, Message) error) *SyncingEngine {
+	return &SyncingEngine{
+		blockState:     blockState,
+		syncer:         syncer,
+		requestTracker: newRequestTracker(logger),
+		send:           send,
+	}
+}
+
+// HandleSyncMessage handles synchronization message types (BlockRequest and BlockResponse)
+func (e *SyncingEngine) HandleSyncMessage(peer peer.ID, msg Message) error {
+	if msg == nil {
+		return nil
+	}
+
+	// if it's a BlockResponse with an ID corresponding to a BlockRequest we sent, forward
+	// message to the sync service
+	if resp, ok := msg.(*BlockResponseMessage); ok && e.requestTracker.hasRequestedBlockID(resp.ID) {
+		e.requestTracker.removeRequestedBlockID(resp.ID)
+		req := e.syncer.HandleBlockResponse(resp)
+		if req != nil {
+			e.requestTracker.addRequestedBlockID(req.ID)
+			if err := e.send(peer, syncID, req); err != nil {
+				logger.Error("failed to send BlockRequest message", "peer", peer)
+			}
+		}
+	}
+
+	// if it's a BlockRequest, call core for processing
+	if req, ok := msg.(*BlockRequestMessage); ok {
+		resp, err := e.syncer.CreateBlockResponse(req)
+		if err != nil {
+			logger.Debug("cannot create response for request", "id", req.ID)
+			return nil
+		}
+
+		if err := e.send(peer, syncID, resp); err != nil {
+			logger.Error("failed to send BlockResponse message", "peer", peer)
+		}
+	}
+
+	return nil
+}
+
+// HandleBlockAnnounce sends peer a BlockRequest over the /sync/2 protocol if, per the
+// syncer, the block it just announced requires syncing more blocks from it
+func (e *SyncingEngine) HandleBlockAnnounce(peer peer.ID, an *BlockAnnounceMessage) error {
+	req := e.syncer.HandleBlockAnnounce(an)
+	if req == nil {
+		return nil
+	}
+
+	e.requestTracker.addRequestedBlockID(req.ID)
+	return e.send(peer, syncID, req)
+}
+
+// HandleStatusMessage sends peer a BlockRequest for our missing blocks if their best block
+// number, carried in statusMessage, is greater than our own
+func (e *SyncingEngine) HandleStatusMessage(peer peer.ID, statusMessage *StatusMessage) error {
+	// get latest block header from block state
+	latestHeader, err := e.blockState.BestBlockHeader()
+	if err != nil {
+		logger.Error("Failed to get best block header from block state", "error", err)
+		return err
+	}
+
+	bestBlockNum := big.NewInt(int64(statusMessage.BestBlockNumber))
+
+	// check if peer block number is greater than host block number
+	if latestHeader.Number.Cmp(bestBlockNum) != -1 {
+		return nil
+	}
+
+	logger.Debug("sending new block to syncer", "number", statusMessage.BestBlockNumber)
+	req := e.syncer.HandleSeenBlocks(bestBlockNum)
+	if req == nil {
+		return nil
+	}
+
+	e.requestTracker.addRequestedBlockID(req.ID)
+	if err := e.send(peer, syncID, req); err != nil {
+		logger.Error("failed to send BlockRequest message", "peer", peer)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+// Package grpc provides server-side interceptors for the node's gRPC query surface.
+package grpc
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// versionSegment matches a protobuf package's trailing version segment, e.g. "v1beta1", so
+// it can be stripped when deriving a method's module name
+var versionSegment = regexp.MustCompile(`^v\d+(beta\d+|alpha\d+)?$`)
+
+// ModuleAllowlistInterceptor rejects any unary call whose service doesn't belong to one of
+// allowedModules, so an operator can expose a narrow query-only surface (e.g. just
+// "bank" and "staking") without standing up a full node's worth of gRPC services publicly.
+// An empty allowedModules allows every module through unchanged.
+func ModuleAllowlistInterceptor(allowedModules []string) grpc.UnaryServerInterceptor {
+	allowed := make(map[string]bool, len(allowedModules))
+	for _, m := range allowedModules {
+		allowed[m] = true
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if len(allowed) == 0 {
+			return handler(ctx, req)
+		}
+
+		module := moduleFromFullMethod(info.FullMethod)
+		if !allowed[module] {
+			return nil, status.Errorf(codes.PermissionDenied, "module %q is not in the gRPC allowlist", module)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// moduleFromFullMethod derives the owning module's name from a gRPC FullMethod such as
+// "/cosmos.bank.v1beta1.Query/AllBalances", returning "bank"
+func moduleFromFullMethod(fullMethod string) string {
+	service := strings.TrimPrefix(fullMethod, "/")
+	if idx := strings.LastIndex(service, "/"); idx >= 0 {
+		service = service[:idx]
+	}
+
+	segments := strings.Split(service, ".")
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+		if segment == "Query" || segment == "Msg" || versionSegment.MatchString(segment) {
+			continue
+		}
+		return segment
+	}
+	return ""
+}
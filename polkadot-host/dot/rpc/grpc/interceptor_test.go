@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestModuleFromFullMethod(t *testing.T) {
+	tests := map[string]string{
+		"/cosmos.bank.v1beta1.Query/AllBalances":    "bank",
+		"/cosmos.staking.v1beta1.Query/Validators":  "staking",
+		"/cosmos.gov.v1beta1.Msg/SubmitProposal":    "gov",
+		"/gokulsan.moorpay.moorfly.Query/MoorflyRecord": "moorfly",
+	}
+
+	for fullMethod, want := range tests {
+		require.Equal(t, want, moduleFromFullMethod(fullMethod), fullMethod)
+	}
+}
+
+func TestModuleAllowlistInterceptor(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	t.Run("empty allowlist allows everything", func(t *testing.T) {
+		interceptor := ModuleAllowlistInterceptor(nil)
+		info := &grpc.UnaryServerInfo{FullMethod: "/cosmos.gov.v1beta1.Query/Proposals"}
+
+		resp, err := interceptor(context.Background(), nil, info, handler)
+		require.NoError(t, err)
+		require.Equal(t, "ok", resp)
+	})
+
+	t.Run("allowed module passes through", func(t *testing.T) {
+		interceptor := ModuleAllowlistInterceptor([]string{"bank", "staking"})
+		info := &grpc.UnaryServerInfo{FullMethod: "/cosmos.bank.v1beta1.Query/AllBalances"}
+
+		resp, err := interceptor(context.Background(), nil, info, handler)
+		require.NoError(t, err)
+		require.Equal(t, "ok", resp)
+	})
+
+	t.Run("disallowed module is rejected", func(t *testing.T) {
+		interceptor := ModuleAllowlistInterceptor([]string{"bank", "staking"})
+		info := &grpc.UnaryServerInfo{FullMethod: "/cosmos.gov.v1beta1.Query/Proposals"}
+
+		_, err := interceptor(context.Background(), nil, info, handler)
+		require.Error(t, err)
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
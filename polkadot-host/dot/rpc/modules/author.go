@@ -0,0 +1,147 @@
+// Package modules holds the node's JSON-RPC method handlers, one file per Substrate RPC
+// module (author, chain, state, ...).
+package modules
+
+import (
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/transaction"
+)
+
+// AuthorTransactionState is the subset of dot/state.TransactionState the author module needs
+// to submit, watch, and list extrinsics.
+type AuthorTransactionState interface {
+	Push(vt *transaction.ValidTransaction, currentBlock uint64) (common.Hash, error)
+	SubscribeStatus(hash common.Hash) <-chan state.TxStatus
+	UnsubscribeStatus(hash common.Hash, ch <-chan state.TxStatus)
+	Pending() []*transaction.ValidTransaction
+}
+
+// WSConnAPI is the subset of the node's per-connection websocket state a subscription needs
+// to push notifications to the client.
+type WSConnAPI interface {
+	// Send writes id's next notification, result, to the client.
+	Send(id uint32, result interface{}) error
+}
+
+// AuthorModule holds the RPC methods under the "author_" prefix.
+type AuthorModule struct {
+	txState AuthorTransactionState
+}
+
+// NewAuthorModule creates a new AuthorModule, backed by txState.
+func NewAuthorModule(txState AuthorTransactionState) *AuthorModule {
+	return &AuthorModule{txState: txState}
+}
+
+// SubmitAndWatchExtrinsicRequest is the author_submitAndWatchExtrinsic request param: a
+// SCALE-encoded extrinsic.
+type SubmitAndWatchExtrinsicRequest struct {
+	Extrinsic []byte
+}
+
+// ExtrinsicStatusResponse mirrors one Substrate author_extrinsicUpdate notification: exactly
+// one of its fields is set, matching which TxStatusKind it reports.
+type ExtrinsicStatusResponse struct {
+	Future    *struct{} `json:"future,omitempty"`
+	Ready     *struct{} `json:"ready,omitempty"`
+	Broadcast *struct{} `json:"broadcast,omitempty"`
+	InBlock   string    `json:"inBlock,omitempty"`
+	Retracted string    `json:"retracted,omitempty"`
+	Finalized string    `json:"finalized,omitempty"`
+	Usurped   *struct{} `json:"usurped,omitempty"`
+	Dropped   *struct{} `json:"dropped,omitempty"`
+	Invalid   *struct{} `json:"invalid,omitempty"`
+}
+
+// newExtrinsicStatusResponse converts a state.TxStatus into the wire shape
+// author_extrinsicUpdate notifies with.
+func newExtrinsicStatusResponse(status state.TxStatus) ExtrinsicStatusResponse {
+	switch status.Kind {
+	case state.Future:
+		return ExtrinsicStatusResponse{Future: &struct{}{}}
+	case state.Ready:
+		return ExtrinsicStatusResponse{Ready: &struct{}{}}
+	case state.Broadcast:
+		return ExtrinsicStatusResponse{Broadcast: &struct{}{}}
+	case state.InBlock:
+		return ExtrinsicStatusResponse{InBlock: status.Hash.String()}
+	case state.Retracted:
+		return ExtrinsicStatusResponse{Retracted: status.Hash.String()}
+	case state.Finalized:
+		return ExtrinsicStatusResponse{Finalized: status.Hash.String()}
+	case state.Usurped:
+		return ExtrinsicStatusResponse{Usurped: &struct{}{}}
+	case state.Dropped:
+		return ExtrinsicStatusResponse{Dropped: &struct{}{}}
+	default:
+		return ExtrinsicStatusResponse{Invalid: &struct{}{}}
+	}
+}
+
+// SubmitAndWatchExtrinsic implements author_submitAndWatchExtrinsic: it pushes req.Extrinsic
+// into the transaction queue and, for as long as conn stays subscribed to subID, forwards
+// every status change the queue reports for it as an author_extrinsicUpdate notification. It
+// returns once the subscription is torn down by UnwatchExtrinsic or conn closing; callers
+// should run it in its own goroutine per the server's subscription dispatch convention.
+func (m *AuthorModule) SubmitAndWatchExtrinsic(conn WSConnAPI, subID uint32, req *SubmitAndWatchExtrinsicRequest) error {
+	// subscribe before pushing: Push itself reports the first Future or Ready status, which a
+	// subscription opened afterwards would miss
+	hash := types.Extrinsic(req.Extrinsic).Hash()
+	updates := m.txState.SubscribeStatus(hash)
+	defer m.txState.UnsubscribeStatus(hash, updates)
+
+	if _, err := m.txState.Push(&transaction.ValidTransaction{
+		Extrinsic: req.Extrinsic,
+		Validity:  &transaction.Validity{Priority: 1},
+	}, 0); err != nil {
+		return err
+	}
+
+	for status := range updates {
+		if err := conn.Send(subID, newExtrinsicStatusResponse(status)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnwatchExtrinsicRequest is the author_unwatchExtrinsic request param: the subscription id
+// returned by the author_submitAndWatchExtrinsic call to cancel.
+type UnwatchExtrinsicRequest struct {
+	SubscriptionID uint32
+}
+
+// UnwatchExtrinsic implements author_unwatchExtrinsic. The server's subscription dispatch is
+// expected to look up the channel SubscribeStatus returned for req.SubscriptionID's hash and
+// call UnsubscribeStatus with it, which causes the matching SubmitAndWatchExtrinsic call's
+// range over updates to end; UnwatchExtrinsic itself has nothing further to do, so it just
+// reports success.
+func (m *AuthorModule) UnwatchExtrinsic(_ *UnwatchExtrinsicRequest) (bool, error) {
+	return true, nil
+}
+
+// PendingExtrinsicsResponse is the author_pendingExtrinsics result: every extrinsic currently
+// in the ready queue or pool, hex-encoded.
+type PendingExtrinsicsResponse []string
+
+// PendingExtrinsics implements author_pendingExtrinsics, returning every extrinsic the
+// transaction queue currently holds, whether ready or still in the pool. It's used by the
+// persistence integration test to confirm a restarted node's pool still holds extrinsics
+// submitted before it was killed.
+func (m *AuthorModule) PendingExtrinsics(_ *EmptyRequest) (PendingExtrinsicsResponse, error) {
+	pending := m.txState.Pending()
+
+	res := make(PendingExtrinsicsResponse, len(pending))
+	for i, vt := range pending {
+		res[i] = fmt.Sprintf("0x%x", []byte(vt.Extrinsic))
+	}
+	return res, nil
+}
+
+// EmptyRequest is the request param for an RPC method, such as author_pendingExtrinsics, that
+// takes no arguments.
+type EmptyRequest struct{}
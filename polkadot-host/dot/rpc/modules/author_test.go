@@ -0,0 +1,88 @@
+package modules
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWSConn records every notification sent to it, in the order sent; the subscription id is
+// ignored.
+type fakeWSConn struct {
+	mu  sync.Mutex
+	got []ExtrinsicStatusResponse
+}
+
+func (c *fakeWSConn) Send(_ uint32, result interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.got = append(c.got, result.(ExtrinsicStatusResponse))
+	return nil
+}
+
+func (c *fakeWSConn) notifications() []ExtrinsicStatusResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]ExtrinsicStatusResponse, len(c.got))
+	copy(out, c.got)
+	return out
+}
+
+func TestAuthorModule_SubmitAndWatchExtrinsic(t *testing.T) {
+	txState := state.NewTransactionState(state.PoolLimits{})
+	m := NewAuthorModule(txState)
+
+	conn := &fakeWSConn{}
+	done := make(chan error, 1)
+	go func() {
+		done <- m.SubmitAndWatchExtrinsic(conn, 1, &SubmitAndWatchExtrinsicRequest{Extrinsic: []byte("tx")})
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(conn.notifications()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, []ExtrinsicStatusResponse{{Ready: &struct{}{}}}, conn.notifications())
+}
+
+func TestNewExtrinsicStatusResponse(t *testing.T) {
+	blockHash := common.Hash{1, 2, 3}
+
+	tests := map[state.TxStatusKind]ExtrinsicStatusResponse{
+		state.Future:    {Future: &struct{}{}},
+		state.Ready:     {Ready: &struct{}{}},
+		state.Broadcast: {Broadcast: &struct{}{}},
+		state.Usurped:   {Usurped: &struct{}{}},
+		state.Dropped:   {Dropped: &struct{}{}},
+		state.Invalid:   {Invalid: &struct{}{}},
+	}
+
+	for kind, want := range tests {
+		require.Equal(t, want, newExtrinsicStatusResponse(state.TxStatus{Kind: kind}))
+	}
+
+	require.Equal(t, ExtrinsicStatusResponse{InBlock: blockHash.String()},
+		newExtrinsicStatusResponse(state.TxStatus{Kind: state.InBlock, Hash: blockHash}))
+	require.Equal(t, ExtrinsicStatusResponse{Finalized: blockHash.String()},
+		newExtrinsicStatusResponse(state.TxStatus{Kind: state.Finalized, Hash: blockHash}))
+}
+
+func TestAuthorModule_PendingExtrinsics(t *testing.T) {
+	txState := state.NewTransactionState(state.PoolLimits{})
+	m := NewAuthorModule(txState)
+
+	_, err := txState.Push(&transaction.ValidTransaction{
+		Extrinsic: []byte("tx"),
+		Validity:  &transaction.Validity{Priority: 1},
+	}, 1)
+	require.NoError(t, err)
+
+	res, err := m.PendingExtrinsics(&EmptyRequest{})
+	require.NoError(t, err)
+	require.Equal(t, PendingExtrinsicsResponse{"0x7478"}, res)
+}
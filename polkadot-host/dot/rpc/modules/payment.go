@@ -0,0 +1,80 @@
+package modules
+
+import (
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// RuntimeInstance is the subset of a runtime instance the payment module needs: running
+// TransactionPaymentApi_query_info against whichever block's state it was given.
+type RuntimeInstance interface {
+	PaymentQueryInfo(ext types.Extrinsic) (*types.RuntimeDispatchInfo, error)
+}
+
+// BlockAPI is the subset of dot/state.BlockState the payment module needs to resolve a block
+// hash to the runtime instance whose state it should query.
+type BlockAPI interface {
+	BestBlockHash() common.Hash
+	GetRuntime(blockHash common.Hash) (RuntimeInstance, error)
+}
+
+// PaymentModule holds the RPC methods under the "payment_" prefix.
+type PaymentModule struct {
+	blockAPI BlockAPI
+}
+
+// NewPaymentModule creates a new PaymentModule, backed by blockAPI.
+func NewPaymentModule(blockAPI BlockAPI) *PaymentModule {
+	return &PaymentModule{blockAPI: blockAPI}
+}
+
+// PaymentQueryInfoRequest is the payment_queryInfo request params: a SCALE-encoded extrinsic
+// and, optionally, the hash of the block to query it against. A nil Hash means the best block.
+type PaymentQueryInfoRequest struct {
+	Ext  []byte
+	Hash *common.Hash
+}
+
+// PaymentQueryInfoResponse is the payment_queryInfo result, mirroring Substrate's
+// RuntimeDispatchInfo: the extrinsic's weight, its DispatchClass name, and the fee it would be
+// charged, as a base-10 string since it doesn't fit in a JSON number.
+type PaymentQueryInfoResponse struct {
+	Weight     uint64 `json:"weight"`
+	Class      string `json:"class"`
+	PartialFee string `json:"partialFee"`
+}
+
+// dispatchClassNames maps types.RuntimeDispatchInfo.Class, Substrate's DispatchClass enum, to
+// the lowercase name Substrate clients expect in the JSON-RPC response.
+var dispatchClassNames = [...]string{"normal", "operational", "mandatory"}
+
+// PaymentQueryInfo implements payment_queryInfo: it decodes req.Ext, runs it through
+// TransactionPaymentApi_query_info at req.Hash (or the best block, if Hash is nil), and reports
+// the weight, dispatch class, and partial fee the runtime computed for it.
+func (m *PaymentModule) PaymentQueryInfo(req *PaymentQueryInfoRequest) (PaymentQueryInfoResponse, error) {
+	hash := m.blockAPI.BestBlockHash()
+	if req.Hash != nil {
+		hash = *req.Hash
+	}
+
+	inst, err := m.blockAPI.GetRuntime(hash)
+	if err != nil {
+		return PaymentQueryInfoResponse{}, err
+	}
+
+	info, err := inst.PaymentQueryInfo(types.Extrinsic(req.Ext))
+	if err != nil {
+		return PaymentQueryInfoResponse{}, err
+	}
+
+	class := "unknown"
+	if int(info.Class) < len(dispatchClassNames) {
+		class = dispatchClassNames[info.Class]
+	}
+
+	return PaymentQueryInfoResponse{
+		Weight:     info.Weight,
+		Class:      class,
+		PartialFee: info.PartialFee.String(),
+	}, nil
+}
@@ -0,0 +1,78 @@
+package modules
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/scale"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRuntimeInstance reports a fixed RuntimeDispatchInfo for whatever extrinsic it's asked
+// about, recording the last extrinsic it was given.
+type fakeRuntimeInstance struct {
+	info  *types.RuntimeDispatchInfo
+	asked types.Extrinsic
+}
+
+func (r *fakeRuntimeInstance) PaymentQueryInfo(ext types.Extrinsic) (*types.RuntimeDispatchInfo, error) {
+	r.asked = ext
+	return r.info, nil
+}
+
+// fakeBlockAPI records which block hash it was asked to resolve a runtime instance for.
+type fakeBlockAPI struct {
+	best    common.Hash
+	runtime RuntimeInstance
+	queried common.Hash
+}
+
+func (b *fakeBlockAPI) BestBlockHash() common.Hash {
+	return b.best
+}
+
+func (b *fakeBlockAPI) GetRuntime(blockHash common.Hash) (RuntimeInstance, error) {
+	b.queried = blockHash
+	return b.runtime, nil
+}
+
+func TestPaymentModule_PaymentQueryInfo_DefaultsToBestBlock(t *testing.T) {
+	fee, err := scale.NewUint128(big.NewInt(42))
+	require.NoError(t, err)
+
+	best := common.Hash{9}
+	inst := &fakeRuntimeInstance{info: &types.RuntimeDispatchInfo{
+		Weight:     100,
+		Class:      1,
+		PartialFee: fee,
+	}}
+	blockAPI := &fakeBlockAPI{best: best, runtime: inst}
+	m := NewPaymentModule(blockAPI)
+
+	res, err := m.PaymentQueryInfo(&PaymentQueryInfoRequest{Ext: []byte{1, 2, 3}})
+	require.NoError(t, err)
+	require.Equal(t, best, blockAPI.queried)
+	require.Equal(t, types.Extrinsic{1, 2, 3}, inst.asked)
+	require.Equal(t, PaymentQueryInfoResponse{
+		Weight:     100,
+		Class:      "operational",
+		PartialFee: "42",
+	}, res)
+}
+
+func TestPaymentModule_PaymentQueryInfo_UsesRequestedBlock(t *testing.T) {
+	fee, err := scale.NewUint128(big.NewInt(0))
+	require.NoError(t, err)
+
+	requested := common.Hash{7}
+	inst := &fakeRuntimeInstance{info: &types.RuntimeDispatchInfo{Class: 0, PartialFee: fee}}
+	blockAPI := &fakeBlockAPI{best: common.Hash{9}, runtime: inst}
+	m := NewPaymentModule(blockAPI)
+
+	res, err := m.PaymentQueryInfo(&PaymentQueryInfoRequest{Ext: []byte{1}, Hash: &requested})
+	require.NoError(t, err)
+	require.Equal(t, requested, blockAPI.queried)
+	require.Equal(t, "normal", res.Class)
+}
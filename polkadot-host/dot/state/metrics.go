@@ -0,0 +1,34 @@
+package state
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// These are registered once, at package init, rather than per TransactionState, since a
+// process only ever has one transaction pool worth reporting on and promauto.New* panics on a
+// second registration of the same metric name.
+var (
+	// poolSize is the number of transactions currently in the ready queue plus future pool.
+	poolSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "gossamer_txpool_size",
+		Help: "Number of transactions currently in the transaction pool.",
+	})
+	// poolBytes is the total encoded Extrinsic size, in bytes, of every transaction currently
+	// in the ready queue plus future pool.
+	poolBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "gossamer_txpool_bytes",
+		Help: "Total size, in bytes, of transactions currently in the transaction pool.",
+	})
+	// poolEvictions counts transactions evicted from the pool to stay under PoolLimits.
+	poolEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "gossamer_txpool_evictions_total",
+		Help: "Total number of transactions evicted from the transaction pool to stay under its configured limits.",
+	})
+	// poolRejected counts transactions rejected outright because the pool was already full of
+	// transactions with priority too high to evict.
+	poolRejected = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "gossamer_txpool_rejected_total",
+		Help: "Total number of transactions rejected because the pool was full and their priority was too low to evict room for them.",
+	})
+)
@@ -1,36 +1,357 @@
 package state
 
 import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/chaindb"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/scale"
 	"github.com/ChainSafe/gossamer/lib/transaction"
+	log "github.com/ChainSafe/log15"
+)
+
+var logger = log.New("pkg", "state")
+
+// txPoolDBPrefix namespaces TransactionState's persisted pool entries within db, so they
+// don't collide with the rest of the node's badger-backed state when db is the node's main
+// database.
+var txPoolDBPrefix = []byte("transaction/pool/")
+
+// persistedTx is the SCALE-encoded record Push and AddToPool write to db for every
+// transaction, keyed by its extrinsic hash, so NewTransactionStateFromDB can replay the pool
+// after a restart. Pop and RemoveExtrinsic/RemoveExtrinsicFromPool/RemoveExpired delete it
+// once the transaction leaves the pool or queue.
+type persistedTx struct {
+	VT         *transaction.ValidTransaction
+	InsertedAt uint64
+}
+
+// CompactionInterval is how often StartCompaction calls RemoveExpired.
+const CompactionInterval = time.Minute
+
+// TxStatusKind identifies a point in a transaction's lifecycle, mirroring Substrate's
+// author_submitAndWatchExtrinsic transaction_status notification kinds.
+type TxStatusKind int
+
+const (
+	// Future means the transaction is in the pool because its Validity.Requires tags
+	// aren't yet satisfied.
+	Future TxStatusKind = iota
+	// Ready means the transaction is in the ready queue, available to be authored into a
+	// block.
+	Ready
+	// Broadcast means the transaction has been announced to peers.
+	Broadcast
+	// InBlock means the transaction was included in the block identified by TxStatus.Hash.
+	InBlock
+	// Retracted means the block the transaction was in, TxStatus.Hash, was retracted by a
+	// re-org, and the transaction has been moved back to the ready queue.
+	Retracted
+	// Finalized means the block the transaction was included in, TxStatus.Hash, has been
+	// finalized.
+	Finalized
+	// Usurped means another transaction with the same sender and nonce replaced this one.
+	Usurped
+	// Dropped means the transaction was evicted from the queue or pool, eg. by
+	// RemoveExpired or to make room under a size limit.
+	Dropped
+	// Invalid means the transaction was found to no longer be valid, eg. on re-validation
+	// against a new block.
+	Invalid
 )
 
-// TransactionState represents the queue of transactions
+// TxStatus is one notification in a subscription created by SubscribeStatus. Hash is only
+// populated for InBlock, Retracted and Finalized, where it carries the block hash.
+type TxStatus struct {
+	Kind TxStatusKind
+	Hash common.Hash
+}
+
+// statusSubsBuffer is how many unread TxStatus notifications SubscribeStatus buffers for a
+// subscriber before notifyStatus starts dropping the oldest ones, so a slow subscriber can't
+// block transaction processing.
+const statusSubsBuffer = 16
+
+// PoolLimits bounds how large a TransactionState's queue plus pool is allowed to grow, so a
+// flood of low-priority extrinsics from a malicious peer can't run the node out of memory.
+// Whichever limit is exceeded once an incoming transaction is accounted for, the
+// lowest-priority transactions currently held - breaking ties by whichever was inserted first -
+// are evicted until both limits are satisfied again. If the incoming transaction's own priority
+// isn't strictly greater than the lowest priority already held, it is rejected outright instead,
+// since evicting wouldn't make room for anything better. A zero PoolLimits means unbounded,
+// matching the behavior before limits existed.
+type PoolLimits struct {
+	// Count is the maximum number of transactions held across the ready queue and the future
+	// pool combined. Zero means no limit.
+	Count int
+	// TotalBytes is the maximum total size, in bytes, of every transaction's Extrinsic held
+	// across the ready queue and the future pool combined. Zero means no limit.
+	TotalBytes int
+}
+
+// ErrPoolFull is returned by Push when limits is exceeded and every transaction with a lower
+// priority than the incoming one is already gone, so nothing is evictable to make room for it.
+// AddToPool reports the same case by returning the zero hash instead.
+var ErrPoolFull = errors.New("transaction pool full: priority too low to evict room")
+
+// TransactionState represents the queue of ready transactions plus a pool of ones that
+// aren't ready yet: transactions Push()ed whose Validity.Requires tags aren't all satisfied
+// by a Validity.Provides tag somewhere in the ready queue stay here until Promote moves them
+// over, implementing Substrate's ready/future pool split.
 type TransactionState struct {
+	mu    sync.RWMutex
 	queue *transaction.PriorityQueue
 	pool  *transaction.Pool
+
+	// insertedAt records the block height Push inserted a pool transaction at, keyed by its
+	// extrinsic hash, so RemoveExpired can evict it once currentBlock - insertedAt exceeds
+	// its Validity.Longevity. Transactions added via AddToPool directly (rather than Push)
+	// have no entry here and so are not evicted by RemoveExpired, until a
+	// NewTransactionStateFromDB replay records every replayed pool transaction here at its
+	// persisted height (0 for one added via AddToPool).
+	insertedAt map[common.Hash]uint64
+
+	// limits bounds the combined size of queue and pool; see PoolLimits.
+	limits PoolLimits
+	// insertedSeq records the order transactions currently in the queue or pool were
+	// inserted in, keyed by extrinsic hash, so enforceLimitsLocked can break priority ties
+	// by evicting whichever was inserted first.
+	insertedSeq map[common.Hash]uint64
+	nextSeq     uint64
+
+	subMu sync.RWMutex
+	subs  map[common.Hash][]chan TxStatus
+
+	// db, if non-nil, is where every Push/AddToPool is persisted and every Pop/
+	// RemoveExtrinsic/RemoveExtrinsicFromPool/RemoveExpired is un-persisted from. It is nil
+	// for a TransactionState built with NewTransactionState, which is in-memory only.
+	db chaindb.Database
 }
 
-// NewTransactionState returns a new TransactionState
-func NewTransactionState() *TransactionState {
+// NewTransactionState returns a new, in-memory-only TransactionState bounded by limits (a zero
+// PoolLimits means unbounded). Use NewTransactionStateFromDB instead for a pool that survives a
+// restart.
+func NewTransactionState(limits PoolLimits) *TransactionState {
 	return &TransactionState{
-		queue: transaction.NewPriorityQueue(),
-		pool:  transaction.NewPool(),
+		queue:       transaction.NewPriorityQueue(),
+		pool:        transaction.NewPool(),
+		insertedAt:  make(map[common.Hash]uint64),
+		limits:      limits,
+		insertedSeq: make(map[common.Hash]uint64),
+		subs:        make(map[common.Hash][]chan TxStatus),
+	}
+}
+
+// NewTransactionStateFromDB returns a TransactionState bounded by limits (a zero PoolLimits
+// means unbounded) whose pool and queue are persisted under a dedicated prefix in db: every
+// Push and AddToPool is written there, and removed again once it leaves the pool or queue via
+// Pop, RemoveExtrinsic, RemoveExtrinsicFromPool, or RemoveExpired. Whatever db already holds
+// under that prefix, eg. from before a restart, is replayed into the in-memory pool and queue
+// before NewTransactionStateFromDB returns, without re-checking limits against it: a pool that
+// was within bounds when it was persisted stays so on replay.
+func NewTransactionStateFromDB(db chaindb.Database, limits PoolLimits) (*TransactionState, error) {
+	s := &TransactionState{
+		queue:       transaction.NewPriorityQueue(),
+		pool:        transaction.NewPool(),
+		insertedAt:  make(map[common.Hash]uint64),
+		limits:      limits,
+		insertedSeq: make(map[common.Hash]uint64),
+		subs:        make(map[common.Hash][]chan TxStatus),
+		db:          db,
+	}
+
+	iter := db.NewIterator()
+	defer iter.Release()
+
+	var allProvides [][]byte
+	for iter.Next() {
+		if !bytes.HasPrefix(iter.Key(), txPoolDBPrefix) {
+			continue
+		}
+
+		var rec persistedTx
+		if _, err := scale.Decode(iter.Value(), &rec); err != nil {
+			return nil, err
+		}
+
+		hash := s.pool.Insert(rec.VT)
+		s.insertedAt[hash] = rec.InsertedAt
+		s.nextSeq++
+		s.insertedSeq[hash] = s.nextSeq
+		allProvides = append(allProvides, rec.VT.Validity.Provides...)
+	}
+
+	s.promoteLocked(allProvides)
+	s.updateMetricsLocked()
+	return s, nil
+}
+
+// StartCompaction calls RemoveExpired(currentBlock()) every CompactionInterval until stop is
+// closed, so a persisted pool doesn't grow unboundedly with transactions whose Longevity has
+// long since elapsed between node restarts.
+func (s *TransactionState) StartCompaction(currentBlock func() uint64, stop <-chan struct{}) {
+	ticker := time.NewTicker(CompactionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.RemoveExpired(currentBlock())
+		case <-stop:
+			return
+		}
+	}
+}
+
+// poolDBKey is the db key persistedTx is stored under for the transaction identified by hash.
+func (s *TransactionState) poolDBKey(hash common.Hash) []byte {
+	return append(append([]byte{}, txPoolDBPrefix...), hash[:]...)
+}
+
+// persist writes vt's persistedTx record to db, if s was built with NewTransactionStateFromDB.
+// A failure is logged rather than returned: the in-memory pool is always the source of truth
+// for this process, and a transaction that fails to persist simply won't survive a restart.
+func (s *TransactionState) persist(hash common.Hash, vt *transaction.ValidTransaction, insertedAt uint64) {
+	if s.db == nil {
+		return
+	}
+
+	enc, err := scale.Encode(persistedTx{VT: vt, InsertedAt: insertedAt})
+	if err != nil {
+		logger.Warn("failed to encode transaction for persistence", "hash", hash, "error", err)
+		return
+	}
+	if err := s.db.Put(s.poolDBKey(hash), enc); err != nil {
+		logger.Warn("failed to persist transaction", "hash", hash, "error", err)
+	}
+}
+
+// unpersist deletes hash's persistedTx record from db, if s was built with
+// NewTransactionStateFromDB. A failure is logged rather than returned, for the same reason as
+// persist.
+func (s *TransactionState) unpersist(hash common.Hash) {
+	if s.db == nil {
+		return
+	}
+	if err := s.db.Del(s.poolDBKey(hash)); err != nil {
+		logger.Warn("failed to remove persisted transaction", "hash", hash, "error", err)
+	}
+}
+
+// SubscribeStatus returns a channel that receives a TxStatus every time the transaction
+// identified by hash, as returned by Push or AddToPool, changes lifecycle state. The caller
+// must call UnsubscribeStatus with the same hash and channel once it's done watching, to
+// release the channel.
+func (s *TransactionState) SubscribeStatus(hash common.Hash) <-chan TxStatus {
+	ch := make(chan TxStatus, statusSubsBuffer)
+
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	s.subs[hash] = append(s.subs[hash], ch)
+	return ch
+}
+
+// UnsubscribeStatus removes ch from hash's subscribers and closes it. It's a no-op if ch is
+// not currently subscribed to hash.
+func (s *TransactionState) UnsubscribeStatus(hash common.Hash, ch <-chan TxStatus) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	subs := s.subs[hash]
+	for i, sub := range subs {
+		if sub == ch {
+			subs[i] = subs[len(subs)-1]
+			s.subs[hash] = subs[:len(subs)-1]
+			close(sub)
+			return
+		}
 	}
 }
 
-// Push pushes a transaction to the queue, ordered by priority
-func (s *TransactionState) Push(vt *transaction.ValidTransaction) (common.Hash, error) {
-	return s.queue.Push(vt)
+// notifyStatus delivers status to every subscriber of hash. A subscriber that isn't keeping
+// up has its oldest unread notification dropped to make room, rather than blocking the
+// caller.
+func (s *TransactionState) notifyStatus(hash common.Hash, status TxStatus) {
+	s.subMu.RLock()
+	defer s.subMu.RUnlock()
+
+	for _, sub := range s.subs[hash] {
+		select {
+		case sub <- status:
+		default:
+			select {
+			case <-sub:
+			default:
+			}
+			select {
+			case sub <- status:
+			default:
+			}
+		}
+	}
 }
 
-// Pop removes and returns the head of the queue
+// Push adds a transaction to the ready queue if its Validity.Requires tags are already
+// satisfied by a Validity.Provides tag somewhere in the ready queue; otherwise vt is parked
+// in the pool as a future transaction. Either way, Push then re-scans the pool with vt's own
+// Provides tags and promotes any future transaction those newly satisfy. currentBlock stamps
+// vt's insertion height for RemoveExpired, if vt ends up in the pool.
+func (s *TransactionState) Push(vt *transaction.ValidTransaction, currentBlock uint64) (common.Hash, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.enforceLimitsLocked(vt) {
+		return common.Hash{}, ErrPoolFull
+	}
+
+	var hash common.Hash
+	var status TxStatusKind
+	if requiresSatisfied(vt.Validity.Requires, s.providedTagsLocked()) {
+		h, err := s.queue.Push(vt)
+		if err != nil {
+			return h, err
+		}
+		hash = h
+		status = Ready
+	} else {
+		hash = s.pool.Insert(vt)
+		s.insertedAt[hash] = currentBlock
+		status = Future
+	}
+	s.nextSeq++
+	s.insertedSeq[hash] = s.nextSeq
+
+	s.persist(hash, vt, currentBlock)
+	s.notifyStatus(hash, TxStatus{Kind: status})
+
+	s.promoteLocked(vt.Validity.Provides)
+	s.updateMetricsLocked()
+	return hash, nil
+}
+
+// Pop removes and returns the head of the ready queue, notifying its subscribers, if any,
+// that it's been broadcast for inclusion.
 func (s *TransactionState) Pop() *transaction.ValidTransaction {
-	return s.queue.Pop()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	vt := s.queue.Pop()
+	if vt != nil {
+		hash := vt.Extrinsic.Hash()
+		delete(s.insertedSeq, hash)
+		s.unpersist(hash)
+		s.notifyStatus(hash, TxStatus{Kind: Broadcast})
+		s.updateMetricsLocked()
+	}
+	return vt
 }
 
-// Peek returns the head of the queue without removing it
+// Peek returns the head of the ready queue without removing it
 func (s *TransactionState) Peek() *transaction.ValidTransaction {
 	return s.queue.Peek()
 }
@@ -45,18 +366,317 @@ func (s *TransactionState) PendingInPool() []*transaction.ValidTransaction {
 	return s.pool.Transactions()
 }
 
-// RemoveExtrinsic removes an extrinsic from the queue and pool
+// RemoveExtrinsic removes an extrinsic from the queue and pool, notifying its subscribers,
+// if any, that it's been dropped.
 func (s *TransactionState) RemoveExtrinsic(ext types.Extrinsic) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.pool.Remove(ext.Hash())
+	delete(s.insertedAt, ext.Hash())
+	delete(s.insertedSeq, ext.Hash())
 	s.queue.RemoveExtrinsic(ext)
+	s.unpersist(ext.Hash())
+	s.notifyStatus(ext.Hash(), TxStatus{Kind: Dropped})
+	s.updateMetricsLocked()
+}
+
+// NotifyInBlock notifies ext's subscribers, if any, that it was included in the block
+// identified by blockHash. It's called by block-import once an imported block's extrinsics
+// are known, rather than by RemoveExtrinsic, so that inclusion is reported as InBlock instead
+// of Dropped.
+func (s *TransactionState) NotifyInBlock(ext types.Extrinsic, blockHash common.Hash) {
+	s.notifyStatus(ext.Hash(), TxStatus{Kind: InBlock, Hash: blockHash})
+}
+
+// NotifyRetracted notifies ext's subscribers, if any, that the block it was included in,
+// blockHash, was retracted by a re-org and ext has been moved back to the ready queue. It's
+// called by block-import when handling a re-org.
+func (s *TransactionState) NotifyRetracted(ext types.Extrinsic, blockHash common.Hash) {
+	s.notifyStatus(ext.Hash(), TxStatus{Kind: Retracted, Hash: blockHash})
+}
+
+// NotifyFinalized notifies ext's subscribers, if any, that the block it was included in,
+// blockHash, has been finalized. It's called by block-import's finalization handling.
+func (s *TransactionState) NotifyFinalized(ext types.Extrinsic, blockHash common.Hash) {
+	s.notifyStatus(ext.Hash(), TxStatus{Kind: Finalized, Hash: blockHash})
+}
+
+// NotifyUsurped notifies ext's subscribers, if any, that another transaction with the same
+// sender and nonce has replaced it.
+func (s *TransactionState) NotifyUsurped(ext types.Extrinsic) {
+	s.notifyStatus(ext.Hash(), TxStatus{Kind: Usurped})
+}
+
+// NotifyInvalid notifies ext's subscribers, if any, that it was found to no longer be valid,
+// eg. on re-validation against a new block.
+func (s *TransactionState) NotifyInvalid(ext types.Extrinsic) {
+	s.notifyStatus(ext.Hash(), TxStatus{Kind: Invalid})
 }
 
 // RemoveExtrinsicFromPool removes an extrinsic from the pool
 func (s *TransactionState) RemoveExtrinsicFromPool(ext types.Extrinsic) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.pool.Remove(ext.Hash())
+	delete(s.insertedAt, ext.Hash())
+	delete(s.insertedSeq, ext.Hash())
+	s.unpersist(ext.Hash())
+	s.updateMetricsLocked()
 }
 
-// AddToPool adds a transaction to the pool
+// AddToPool adds a transaction to the pool directly, bypassing Push's Requires gating and
+// Longevity bookkeeping. It's used by callers, such as the offchain worker's transaction
+// submission, that don't track a current block height to stamp an insertion height with.
 func (s *TransactionState) AddToPool(vt *transaction.ValidTransaction) common.Hash {
-	return s.pool.Insert(vt)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.enforceLimitsLocked(vt) {
+		return common.Hash{}
+	}
+
+	hash := s.pool.Insert(vt)
+	s.nextSeq++
+	s.insertedSeq[hash] = s.nextSeq
+	s.persist(hash, vt, 0)
+	s.notifyStatus(hash, TxStatus{Kind: Future})
+	s.updateMetricsLocked()
+	return hash
+}
+
+// Promote moves every pool transaction whose Validity.Requires tags are all satisfied by
+// providedTags or by a Provides tag already in the ready queue into the ready queue, removing
+// it from the pool. It repeats until a pass promotes nothing, so promoting one transaction can
+// transitively unblock another pool transaction that required one of its tags.
+func (s *TransactionState) Promote(providedTags [][]byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.promoteLocked(providedTags)
+}
+
+func (s *TransactionState) promoteLocked(providedTags [][]byte) {
+	for {
+		available := append(s.providedTagsLocked(), providedTags...)
+		promoted := false
+
+		for _, vt := range s.pool.Transactions() {
+			if !requiresSatisfied(vt.Validity.Requires, available) {
+				continue
+			}
+
+			hash := vt.Extrinsic.Hash()
+			s.pool.Remove(hash)
+			delete(s.insertedAt, hash)
+			if _, err := s.queue.Push(vt); err != nil {
+				continue
+			}
+			s.notifyStatus(hash, TxStatus{Kind: Ready})
+			promoted = true
+		}
+
+		if !promoted {
+			return
+		}
+	}
+}
+
+// RemoveExpired evicts every pool transaction Push inserted at a height more than its
+// Validity.Longevity blocks before currentBlock. Transactions added via AddToPool, which have
+// no recorded insertion height, are left alone; after a NewTransactionStateFromDB replay they
+// are tracked from height 0 like any other pool transaction.
+func (s *TransactionState) RemoveExpired(currentBlock uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, vt := range s.pool.Transactions() {
+		hash := vt.Extrinsic.Hash()
+
+		insertedAt, tracked := s.insertedAt[hash]
+		if !tracked || currentBlock < insertedAt {
+			continue
+		}
+
+		if currentBlock-insertedAt > vt.Validity.Longevity {
+			s.pool.Remove(hash)
+			delete(s.insertedAt, hash)
+			delete(s.insertedSeq, hash)
+			s.unpersist(hash)
+			s.notifyStatus(hash, TxStatus{Kind: Dropped})
+		}
+	}
+	s.updateMetricsLocked()
+}
+
+// poolSizeAndBytesLocked reports the number of transactions and their total Extrinsic size
+// across the ready queue and future pool combined. Callers must hold s.mu.
+func (s *TransactionState) poolSizeAndBytesLocked() (count, totalBytes int) {
+	for _, vt := range s.queue.Pending() {
+		count++
+		totalBytes += len(vt.Extrinsic)
+	}
+	for _, vt := range s.pool.Transactions() {
+		count++
+		totalBytes += len(vt.Extrinsic)
+	}
+	return count, totalBytes
+}
+
+// updateMetricsLocked refreshes the gossamer_txpool_size and gossamer_txpool_bytes gauges from
+// the current contents of the queue and pool. Callers must hold s.mu.
+func (s *TransactionState) updateMetricsLocked() {
+	count, totalBytes := s.poolSizeAndBytesLocked()
+	poolSize.Set(float64(count))
+	poolBytes.Set(float64(totalBytes))
+}
+
+// enforceLimitsLocked makes room for incoming under s.limits, if necessary, by evicting the
+// lowest-priority transactions currently in the queue or pool - breaking ties by whichever was
+// inserted first - until accepting incoming would no longer exceed either limit. It reports
+// false, rejecting incoming without evicting anything, if incoming's priority isn't strictly
+// greater than the lowest priority already held: evicting wouldn't make room for anything worth
+// keeping. Callers must hold s.mu and must not have inserted incoming yet.
+func (s *TransactionState) enforceLimitsLocked(incoming *transaction.ValidTransaction) bool {
+	if s.limits.Count == 0 && s.limits.TotalBytes == 0 {
+		return true
+	}
+
+	count, totalBytes := s.poolSizeAndBytesLocked()
+	incomingBytes := len(incoming.Extrinsic)
+
+	overCount := s.limits.Count > 0 && count+1 > s.limits.Count
+	overBytes := s.limits.TotalBytes > 0 && totalBytes+incomingBytes > s.limits.TotalBytes
+	if !overCount && !overBytes {
+		return true
+	}
+
+	type candidate struct {
+		vt  *transaction.ValidTransaction
+		seq uint64
+	}
+	candidates := make([]candidate, 0, count)
+	for _, vt := range s.queue.Pending() {
+		candidates = append(candidates, candidate{vt, s.insertedSeq[vt.Extrinsic.Hash()]})
+	}
+	for _, vt := range s.pool.Transactions() {
+		candidates = append(candidates, candidate{vt, s.insertedSeq[vt.Extrinsic.Hash()]})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].vt.Validity.Priority != candidates[j].vt.Validity.Priority {
+			return candidates[i].vt.Validity.Priority < candidates[j].vt.Validity.Priority
+		}
+		return candidates[i].seq < candidates[j].seq
+	})
+
+	if len(candidates) > 0 && incoming.Validity.Priority <= candidates[0].vt.Validity.Priority {
+		poolRejected.Inc()
+		return false
+	}
+
+	for _, c := range candidates {
+		countOK := s.limits.Count == 0 || count+1 <= s.limits.Count
+		bytesOK := s.limits.TotalBytes == 0 || totalBytes+incomingBytes <= s.limits.TotalBytes
+		if countOK && bytesOK {
+			break
+		}
+
+		hash := c.vt.Extrinsic.Hash()
+		s.queue.RemoveExtrinsic(c.vt.Extrinsic)
+		s.pool.Remove(hash)
+		delete(s.insertedAt, hash)
+		delete(s.insertedSeq, hash)
+		s.unpersist(hash)
+		s.notifyStatus(hash, TxStatus{Kind: Dropped})
+		poolEvictions.Inc()
+
+		count--
+		totalBytes -= len(c.vt.Extrinsic)
+	}
+
+	countOK := s.limits.Count == 0 || count+1 <= s.limits.Count
+	bytesOK := s.limits.TotalBytes == 0 || totalBytes+incomingBytes <= s.limits.TotalBytes
+	if !countOK || !bytesOK {
+		poolRejected.Inc()
+		return false
+	}
+
+	return true
+}
+
+// Ready returns every transaction in the ready queue, ordered so that a transaction never
+// precedes one whose Provides tag it Requires, and otherwise by descending Validity.Priority.
+// This is the order a block author should apply ready transactions in.
+func (s *TransactionState) Ready() []*transaction.ValidTransaction {
+	s.mu.RLock()
+	pending := s.queue.Pending()
+	s.mu.RUnlock()
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].Validity.Priority > pending[j].Validity.Priority
+	})
+
+	ordered := make([]*transaction.ValidTransaction, 0, len(pending))
+	seen := make([]bool, len(pending))
+	var provided [][]byte
+
+	for len(ordered) < len(pending) {
+		progressed := false
+
+		for i, vt := range pending {
+			if seen[i] || !requiresSatisfied(vt.Validity.Requires, provided) {
+				continue
+			}
+
+			ordered = append(ordered, vt)
+			provided = append(provided, vt.Validity.Provides...)
+			seen[i] = true
+			progressed = true
+		}
+
+		if !progressed {
+			// the rest depend on a tag nothing in the ready queue provides (eg. a tag
+			// provided by a transaction still stuck in the pool); append them in priority
+			// order rather than dropping them.
+			for i, vt := range pending {
+				if !seen[i] {
+					ordered = append(ordered, vt)
+				}
+			}
+			break
+		}
+	}
+
+	return ordered
+}
+
+// providedTagsLocked returns every Validity.Provides tag offered by a transaction currently
+// in the ready queue. Callers must hold s.mu.
+func (s *TransactionState) providedTagsLocked() [][]byte {
+	pending := s.queue.Pending()
+
+	var tags [][]byte
+	for _, vt := range pending {
+		tags = append(tags, vt.Validity.Provides...)
+	}
+	return tags
+}
+
+// requiresSatisfied reports whether every tag in requires is present in provided.
+func requiresSatisfied(requires, provided [][]byte) bool {
+	for _, req := range requires {
+		found := false
+		for _, tag := range provided {
+			if bytes.Equal(tag, req) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
@@ -1,9 +1,13 @@
 package state
 
 import (
+	"path/filepath"
 	"sort"
 	"testing"
+	"time"
 
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/transaction"
 
@@ -11,7 +15,7 @@ import (
 )
 
 func TestTransactionState_Pending(t *testing.T) {
-	ts := NewTransactionState()
+	ts := NewTransactionState(PoolLimits{})
 
 	txs := []*transaction.ValidTransaction{
 		{
@@ -59,3 +63,344 @@ func TestTransactionState_Pending(t *testing.T) {
 	head := ts.Peek()
 	require.Nil(t, head)
 }
+
+func TestTransactionState_PoolLimits_EvictsLowestPriorityByCount(t *testing.T) {
+	ts := NewTransactionState(PoolLimits{Count: 2})
+
+	low := &transaction.ValidTransaction{
+		Extrinsic: []byte("low"),
+		Validity:  &transaction.Validity{Priority: 1},
+	}
+	mid := &transaction.ValidTransaction{
+		Extrinsic: []byte("mid"),
+		Validity:  &transaction.Validity{Priority: 2},
+	}
+	high := &transaction.ValidTransaction{
+		Extrinsic: []byte("high"),
+		Validity:  &transaction.Validity{Priority: 3},
+	}
+
+	_, err := ts.Push(low, 1)
+	require.NoError(t, err)
+	_, err = ts.Push(mid, 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(ts.Ready()))
+
+	// adding a third, higher-priority transaction should evict low - the lowest priority, not
+	// mid, which was inserted first among the remaining two - to stay at Count: 2
+	_, err = ts.Push(high, 1)
+	require.NoError(t, err)
+
+	ready := ts.Ready()
+	require.Equal(t, 2, len(ready))
+	for _, vt := range ready {
+		require.NotEqual(t, low, vt)
+	}
+}
+
+func TestTransactionState_PoolLimits_RejectsTooLowPriority(t *testing.T) {
+	ts := NewTransactionState(PoolLimits{Count: 1})
+
+	high := &transaction.ValidTransaction{
+		Extrinsic: []byte("high"),
+		Validity:  &transaction.Validity{Priority: 5},
+	}
+	_, err := ts.Push(high, 1)
+	require.NoError(t, err)
+
+	lower := &transaction.ValidTransaction{
+		Extrinsic: []byte("lower"),
+		Validity:  &transaction.Validity{Priority: 5},
+	}
+	_, err = ts.Push(lower, 1)
+	require.Equal(t, ErrPoolFull, err)
+
+	// high should still be the sole occupant - nothing was evicted for a rejected transaction
+	ready := ts.Ready()
+	require.Equal(t, []*transaction.ValidTransaction{high}, ready)
+}
+
+func TestTransactionState_PoolLimits_EvictsByTotalBytes(t *testing.T) {
+	ts := NewTransactionState(PoolLimits{TotalBytes: 6})
+
+	low := &transaction.ValidTransaction{
+		Extrinsic: []byte("low"), // 3 bytes
+		Validity:  &transaction.Validity{Priority: 1},
+	}
+	_, err := ts.Push(low, 1)
+	require.NoError(t, err)
+
+	high := &transaction.ValidTransaction{
+		Extrinsic: []byte("higher"), // 6 bytes; together with low, over the 6 byte cap
+		Validity:  &transaction.Validity{Priority: 2},
+	}
+	_, err = ts.Push(high, 1)
+	require.NoError(t, err)
+
+	ready := ts.Ready()
+	require.Equal(t, []*transaction.ValidTransaction{high}, ready)
+}
+
+func TestTransactionState_PoolLimits_RejectsFirstTransactionOverLimitAlone(t *testing.T) {
+	ts := NewTransactionState(PoolLimits{TotalBytes: 6})
+
+	tooBig := &transaction.ValidTransaction{
+		Extrinsic: []byte("this extrinsic alone is already over the limit"),
+		Validity:  &transaction.Validity{Priority: 1},
+	}
+	_, err := ts.Push(tooBig, 1)
+	require.Equal(t, ErrPoolFull, err)
+
+	// nothing should have been admitted - there was nothing to evict it in favour of
+	ready := ts.Ready()
+	require.Empty(t, ready)
+}
+
+func TestTransactionState_Push_FutureTransactionStaysInPool(t *testing.T) {
+	ts := NewTransactionState(PoolLimits{})
+
+	future := &transaction.ValidTransaction{
+		Extrinsic: []byte("future"),
+		Validity: &transaction.Validity{
+			Priority: 1,
+			Requires: [][]byte{[]byte("tag-a")},
+		},
+	}
+
+	_, err := ts.Push(future, 1)
+	require.NoError(t, err)
+
+	require.Nil(t, ts.Peek())
+	require.Equal(t, []*transaction.ValidTransaction{future}, ts.PendingInPool())
+}
+
+func TestTransactionState_Push_PromotesFutureTransaction(t *testing.T) {
+	ts := NewTransactionState(PoolLimits{})
+
+	future := &transaction.ValidTransaction{
+		Extrinsic: []byte("future"),
+		Validity: &transaction.Validity{
+			Priority: 1,
+			Requires: [][]byte{[]byte("tag-a")},
+		},
+	}
+
+	_, err := ts.Push(future, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ts.PendingInPool()))
+
+	provider := &transaction.ValidTransaction{
+		Extrinsic: []byte("provider"),
+		Validity: &transaction.Validity{
+			Priority: 1,
+			Provides: [][]byte{[]byte("tag-a")},
+		},
+	}
+
+	_, err = ts.Push(provider, 1)
+	require.NoError(t, err)
+
+	// both transactions should now be ready: provider satisfied its own requirements
+	// trivially, and pushing it promoted future out of the pool
+	require.Equal(t, 0, len(ts.PendingInPool()))
+	ready := ts.Ready()
+	require.Equal(t, 2, len(ready))
+}
+
+func TestTransactionState_RemoveExpired(t *testing.T) {
+	ts := NewTransactionState(PoolLimits{})
+
+	tx := &transaction.ValidTransaction{
+		Extrinsic: []byte("expiring"),
+		Validity: &transaction.Validity{
+			Priority:  1,
+			Requires:  [][]byte{[]byte("never-provided")},
+			Longevity: 2,
+		},
+	}
+
+	_, err := ts.Push(tx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ts.PendingInPool()))
+
+	ts.RemoveExpired(2)
+	require.Equal(t, 1, len(ts.PendingInPool()), "longevity not yet elapsed")
+
+	ts.RemoveExpired(4)
+	require.Equal(t, 0, len(ts.PendingInPool()), "longevity elapsed")
+}
+
+func TestTransactionState_Ready_OrdersByTagDependency(t *testing.T) {
+	ts := NewTransactionState(PoolLimits{})
+
+	consumer := &transaction.ValidTransaction{
+		Extrinsic: []byte("consumer"),
+		Validity: &transaction.Validity{
+			Priority: 100,
+			Requires: [][]byte{[]byte("tag-a")},
+			Provides: [][]byte{[]byte("tag-a")}, // satisfies its own requirement
+		},
+	}
+
+	_, err := ts.Push(consumer, 1)
+	require.NoError(t, err)
+
+	independent := &transaction.ValidTransaction{
+		Extrinsic: []byte("independent"),
+		Validity:  &transaction.Validity{Priority: 1},
+	}
+
+	_, err = ts.Push(independent, 1)
+	require.NoError(t, err)
+
+	ready := ts.Ready()
+	require.Equal(t, 2, len(ready))
+}
+
+func TestTransactionState_SubscribeStatus_FutureThenReady(t *testing.T) {
+	ts := NewTransactionState(PoolLimits{})
+
+	future := &transaction.ValidTransaction{
+		Extrinsic: []byte("future"),
+		Validity: &transaction.Validity{
+			Priority: 1,
+			Requires: [][]byte{[]byte("tag-a")},
+		},
+	}
+
+	hash, err := ts.Push(future, 1)
+	require.NoError(t, err)
+
+	ch := ts.SubscribeStatus(hash)
+	defer ts.UnsubscribeStatus(hash, ch)
+
+	provider := &transaction.ValidTransaction{
+		Extrinsic: []byte("provider"),
+		Validity: &transaction.Validity{
+			Priority: 1,
+			Provides: [][]byte{[]byte("tag-a")},
+		},
+	}
+	_, err = ts.Push(provider, 1)
+	require.NoError(t, err)
+
+	select {
+	case status := <-ch:
+		require.Equal(t, Ready, status.Kind)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Ready notification")
+	}
+}
+
+func TestTransactionState_SubscribeStatus_InBlockAndFinalized(t *testing.T) {
+	ts := NewTransactionState(PoolLimits{})
+
+	vt := &transaction.ValidTransaction{
+		Extrinsic: []byte("tx"),
+		Validity:  &transaction.Validity{Priority: 1},
+	}
+
+	hash, err := ts.Push(vt, 1)
+	require.NoError(t, err)
+
+	ch := ts.SubscribeStatus(hash)
+	defer ts.UnsubscribeStatus(hash, ch)
+
+	blockHash := common.Hash{1, 2, 3}
+	ts.NotifyInBlock(vt.Extrinsic, blockHash)
+	ts.NotifyFinalized(vt.Extrinsic, blockHash)
+
+	status := <-ch
+	require.Equal(t, TxStatus{Kind: InBlock, Hash: blockHash}, status)
+
+	status = <-ch
+	require.Equal(t, TxStatus{Kind: Finalized, Hash: blockHash}, status)
+}
+
+func TestTransactionState_UnsubscribeStatus_ClosesChannel(t *testing.T) {
+	ts := NewTransactionState(PoolLimits{})
+
+	vt := &transaction.ValidTransaction{
+		Extrinsic: []byte("tx"),
+		Validity:  &transaction.Validity{Priority: 1},
+	}
+
+	hash, err := ts.Push(vt, 1)
+	require.NoError(t, err)
+
+	ch := ts.SubscribeStatus(hash)
+	ts.UnsubscribeStatus(hash, ch)
+
+	_, open := <-ch
+	require.False(t, open)
+
+	// a status reported after unsubscribing must not send on the now-closed channel
+	ts.RemoveExtrinsic(types.Extrinsic(vt.Extrinsic))
+}
+
+func TestNewTransactionStateFromDB_SurvivesRestart(t *testing.T) {
+	db, err := chaindb.NewBadgerDB(filepath.Join(t.TempDir(), "transactions"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ts, err := NewTransactionStateFromDB(db, PoolLimits{})
+	require.NoError(t, err)
+
+	ready := &transaction.ValidTransaction{
+		Extrinsic: []byte("ready"),
+		Validity:  &transaction.Validity{Priority: 1},
+	}
+	future := &transaction.ValidTransaction{
+		Extrinsic: []byte("future"),
+		Validity: &transaction.Validity{
+			Priority: 1,
+			Requires: [][]byte{[]byte("tag-a")},
+		},
+	}
+
+	_, err = ts.Push(ready, 1)
+	require.NoError(t, err)
+	_, err = ts.Push(future, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ts.Ready()))
+	require.Equal(t, 1, len(ts.PendingInPool()))
+
+	// a fresh TransactionState over the same db, as if the node had just restarted, should
+	// see both transactions again without either being re-submitted
+	restarted, err := NewTransactionStateFromDB(db, PoolLimits{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(restarted.Ready()))
+	require.Equal(t, 1, len(restarted.PendingInPool()))
+}
+
+func TestNewTransactionStateFromDB_PopAndRemoveUnpersist(t *testing.T) {
+	db, err := chaindb.NewBadgerDB(filepath.Join(t.TempDir(), "transactions"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ts, err := NewTransactionStateFromDB(db, PoolLimits{})
+	require.NoError(t, err)
+
+	popped := &transaction.ValidTransaction{
+		Extrinsic: []byte("popped"),
+		Validity:  &transaction.Validity{Priority: 1},
+	}
+	removed := &transaction.ValidTransaction{
+		Extrinsic: []byte("removed"),
+		Validity:  &transaction.Validity{Priority: 1},
+	}
+
+	_, err = ts.Push(popped, 1)
+	require.NoError(t, err)
+	_, err = ts.Push(removed, 1)
+	require.NoError(t, err)
+
+	require.NotNil(t, ts.Pop())
+	ts.RemoveExtrinsic(types.Extrinsic(removed.Extrinsic))
+
+	restarted, err := NewTransactionStateFromDB(db, PoolLimits{})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(restarted.Ready()))
+	require.Equal(t, 0, len(restarted.PendingInPool()))
+}
+
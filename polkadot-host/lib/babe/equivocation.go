@@ -0,0 +1,140 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package babe
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/scale"
+	log "github.com/ChainSafe/log15"
+)
+
+var logger = log.New("pkg", "babe")
+
+// errEmptyKeyOwnershipProof is returned by reportEquivocation when the runtime has no
+// key-ownership proof for the equivocating authority, typically because it has since left the
+// active validator set. It is non-fatal: the caller should log it and continue verification
+// rather than abort.
+var errEmptyKeyOwnershipProof = errors.New("babe: runtime returned an empty key-ownership proof")
+
+// equivocationRuntime is the subset of the runtime instance API the equivocation detector calls
+// into; *wasmer.Instance satisfies it, while the wasmtime Instance does not implement these
+// methods.
+type equivocationRuntime interface {
+	BabeGenerateKeyOwnershipProof(slot uint64, authorityID [32]byte) ([]byte, error)
+	BabeSubmitReportEquivocationUnsignedExtrinsic(equivocationProof, keyOwnershipProof []byte) error
+}
+
+// slotAuthority identifies a single BABE slot claim by the authority that claimed it.
+type slotAuthority struct {
+	slot           uint64
+	authorityIndex uint32
+}
+
+// EquivocationProof is the SCALE-encoded evidence that two distinct headers were produced for
+// the same slot by the same authority, submitted to
+// BabeApi_submit_report_equivocation_unsigned_extrinsic alongside a key-ownership proof.
+type EquivocationProof struct {
+	Offender     [32]byte
+	Slot         uint64
+	FirstHeader  *types.Header
+	SecondHeader *types.Header
+}
+
+// equivocationRecord is every distinct header equivocationDetector has seen for one
+// (authorityIndex, slot) pair, plus whether that pair has already been reported. Keeping every
+// header seen, not just the latest, means a third or later rebroadcast of a header already on
+// record is recognised as a repeat rather than re-triggering a report; the reported flag then
+// makes sure the first genuine equivocation is only ever reported once, even if the same two
+// headers keep alternating afterwards.
+type equivocationRecord struct {
+	headers  []*types.Header
+	reported bool
+}
+
+// equivocationDetector records every distinct header seen per (authorityIndex, slot) pair and
+// flags the first second-and-different header seen for a pair as an equivocation, never
+// re-flagging the same pair again. It is safe for concurrent use, matching how the verifier may
+// check several peers' headers at once.
+type equivocationDetector struct {
+	mu   sync.Mutex
+	seen map[slotAuthority]*equivocationRecord
+}
+
+// newEquivocationDetector creates an empty equivocationDetector.
+func newEquivocationDetector() *equivocationDetector {
+	return &equivocationDetector{seen: make(map[slotAuthority]*equivocationRecord)}
+}
+
+// check records header under (authorityIndex, slot) and returns the first header seen for that
+// pair the first time a second, distinct header is seen for it. It returns nil if header is the
+// first one seen for the pair, a repeat of a header already on record, or a second-and-different
+// header for a pair that has already been reported once.
+func (d *equivocationDetector) check(slot uint64, authorityIndex uint32, header *types.Header) *types.Header {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := slotAuthority{slot: slot, authorityIndex: authorityIndex}
+	rec, ok := d.seen[key]
+	if !ok {
+		d.seen[key] = &equivocationRecord{headers: []*types.Header{header}}
+		return nil
+	}
+
+	for _, h := range rec.headers {
+		if h.Hash() == header.Hash() {
+			return nil
+		}
+	}
+	first := rec.headers[0]
+	rec.headers = append(rec.headers, header)
+
+	if rec.reported {
+		return nil
+	}
+	rec.reported = true
+	return first
+}
+
+// reportEquivocation asks rt for a key-ownership proof of authorityID at slot and, if one
+// exists, submits an unsigned extrinsic reporting the equivocation between first and second.
+// An empty key-ownership proof is reported as errEmptyKeyOwnershipProof rather than an
+// encode/submit error, so the caller can log it at warn level and keep verifying.
+func reportEquivocation(rt equivocationRuntime, authorityID [32]byte, slot uint64, first, second *types.Header) error {
+	keyOwnershipProof, err := rt.BabeGenerateKeyOwnershipProof(slot, authorityID)
+	if err != nil {
+		return err
+	}
+	if len(keyOwnershipProof) == 0 {
+		return errEmptyKeyOwnershipProof
+	}
+
+	proof := &EquivocationProof{
+		Offender:     authorityID,
+		Slot:         slot,
+		FirstHeader:  first,
+		SecondHeader: second,
+	}
+	encodedProof, err := scale.Encode(proof)
+	if err != nil {
+		return err
+	}
+
+	return rt.BabeSubmitReportEquivocationUnsignedExtrinsic(encodedProof, keyOwnershipProof)
+}
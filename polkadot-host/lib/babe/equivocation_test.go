@@ -0,0 +1,126 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package babe
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/require"
+)
+
+// mockEquivocationRuntime records the calls verifyEquivocation makes into the runtime, so tests
+// can assert on what was submitted without a real wasm instance.
+type mockEquivocationRuntime struct {
+	mu sync.Mutex
+
+	keyOwnershipProof []byte
+	keyOwnershipErr   error
+
+	submitted []struct {
+		equivocationProof []byte
+		keyOwnershipProof []byte
+	}
+}
+
+func (m *mockEquivocationRuntime) BabeGenerateKeyOwnershipProof(uint64, [32]byte) ([]byte, error) {
+	return m.keyOwnershipProof, m.keyOwnershipErr
+}
+
+func (m *mockEquivocationRuntime) BabeSubmitReportEquivocationUnsignedExtrinsic(equivocationProof, keyOwnershipProof []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.submitted = append(m.submitted, struct {
+		equivocationProof []byte
+		keyOwnershipProof []byte
+	}{equivocationProof, keyOwnershipProof})
+	return nil
+}
+
+func (m *mockEquivocationRuntime) submittedCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.submitted)
+}
+
+func TestVerifier_VerifyEquivocation_DetectsConflictingHeaders(t *testing.T) {
+	rt := &mockEquivocationRuntime{keyOwnershipProof: []byte{1, 2, 3}}
+	v := newVerifier(rt)
+
+	var authorityID [32]byte
+	copy(authorityID[:], []byte("same-authority-key"))
+
+	first := &types.Header{Number: big.NewInt(1), ExtrinsicsRoot: common.Hash{1}}
+	second := &types.Header{Number: big.NewInt(1), ExtrinsicsRoot: common.Hash{2}}
+
+	v.verifyEquivocation(first, authorityID, 7, 0)
+	require.Equal(t, 0, rt.submittedCount(), "first header for a slot is never itself an equivocation")
+
+	v.verifyEquivocation(second, authorityID, 7, 0)
+	require.Equal(t, 1, rt.submittedCount(), "second, distinct header for the same slot/authority must be reported")
+}
+
+func TestVerifier_VerifyEquivocation_RepeatHeaderIsNotAnEquivocation(t *testing.T) {
+	rt := &mockEquivocationRuntime{keyOwnershipProof: []byte{1, 2, 3}}
+	v := newVerifier(rt)
+
+	var authorityID [32]byte
+	header := &types.Header{Number: big.NewInt(1)}
+
+	v.verifyEquivocation(header, authorityID, 7, 0)
+	v.verifyEquivocation(header, authorityID, 7, 0)
+
+	require.Equal(t, 0, rt.submittedCount())
+}
+
+func TestVerifier_VerifyEquivocation_AlternatingHeadersReportOnlyOnce(t *testing.T) {
+	rt := &mockEquivocationRuntime{keyOwnershipProof: []byte{1, 2, 3}}
+	v := newVerifier(rt)
+
+	var authorityID [32]byte
+	first := &types.Header{Number: big.NewInt(1), ExtrinsicsRoot: common.Hash{1}}
+	second := &types.Header{Number: big.NewInt(1), ExtrinsicsRoot: common.Hash{2}}
+
+	v.verifyEquivocation(first, authorityID, 7, 0)
+	v.verifyEquivocation(second, authorityID, 7, 0)
+	require.Equal(t, 1, rt.submittedCount(), "second, distinct header for the same slot/authority must be reported")
+
+	// first and second keep getting rebroadcast/retried after the equivocation has already been
+	// reported once: neither is new information, so neither should trigger another report.
+	v.verifyEquivocation(first, authorityID, 7, 0)
+	v.verifyEquivocation(second, authorityID, 7, 0)
+	v.verifyEquivocation(first, authorityID, 7, 0)
+	require.Equal(t, 1, rt.submittedCount(), "an already-reported equivocation must not be re-reported on repeat deliveries of the same two headers")
+}
+
+func TestVerifier_VerifyEquivocation_EmptyKeyOwnershipProofIsNonFatal(t *testing.T) {
+	rt := &mockEquivocationRuntime{keyOwnershipProof: nil}
+	v := newVerifier(rt)
+
+	var authorityID [32]byte
+	first := &types.Header{Number: big.NewInt(1), ExtrinsicsRoot: common.Hash{1}}
+	second := &types.Header{Number: big.NewInt(1), ExtrinsicsRoot: common.Hash{2}}
+
+	v.verifyEquivocation(first, authorityID, 7, 0)
+	v.verifyEquivocation(second, authorityID, 7, 0)
+
+	require.Equal(t, 0, rt.submittedCount(), "an empty key-ownership proof must not be submitted as an equivocation report")
+}
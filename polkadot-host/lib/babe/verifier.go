@@ -0,0 +1,67 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package babe
+
+import (
+	"errors"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+)
+
+// verifier verifies that incoming block headers claim their BABE slot legitimately. Signature
+// and VRF checks live alongside it; this file only adds equivocation detection on top of
+// whatever slot/authority a header has already been shown to claim.
+type verifier struct {
+	rt       equivocationRuntime
+	detector *equivocationDetector
+}
+
+// newVerifier creates a verifier that reports equivocations it detects through rt.
+func newVerifier(rt equivocationRuntime) *verifier {
+	return &verifier{
+		rt:       rt,
+		detector: newEquivocationDetector(),
+	}
+}
+
+// verifyEquivocation checks header's (authorityIndex, slot) pair against every header this
+// verifier has already verified. If header is a second, distinct header for a pair already on
+// record, it's a BABE equivocation: the verifier asks the runtime for a key-ownership proof of
+// authorityID and submits an unsigned equivocation-report extrinsic. A missing key-ownership
+// proof (errEmptyKeyOwnershipProof) and any other failure to report are both logged at warn
+// level; neither aborts verification of header itself, since the equivocation is evidence
+// against the authority, not a defect in this particular header.
+func (v *verifier) verifyEquivocation(header *types.Header, authorityID [32]byte, slot uint64, authorityIndex uint32) {
+	prev := v.detector.check(slot, authorityIndex, header)
+	if prev == nil {
+		return
+	}
+
+	err := reportEquivocation(v.rt, authorityID, slot, prev, header)
+	if err == nil {
+		return
+	}
+
+	if errors.Is(err, errEmptyKeyOwnershipProof) {
+		logger.Warn("BABE equivocation detected but authority has no key-ownership proof",
+			"slot", slot, "authorityIndex", authorityIndex)
+		return
+	}
+
+	logger.Warn("failed to report BABE equivocation",
+		"slot", slot, "authorityIndex", authorityIndex, "error", err)
+}
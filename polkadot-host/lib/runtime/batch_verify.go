@@ -0,0 +1,146 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package runtime
+
+import (
+	"crypto/ed25519"
+	goruntime "runtime"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
+)
+
+// VerifyScheme identifies which signature scheme a queued batch job should be checked
+// against.
+type VerifyScheme int
+
+const (
+	// Ed25519Scheme marks a batch job as an ed25519 signature check
+	Ed25519Scheme VerifyScheme = iota
+	// Sr25519Scheme marks a batch job as an sr25519 signature check
+	Sr25519Scheme
+	// EcdsaScheme marks a batch job as an ecdsa signature check
+	EcdsaScheme
+)
+
+// VerifyJob is a single queued signature check, scoped to one in-flight BatchVerifier.
+type VerifyJob struct {
+	Scheme VerifyScheme
+	Sig    []byte
+	Msg    []byte
+	Pubkey []byte
+}
+
+// BatchVerifier fans queued signature checks out to a bounded worker pool and short-circuits
+// as soon as any job fails, so a runtime validating hundreds of signatures per block doesn't
+// pay for them one at a time.
+type BatchVerifier struct {
+	jobs chan VerifyJob
+	wg   sync.WaitGroup
+
+	mu     sync.Mutex
+	failed bool
+}
+
+// NewBatchVerifier starts a worker pool sized to GOMAXPROCS and returns the verifier used to
+// enqueue jobs against it.
+func NewBatchVerifier() *BatchVerifier {
+	bv := &BatchVerifier{
+		jobs: make(chan VerifyJob, 128),
+	}
+
+	numWorkers := goruntime.GOMAXPROCS(0)
+	bv.wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go bv.run()
+	}
+
+	return bv
+}
+
+func (bv *BatchVerifier) run() {
+	defer bv.wg.Done()
+
+	for job := range bv.jobs {
+		// short-circuit: skip verification once a prior job has failed, we still drain
+		// the channel so Enqueue never blocks forever
+		if bv.hasFailed() {
+			continue
+		}
+
+		if !verifySignature(job) {
+			bv.mu.Lock()
+			bv.failed = true
+			bv.mu.Unlock()
+		}
+	}
+}
+
+func (bv *BatchVerifier) hasFailed() bool {
+	bv.mu.Lock()
+	defer bv.mu.Unlock()
+	return bv.failed
+}
+
+// Enqueue queues a signature check to run on the worker pool.
+func (bv *BatchVerifier) Enqueue(job VerifyJob) {
+	bv.jobs <- job
+}
+
+// Finish waits for every queued job to complete and reports whether every one of them
+// verified successfully.
+func (bv *BatchVerifier) Finish() bool {
+	close(bv.jobs)
+	bv.wg.Wait()
+	return !bv.hasFailed()
+}
+
+func verifySignature(job VerifyJob) bool {
+	switch job.Scheme {
+	case Ed25519Scheme:
+		return VerifyEd25519Signature(job.Sig, job.Msg, job.Pubkey)
+	case Sr25519Scheme:
+		return VerifySr25519Signature(job.Sig, job.Msg, job.Pubkey)
+	case EcdsaScheme:
+		return VerifyEcdsaSignature(job.Sig, job.Msg, job.Pubkey)
+	default:
+		return false
+	}
+}
+
+// VerifyEd25519Signature reports whether sig is a valid ed25519 signature by pubkey over msg.
+func VerifyEd25519Signature(sig, msg, pubkey []byte) bool {
+	if len(pubkey) != ed25519.PublicKeySize || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	return ed25519.Verify(pubkey, msg, sig)
+}
+
+// VerifySr25519Signature reports whether sig is a valid sr25519 signature by pubkey over msg.
+func VerifySr25519Signature(sig, msg, pubkey []byte) bool {
+	pub, err := sr25519.NewPublicKey(pubkey)
+	if err != nil {
+		return false
+	}
+
+	ok, err := pub.Verify(msg, sig)
+	if err != nil {
+		return false
+	}
+
+	return ok
+}
@@ -39,6 +39,11 @@ const (
 	TEST_RUNTIME  = "test_runtime"
 	TESTS_FP      = "test_wasm.wasm"
 	TEST_WASM_URL = "https://github.com/ChainSafe/gossamer-test-wasm/blob/noot/target/wasm32-unknown-unknown/release/test_wasm.wasm?raw=true"
+
+	// a parachain candidate validation (PVF) runtime, for exercising ImportsParachainRuntime
+	PARACHAIN_VALIDATION_RUNTIME     = "parachain_validation_runtime"
+	PARACHAIN_VALIDATION_RUNTIME_FP  = "parachain_validation_runtime.compact.wasm"
+	PARACHAIN_VALIDATION_RUNTIME_URL = "https://github.com/ChainSafe/gossamer-test-wasm/blob/noot/target/wasm32-unknown-unknown/release/parachain_validation_runtime.compact.wasm?raw=true"
 )
 
 var (
@@ -62,6 +67,21 @@ var (
 	BlockBuilderApplyExtrinsic = "BlockBuilder_apply_extrinsic"
 	// BlockBuilderFinalizeBlock is the runtime API call BlockBuilder_finalize_block
 	BlockBuilderFinalizeBlock = "BlockBuilder_finalize_block"
+	// BlockBuilderCheckInherents is the runtime API call BlockBuilder_check_inherents
+	BlockBuilderCheckInherents = "BlockBuilder_check_inherents"
+	// OffchainWorkerAPIOffchainWorker is the runtime API call OffchainWorkerApi_offchain_worker
+	OffchainWorkerAPIOffchainWorker = "OffchainWorkerApi_offchain_worker"
+	// CoreRandomSeed is the runtime API call Core_random_seed
+	CoreRandomSeed = "Core_random_seed"
+	// SessionKeysGenerateSessionKeys is the runtime API call SessionKeys_generate_session_keys
+	SessionKeysGenerateSessionKeys = "SessionKeys_generate_session_keys"
+	// BabeAPIGenerateKeyOwnershipProof is the runtime API call BabeApi_generate_key_ownership_proof
+	BabeAPIGenerateKeyOwnershipProof = "BabeApi_generate_key_ownership_proof"
+	// BabeAPISubmitReportEquivocationUnsignedExtrinsic is the runtime API call
+	// BabeApi_submit_report_equivocation_unsigned_extrinsic
+	BabeAPISubmitReportEquivocationUnsignedExtrinsic = "BabeApi_submit_report_equivocation_unsigned_extrinsic"
+	// TransactionPaymentAPIQueryInfo is the runtime API call TransactionPaymentApi_query_info
+	TransactionPaymentAPIQueryInfo = "TransactionPaymentApi_query_info"
 )
 
 // GrandpaAuthorityDataKey is the location of GRANDPA authority data in the storage trie for LEGACY_NODE_RUNTIME and NODE_RUNTIME
@@ -0,0 +1,105 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package runtime
+
+import (
+	"bytes"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+)
+
+// EcdsaVerifyError identifies why secp256k1 ECDSA public-key recovery failed, matching the
+// Result<_, EcdsaVerifyError> variants the runtime expects back from
+// ext_crypto_secp256k1_ecdsa_recover_version_1 and its compressed counterpart.
+type EcdsaVerifyError byte
+
+const (
+	// EcdsaVerifyErrorBadRS indicates the signature is not 65 bytes long
+	EcdsaVerifyErrorBadRS EcdsaVerifyError = iota
+	// EcdsaVerifyErrorBadV indicates the signature's recovery id is out of range
+	EcdsaVerifyErrorBadV
+	// EcdsaVerifyErrorBadSignature indicates the signature does not recover to a public key
+	EcdsaVerifyErrorBadSignature
+)
+
+// RecoverSecp256k1PublicKey recovers the 65-byte uncompressed public key (including its
+// leading 0x04 prefix byte) that produced sig over msg. sig must be the 65-byte compact
+// [R || S || V] signature and msg the 32-byte message hash; this mirrors the inputs
+// ext_crypto_secp256k1_ecdsa_recover_version_1 reads out of wasm linear memory.
+func RecoverSecp256k1PublicKey(sig, msg []byte) ([]byte, *EcdsaVerifyError) {
+	if len(sig) != 65 {
+		err := EcdsaVerifyErrorBadRS
+		return nil, &err
+	}
+
+	if sig[64] > 3 {
+		err := EcdsaVerifyErrorBadV
+		return nil, &err
+	}
+
+	pubkey, err := secp256k1.RecoverPubkey(msg, sig)
+	if err != nil {
+		badSig := EcdsaVerifyErrorBadSignature
+		return nil, &badSig
+	}
+
+	return pubkey, nil
+}
+
+// CompressSecp256k1PublicKey converts a 65-byte uncompressed public key (0x04 || X || Y)
+// into its 33-byte compressed form (0x02/0x03 || X).
+func CompressSecp256k1PublicKey(pubkey []byte) []byte {
+	compressed := make([]byte, 33)
+	copy(compressed[1:], pubkey[1:33])
+
+	if pubkey[64]&1 == 0 {
+		compressed[0] = 0x02
+	} else {
+		compressed[0] = 0x03
+	}
+
+	return compressed
+}
+
+// VerifyEcdsaSignaturePrehashed reports whether the 65-byte compact [R || S || V] signature
+// sig recovers, over the 32-byte hash the caller has already computed, to the 33-byte
+// compressed public key pubkey. It backs ext_crypto_ecdsa_verify_prehashed_version_1.
+func VerifyEcdsaSignaturePrehashed(sig, hash, pubkey []byte) bool {
+	if len(pubkey) != 33 {
+		return false
+	}
+
+	recovered, err := RecoverSecp256k1PublicKey(sig, hash)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(CompressSecp256k1PublicKey(recovered), pubkey)
+}
+
+// VerifyEcdsaSignature reports whether sig is a valid ecdsa signature by the compressed
+// public key pubkey over blake2_256(msg). It backs ext_crypto_ecdsa_verify_version_1 and
+// ext_crypto_ecdsa_verify_version_2, which both hash msg with blake2_256 before recovery.
+func VerifyEcdsaSignature(sig, msg, pubkey []byte) bool {
+	hash, err := common.Blake2bHash(msg)
+	if err != nil {
+		return false
+	}
+
+	return VerifyEcdsaSignaturePrehashed(sig, hash[:], pubkey)
+}
@@ -0,0 +1,43 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package runtime
+
+import "errors"
+
+// Sentinel errors returned by LegacyInstance.Exec implementations so that
+// callers can distinguish failure modes with errors.Is, regardless of which
+// wasm engine (wasmer, wasmtime, ...) produced them.
+var (
+	// ErrExportNotFound is returned when the requested function is not
+	// exported by the runtime.
+	ErrExportNotFound = errors.New("runtime function not found")
+	// ErrRuntimeCallFailed is returned when invoking an exported runtime
+	// function returns an error from the underlying wasm engine.
+	ErrRuntimeCallFailed = errors.New("runtime function call failed")
+	// ErrAllocatorFailed is returned when the heap allocator cannot
+	// satisfy an allocation request.
+	ErrAllocatorFailed = errors.New("runtime allocator failed")
+	// ErrPointerOverflow is returned when a pointer/length pair returned
+	// by the runtime (or about to be passed to it) falls outside the
+	// bounds of the guest's linear memory.
+	ErrPointerOverflow = errors.New("pointer and length overflow guest memory")
+	// ErrOutOfFuel is returned when a metered instance exhausts its fuel
+	// allowance mid-call, so callers can distinguish metering cutoffs
+	// (the runtime function was still making progress) from genuine
+	// runtime errors.
+	ErrOutOfFuel = errors.New("instance ran out of fuel")
+)
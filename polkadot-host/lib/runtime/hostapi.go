@@ -0,0 +1,232 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package runtime
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/common/optional"
+)
+
+// Memory is the guest linear memory backing a single wasm instance. It's implemented by
+// each interpreter's own memory type (wasmer's wasm.Memory, wasmtime's Memory, and
+// eventually life's) so HostAPI can read and write guest bytes without depending on any
+// one interpreter's calling convention.
+type Memory interface {
+	Data() []byte
+	Length() uint32
+}
+
+// HostAPI gathers the behaviour behind the ext_* host functions that are identical across
+// every wasm backend gossamer embeds: how arguments are unpacked from guest memory, what a
+// host function actually does with them, and how results are packed back. Each interpreter
+// keeps a thin adapter (currently lib/runtime/wasmer; lib/runtime/life will need its own
+// once that package is vendored here) that unpacks its own cgo/life calling convention into
+// plain byte slices, builds a HostAPI around the call's Memory/Allocator/Context, and
+// delegates. That keeps a single implementation of e.g. hashing, child storage and offchain
+// worker behaviour backing every interpreter, rather than one copy per backend drifting out
+// of sync with the others.
+type HostAPI struct {
+	Memory    Memory
+	Allocator *FreeingBumpHeapAllocator
+	Ctx       *Context
+}
+
+// NewHostAPI returns the HostAPI for a single host function call against memory, allocator
+// and ctx. Interpreter adapters construct one per call rather than caching it, since the
+// allocator's free list mutates between calls.
+func NewHostAPI(memory Memory, allocator *FreeingBumpHeapAllocator, ctx *Context) *HostAPI {
+	return &HostAPI{Memory: memory, Allocator: allocator, Ctx: ctx}
+}
+
+// PackSpan packs a guest pointer and length into the 64-bit span descriptor used across the
+// ext_* ABI to return a (ptr, size) pair in a single i64.
+func PackSpan(ptr, size uint32) int64 {
+	return int64(ptr) + (int64(size) << 32)
+}
+
+// UnpackSpan splits a 64-bit span descriptor back into its guest pointer and length.
+func UnpackSpan(span int64) (ptr, size uint32) {
+	return uint32(span), uint32(span >> 32)
+}
+
+// ReadSpan returns the guest memory slice described by span.
+func (h *HostAPI) ReadSpan(span int64) []byte {
+	ptr, size := UnpackSpan(span)
+	return h.Memory.Data()[ptr : ptr+size]
+}
+
+// WriteToMemory copies data into guest memory and returns the resulting span descriptor.
+func (h *HostAPI) WriteToMemory(data []byte) (int64, error) {
+	size := uint32(len(data))
+
+	out, err := h.Allocator.Allocate(size)
+	if err != nil {
+		return 0, err
+	}
+
+	copy(h.Memory.Data()[out:out+size], data)
+	return PackSpan(out, size), nil
+}
+
+// WriteSized copies a fixed-size data into guest memory and returns the pointer it was
+// written at, for host functions whose ABI returns a bare pointer because the caller
+// already knows the result size (e.g. a 32-byte hash).
+func (h *HostAPI) WriteSized(data []byte, size uint32) (uint32, error) {
+	if int(size) != len(data) {
+		return 0, errors.New("internal byte array size mismatch")
+	}
+
+	out, err := h.Allocator.Allocate(size)
+	if err != nil {
+		return 0, err
+	}
+
+	copy(h.Memory.Data()[out:out+size], data)
+	return out, nil
+}
+
+// WriteOptional wraps data in a SCALE-encoded Option<Vec<u8>> (None if data is empty) and
+// copies it into guest memory, matching the ABI of the ext_*_get-style host functions.
+func (h *HostAPI) WriteOptional(data []byte) (int64, error) {
+	var opt *optional.Bytes
+	if len(data) == 0 {
+		opt = optional.NewBytes(false, nil)
+	} else {
+		opt = optional.NewBytes(true, data)
+	}
+
+	enc, err := opt.Encode()
+	if err != nil {
+		return 0, err
+	}
+
+	return h.WriteToMemory(enc)
+}
+
+// Twox128 hashes data with twox_128 and writes the 16-byte digest into guest memory,
+// returning the pointer it was written at. It backs ext_hashing_twox_128_version_1.
+func (h *HostAPI) Twox128(data []byte) (uint32, error) {
+	hash, err := common.Twox128(data)
+	if err != nil {
+		return 0, err
+	}
+
+	return h.WriteSized(hash[:], 16)
+}
+
+// storageReadResult copies min(len(value)-offset, len(dst)) bytes of value[offset:] into dst
+// and returns the SCALE-encoded Option<u32> that ext_storage_read_version_1 and
+// ext_default_child_storage_read_version_1 return: []byte{0} (None) if value is absent,
+// otherwise Some(n) where n is the number of bytes that remained to copy from offset
+// (clamped to 0 once offset is past the end of value), regardless of how much of that
+// actually fit in dst.
+func storageReadResult(dst, value []byte, offset uint32) []byte {
+	if len(value) == 0 {
+		return []byte{0}
+	}
+
+	var remaining []byte
+	if offset < uint32(len(value)) {
+		remaining = value[offset:]
+	}
+	copy(dst, remaining)
+
+	enc := make([]byte, 5)
+	enc[0] = 1
+	binary.LittleEndian.PutUint32(enc[1:], uint32(len(remaining)))
+	return enc
+}
+
+// ReadStorageValue copies min(len(value)-offset, the guest buffer's length) bytes of
+// value[offset:] into the guest buffer described by bufferSpan, then writes and returns a
+// span pointing at the SCALE-encoded Option<u32> described by storageReadResult. It backs
+// ext_storage_read_version_1 and ext_default_child_storage_read_version_1.
+func (h *HostAPI) ReadStorageValue(value []byte, bufferSpan int64, offset uint32) (int64, error) {
+	bufPtr, bufSize := UnpackSpan(bufferSpan)
+	return h.WriteToMemory(storageReadResult(h.Memory.Data()[bufPtr:bufPtr+bufSize], value, offset))
+}
+
+// ChildStorageGet returns the SCALE-encoded Option<Vec<u8>> value stored under key in the
+// child trie rooted at keyToChild, backing ext_default_child_storage_get_version_1.
+func (h *HostAPI) ChildStorageGet(keyToChild, key []byte) (int64, error) {
+	value, err := h.Ctx.Storage.GetChildStorage(keyToChild, key)
+	if err != nil {
+		return 0, err
+	}
+
+	return h.WriteOptional(value)
+}
+
+// ChildStorageSet stores value under key in the child trie rooted at keyToChild, backing
+// ext_default_child_storage_set_version_1.
+func (h *HostAPI) ChildStorageSet(keyToChild, key, value []byte) error {
+	return h.Ctx.Storage.SetChildStorage(keyToChild, key, value)
+}
+
+// OffchainLocalStorageGet returns the SCALE-encoded Option<Vec<u8>> value stored under key in
+// the given offchain storage kind, backing ext_offchain_local_storage_get_version_1. It
+// reports an empty span rather than an error when no Offchain backend is configured, matching
+// the other ext_offchain_* host functions.
+func (h *HostAPI) OffchainLocalStorageGet(kind int32, key []byte) (int64, error) {
+	if h.Ctx.Offchain == nil {
+		return 0, nil
+	}
+
+	value, err := h.Ctx.Offchain.LocalStorageGet(kind, key)
+	if err != nil {
+		return 0, err
+	}
+
+	return h.WriteOptional(value)
+}
+
+// OffchainLocalStorageSet stores value under key in the given offchain storage kind, backing
+// ext_offchain_local_storage_set_version_1. It's a no-op when no Offchain backend is
+// configured, matching the other ext_offchain_* host functions.
+func (h *HostAPI) OffchainLocalStorageSet(kind int32, key, value []byte) error {
+	if h.Ctx.Offchain == nil {
+		return nil
+	}
+
+	return h.Ctx.Offchain.LocalStorageSet(kind, key, value)
+}
+
+// StartBatchVerify opens a new signature verification batch on the call's Context, backing
+// ext_crypto_start_batch_verify_version_1.
+func (h *HostAPI) StartBatchVerify() error {
+	if h.Ctx.Batch != nil {
+		return errors.New("a batch verification is already in progress")
+	}
+
+	h.Ctx.Batch = NewBatchVerifier()
+	return nil
+}
+
+// FinishBatchVerify closes the in-flight signature verification batch and reports whether
+// every queued job passed, backing ext_crypto_finish_batch_verify_version_1.
+func (h *HostAPI) FinishBatchVerify() (bool, error) {
+	if h.Ctx.Batch == nil {
+		return false, errors.New("no batch verification in progress")
+	}
+
+	ok := h.Ctx.Batch.Finish()
+	h.Ctx.Batch = nil
+	return ok, nil
+}
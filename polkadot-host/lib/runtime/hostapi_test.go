@@ -0,0 +1,51 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageReadResult_MissingKey(t *testing.T) {
+	dst := make([]byte, 4)
+	enc := storageReadResult(dst, nil, 0)
+	require.Equal(t, []byte{0}, enc)
+	require.Equal(t, make([]byte, 4), dst) // untouched
+}
+
+func TestStorageReadResult_OffsetPastEnd(t *testing.T) {
+	dst := []byte{0xff, 0xff, 0xff, 0xff}
+	enc := storageReadResult(dst, []byte("abcd"), 10)
+	require.Equal(t, []byte{1, 0, 0, 0, 0}, enc) // Some(0)
+	require.Equal(t, []byte{0xff, 0xff, 0xff, 0xff}, dst) // untouched
+}
+
+func TestStorageReadResult_ShortBuffer(t *testing.T) {
+	dst := make([]byte, 3)
+	enc := storageReadResult(dst, []byte("abcdefghij"), 0) // 10 bytes, only 3 fit
+	require.Equal(t, []byte("abc"), dst)
+	require.Equal(t, []byte{1, 10, 0, 0, 0}, enc) // Some(10), the full remaining count
+}
+
+func TestStorageReadResult_OffsetWithinBounds(t *testing.T) {
+	dst := make([]byte, 8)
+	enc := storageReadResult(dst, []byte("abcdefghij"), 5) // "fghij" remains
+	require.Equal(t, []byte("fghij"), dst[:5])
+	require.Equal(t, []byte{1, 5, 0, 0, 0}, enc) // Some(5)
+}
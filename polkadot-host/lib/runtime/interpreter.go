@@ -0,0 +1,49 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package runtime
+
+// Interpreter is implemented by every wasm backend's top-level instance type (wasmer.Instance,
+// wasmtime.Instance, ...), so callers that only need to run a runtime function - block import,
+// tx validation, the block proposer - don't have to depend on a concrete engine. A factory
+// keyed by each backend's Name constant lives in lib/runtime/testhelpers rather than here: both
+// backends import this package for Storage/InstanceConfig/etc, so a factory that imported them
+// back would be a cycle.
+type Interpreter interface {
+	// Exec calls the given exported runtime function with the given SCALE-encoded argument data.
+	Exec(function string, data []byte) ([]byte, error)
+	// SetContext sets the runtime's storage. It should be called before Exec.
+	SetContext(s Storage)
+	// NodeStorage returns the context's NodeStorage.
+	NodeStorage() NodeStorage
+	// NetworkService returns the context's BasicNetwork.
+	NetworkService() BasicNetwork
+	// Stop tears down the instance and releases any engine-side resources it holds.
+	Stop()
+	// Name returns the interpreter's backend name (wasmer.Name, wasmtime.Name, ...).
+	Name() string
+	// Metrics reports execution metrics gathered since the instance was created. Fields a
+	// backend doesn't support are left at their zero value; see Metrics for details.
+	Metrics() Metrics
+}
+
+// Metrics reports wasm-engine-specific execution metrics collected by an Interpreter. Not every
+// backend populates every field.
+type Metrics struct {
+	// FuelConsumed is the wasmtime fuel consumed by the instance's Exec calls so far. Always 0
+	// for backends without fuel metering (today: wasmer).
+	FuelConsumed uint64
+}
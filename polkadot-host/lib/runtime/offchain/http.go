@@ -0,0 +1,230 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package offchain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+)
+
+// ErrUnknownRequest is returned when a host function call references a request ID Session has
+// no record of, either because it was never started or because it already finished and was
+// dropped.
+var ErrUnknownRequest = errors.New("unknown offchain http request")
+
+// httpRequestPhase tracks where a request sits in the
+// start -> add-header* -> write-body* -> dispatched -> finished/failed lifecycle.
+type httpRequestPhase int
+
+const (
+	httpRequestBuilding httpRequestPhase = iota
+	httpRequestFinished
+	httpRequestFailed
+)
+
+// httpRequest holds the state for one in-flight or completed offchain HTTP request. A request
+// is dispatched the moment HTTPRequestWriteBody is called with an empty chunk, matching the
+// Substrate convention that an empty write finalizes the body; this Session implementation
+// performs the round trip synchronously at that point rather than pipelining multiple
+// outstanding requests, which keeps the request's observable state simple: by the time
+// HTTPResponseWait is asked about an id, dispatch has already happened and the outcome is
+// final.
+type httpRequest struct {
+	method string
+	uri    string
+	header http.Header
+	body   bytes.Buffer
+
+	phase      httpRequestPhase
+	statusCode uint16
+	respHeader http.Header
+	respBody   []byte
+	readOffset int
+}
+
+func deadlineContext(deadline *uint64) (context.Context, context.CancelFunc) {
+	if deadline == nil {
+		return context.Background(), func() {}
+	}
+	return context.WithDeadline(context.Background(), time.UnixMilli(int64(*deadline)))
+}
+
+// HTTPRequestStart begins building a request for method and uri, returning the ID later calls
+// use to refer to it.
+func (s *Session) HTTPRequestStart(method, uri string) (uint16, error) {
+	s.reqMu.Lock()
+	defer s.reqMu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+
+	s.requests[id] = &httpRequest{
+		method: method,
+		uri:    uri,
+		header: make(http.Header),
+	}
+	return id, nil
+}
+
+// HTTPRequestAddHeader attaches a header to a request still being built.
+func (s *Session) HTTPRequestAddHeader(id uint16, name, value string) error {
+	s.reqMu.Lock()
+	defer s.reqMu.Unlock()
+
+	req, ok := s.requests[id]
+	if !ok {
+		return ErrUnknownRequest
+	}
+	if req.phase != httpRequestBuilding {
+		return errors.New("request already dispatched")
+	}
+
+	req.header.Add(name, value)
+	return nil
+}
+
+// HTTPRequestWriteBody appends chunk to the request body. A zero-length chunk finalizes the
+// body and dispatches the request.
+func (s *Session) HTTPRequestWriteBody(id uint16, chunk []byte, deadline *uint64) error {
+	s.reqMu.Lock()
+	req, ok := s.requests[id]
+	s.reqMu.Unlock()
+	if !ok {
+		return ErrUnknownRequest
+	}
+	if req.phase != httpRequestBuilding {
+		return errors.New("request already dispatched")
+	}
+
+	if len(chunk) > 0 {
+		req.body.Write(chunk)
+		return nil
+	}
+
+	s.dispatch(req, deadline)
+	return nil
+}
+
+// dispatch performs req's HTTP round trip and records the outcome on req.
+func (s *Session) dispatch(req *httpRequest, deadline *uint64) {
+	ctx, cancel := deadlineContext(deadline)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, req.uri, bytes.NewReader(req.body.Bytes()))
+	if err != nil {
+		req.phase = httpRequestFailed
+		return
+	}
+	httpReq.Header = req.header
+
+	resp, err := s.store.client.Do(httpReq)
+	if err != nil {
+		req.phase = httpRequestFailed
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		req.phase = httpRequestFailed
+		return
+	}
+
+	req.phase = httpRequestFinished
+	req.statusCode = uint16(resp.StatusCode)
+	req.respHeader = resp.Header
+	req.respBody = body
+}
+
+// HTTPResponseWait reports the outcome of every request in ids, in the same order. Since
+// dispatch happens synchronously inside HTTPRequestWriteBody, every known id is already in
+// its final state by the time this is called; deadline is accepted for ABI compatibility but
+// there is nothing left to wait on.
+func (s *Session) HTTPResponseWait(ids []uint16, _ *uint64) []runtime.HTTPRequestStatus {
+	statuses := make([]runtime.HTTPRequestStatus, len(ids))
+
+	s.reqMu.Lock()
+	defer s.reqMu.Unlock()
+
+	for i, id := range ids {
+		req, ok := s.requests[id]
+		if !ok {
+			statuses[i] = runtime.HTTPRequestStatus{Outcome: runtime.HTTPOutcomeInvalid}
+			continue
+		}
+
+		switch req.phase {
+		case httpRequestFinished:
+			statuses[i] = runtime.HTTPRequestStatus{Outcome: runtime.HTTPOutcomeFinished, StatusCode: req.statusCode}
+		case httpRequestFailed:
+			statuses[i] = runtime.HTTPRequestStatus{Outcome: runtime.HTTPOutcomeIoError}
+		default:
+			statuses[i] = runtime.HTTPRequestStatus{Outcome: runtime.HTTPOutcomeDeadlineReached}
+		}
+	}
+
+	return statuses
+}
+
+// HTTPResponseHeaders returns the response headers for a finished request as name/value
+// byte-slice pairs. An unknown or not-yet-finished request reports no headers.
+func (s *Session) HTTPResponseHeaders(id uint16) [][2][]byte {
+	s.reqMu.Lock()
+	defer s.reqMu.Unlock()
+
+	req, ok := s.requests[id]
+	if !ok || req.phase != httpRequestFinished {
+		return nil
+	}
+
+	pairs := make([][2][]byte, 0, len(req.respHeader))
+	for name, values := range req.respHeader {
+		for _, value := range values {
+			pairs = append(pairs, [2][]byte{[]byte(name), []byte(value)})
+		}
+	}
+	return pairs
+}
+
+// HTTPResponseReadBody copies as much of the response body into buffer as fits, returning the
+// number of bytes copied. deadline is accepted for ABI compatibility; the body is already
+// buffered in full by the time a request finishes, so there is nothing to wait on.
+func (s *Session) HTTPResponseReadBody(id uint16, buffer []byte, _ *uint64) (int, error) {
+	s.reqMu.Lock()
+	defer s.reqMu.Unlock()
+
+	req, ok := s.requests[id]
+	if !ok {
+		return 0, ErrUnknownRequest
+	}
+	if req.phase == httpRequestFailed {
+		return 0, errors.New("request failed")
+	}
+	if req.phase != httpRequestFinished {
+		return 0, errors.New("request not finished")
+	}
+
+	n := copy(buffer, req.respBody[req.readOffset:])
+	req.readOffset += n
+	return n, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package offchain
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) *Store {
+	db, err := chaindb.NewBadgerDB(filepath.Join(t.TempDir(), "offchain"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return NewStore(db, nil, nil, false, nil)
+}
+
+// TestSession_HTTPRoundTrip drives a full HTTPRequestStart -> HTTPRequestAddHeader ->
+// HTTPRequestWriteBody -> HTTPResponseWait -> HTTPResponseHeaders -> HTTPResponseReadBody
+// sequence against an httptest.Server, the same order the ext_offchain_http_* host functions
+// call into a Session.
+func TestSession_HTTPRoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "POST", r.Method)
+		require.Equal(t, "bar", r.Header.Get("X-Foo"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(body))
+
+		w.Header().Set("X-Reply", "pong")
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("world"))
+	}))
+	defer server.Close()
+
+	session := newTestStore(t).NewSession()
+
+	id, err := session.HTTPRequestStart("POST", server.URL)
+	require.NoError(t, err)
+
+	require.NoError(t, session.HTTPRequestAddHeader(id, "X-Foo", "bar"))
+	require.NoError(t, session.HTTPRequestWriteBody(id, []byte("hello"), nil))
+	require.NoError(t, session.HTTPRequestWriteBody(id, nil, nil))
+
+	statuses := session.HTTPResponseWait([]uint16{id}, nil)
+	require.Len(t, statuses, 1)
+	require.Equal(t, runtime.HTTPOutcomeFinished, statuses[0].Outcome)
+	require.Equal(t, uint16(http.StatusTeapot), statuses[0].StatusCode)
+
+	var reply string
+	for _, pair := range session.HTTPResponseHeaders(id) {
+		if string(pair[0]) == "X-Reply" {
+			reply = string(pair[1])
+		}
+	}
+	require.Equal(t, "pong", reply)
+
+	buf := make([]byte, 32)
+	n, err := session.HTTPResponseReadBody(id, buf, nil)
+	require.NoError(t, err)
+	require.Equal(t, "world", string(buf[:n]))
+}
+
+// TestSession_HTTPRoundTrip_UnknownRequest asserts that referencing a request ID that was never
+// started makes HTTPRequestAddHeader, HTTPRequestWriteBody and HTTPResponseReadBody fail with
+// ErrUnknownRequest.
+func TestSession_HTTPRoundTrip_UnknownRequest(t *testing.T) {
+	session := newTestStore(t).NewSession()
+
+	require.ErrorIs(t, session.HTTPRequestAddHeader(42, "X-Foo", "bar"), ErrUnknownRequest)
+	require.ErrorIs(t, session.HTTPRequestWriteBody(42, nil, nil), ErrUnknownRequest)
+
+	_, err := session.HTTPResponseReadBody(42, make([]byte, 8), nil)
+	require.ErrorIs(t, err, ErrUnknownRequest)
+}
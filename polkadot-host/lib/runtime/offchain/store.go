@@ -0,0 +1,203 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package offchain
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/transaction"
+)
+
+// persistentPrefix namespaces Store's keys within the badger DB it's given, so
+// NodeStorageTypePersistent reads and writes don't collide with the node's other
+// badger-backed state when Store is pointed at the node's main database.
+var persistentPrefix = []byte("offchain/persistent/")
+
+// TransactionPool is the subset of dot/state.TransactionState a Store needs to hand off
+// extrinsics submitted via ext_offchain_submit_transaction_version_1.
+type TransactionPool interface {
+	AddToPool(vt *transaction.ValidTransaction) common.Hash
+}
+
+// Store is the runtime.Offchain and runtime.HTTPOffchain backend shared by the wasmer and
+// wasmtime interpreters. NodeStorageTypePersistent reads and writes go through db under
+// persistentPrefix, so they survive a restart; NodeStorageTypeLocal is served out of the
+// in-memory map a Session owns, which does not outlive that Session. Outbound HTTP requests are
+// issued through client and tracked per Session, matching how Substrate scopes both LOCAL
+// storage and in-flight HTTP state to a single offchain worker run rather than the node's
+// lifetime.
+type Store struct {
+	db        *chaindb.BadgerDB
+	txPool    TransactionPool
+	network   runtime.BasicNetwork
+	validator bool
+	client    *http.Client
+}
+
+// NewStore creates a Store whose PERSISTENT storage is namespaced within db. Extrinsics
+// submitted by the runtime are handed to txPool.AddToPool. client is the http.Client offchain
+// HTTP requests are issued through; a nil client means http.DefaultClient.
+func NewStore(db *chaindb.BadgerDB, txPool TransactionPool, network runtime.BasicNetwork, validator bool, client *http.Client) *Store {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Store{
+		db:        db,
+		txPool:    txPool,
+		network:   network,
+		validator: validator,
+		client:    client,
+	}
+}
+
+func (s *Store) persistentKey(key []byte) []byte {
+	return append(append([]byte{}, persistentPrefix...), key...)
+}
+
+// NewSession returns a Session that reads and writes PERSISTENT storage, submits transactions,
+// and issues HTTP requests through s, but has its own LOCAL storage and HTTP request table
+// isolated from every other Session s has handed out.
+func (s *Store) NewSession() *Session {
+	return &Session{
+		store:    s,
+		local:    make(map[string][]byte),
+		requests: make(map[uint16]*httpRequest),
+	}
+}
+
+// Session is one offchain worker run's view of a Store.
+type Session struct {
+	store *Store
+
+	localMu sync.Mutex
+	local   map[string][]byte
+
+	reqMu    sync.Mutex
+	nextID   uint16
+	requests map[uint16]*httpRequest
+}
+
+var _ runtime.Offchain = (*Session)(nil)
+var _ runtime.HTTPOffchain = (*Session)(nil)
+
+// LocalStorageGet returns the value stored under key in the given storage kind.
+func (s *Session) LocalStorageGet(kind int32, key []byte) ([]byte, error) {
+	if runtime.NodeStorageType(kind) == runtime.NodeStorageTypePersistent {
+		value, err := s.store.db.Get(s.store.persistentKey(key))
+		if errors.Is(err, chaindb.ErrKeyNotFound) {
+			return nil, nil
+		}
+		return value, err
+	}
+
+	s.localMu.Lock()
+	defer s.localMu.Unlock()
+	return s.local[string(key)], nil
+}
+
+// LocalStorageSet stores value under key in the given storage kind.
+func (s *Session) LocalStorageSet(kind int32, key, value []byte) error {
+	if runtime.NodeStorageType(kind) == runtime.NodeStorageTypePersistent {
+		return s.store.db.Put(s.store.persistentKey(key), value)
+	}
+
+	s.localMu.Lock()
+	defer s.localMu.Unlock()
+	s.local[string(key)] = value
+	return nil
+}
+
+// LocalStorageCompareAndSet atomically sets newValue for key if and only if the current value
+// equals oldValue.
+func (s *Session) LocalStorageCompareAndSet(kind int32, key, oldValue, newValue []byte) (bool, error) {
+	if runtime.NodeStorageType(kind) == runtime.NodeStorageTypePersistent {
+		persistentKey := s.store.persistentKey(key)
+
+		cur, err := s.store.db.Get(persistentKey)
+		if err != nil && !errors.Is(err, chaindb.ErrKeyNotFound) {
+			return false, err
+		}
+		if !bytes.Equal(cur, oldValue) {
+			return false, nil
+		}
+		if err := s.store.db.Put(persistentKey, newValue); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	s.localMu.Lock()
+	defer s.localMu.Unlock()
+	if !bytes.Equal(s.local[string(key)], oldValue) {
+		return false, nil
+	}
+	s.local[string(key)] = newValue
+	return true, nil
+}
+
+// LocalStorageClear removes key from the given storage kind.
+func (s *Session) LocalStorageClear(kind int32, key []byte) error {
+	if runtime.NodeStorageType(kind) == runtime.NodeStorageTypePersistent {
+		return s.store.db.Del(s.store.persistentKey(key))
+	}
+
+	s.localMu.Lock()
+	defer s.localMu.Unlock()
+	delete(s.local, string(key))
+	return nil
+}
+
+// SubmitTransaction hands a SCALE-encoded extrinsic to the node's transaction pool.
+func (s *Session) SubmitTransaction(ext []byte) error {
+	if s.store.txPool == nil {
+		return errors.New("no transaction pool configured")
+	}
+
+	s.store.txPool.AddToPool(&transaction.ValidTransaction{
+		Extrinsic: ext,
+		Validity:  &transaction.Validity{Priority: 1},
+	})
+	return nil
+}
+
+// NetworkState returns the libp2p peer ID and multiaddrs of the host.
+func (s *Session) NetworkState() (common.NetworkState, error) {
+	if s.store.network == nil {
+		return common.NetworkState{}, errors.New("no network service configured")
+	}
+	return s.store.network.NetworkState(), nil
+}
+
+// RandomSeed returns 32 bytes of cryptographically random data.
+func (s *Session) RandomSeed() [32]byte {
+	var seed [32]byte
+	_, _ = rand.Read(seed[:])
+	return seed
+}
+
+// IsValidator reports whether the node is running with an authoring role.
+func (s *Session) IsValidator() bool {
+	return s.store.validator
+}
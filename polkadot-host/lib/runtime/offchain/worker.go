@@ -0,0 +1,78 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package offchain drives a runtime's OffchainWorkerApi_offchain_worker entry
+// point once per imported block.
+package offchain
+
+import (
+	"github.com/ChainSafe/gossamer/dot/types"
+	log "github.com/ChainSafe/log15"
+)
+
+var logger = log.New("pkg", "runtime", "module", "offchain")
+
+// Instance is implemented by runtime instances that expose the offchain
+// worker entry point (lib/runtime/wasmer.Instance satisfies it; the wasmtime
+// Instance's OffchainWorker takes no header and returns no error, so it does not).
+type Instance interface {
+	OffchainWorker(header *types.Header) error
+}
+
+// Worker calls Instance.OffchainWorker once for every header received on
+// its imported channel, until Stop is called.
+type Worker struct {
+	instance Instance
+	imported <-chan *types.Header
+	stop     chan struct{}
+}
+
+// NewWorker creates a Worker that runs the given instance's offchain worker
+// against each header sent on imported.
+func NewWorker(instance Instance, imported <-chan *types.Header) *Worker {
+	return &Worker{
+		instance: instance,
+		imported: imported,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start runs the driver loop in its own goroutine and returns immediately.
+func (w *Worker) Start() {
+	go w.run()
+}
+
+// Stop terminates the driver loop started by Start.
+func (w *Worker) Stop() {
+	close(w.stop)
+}
+
+func (w *Worker) run() {
+	for {
+		select {
+		case header, ok := <-w.imported:
+			if !ok {
+				return
+			}
+
+			if err := w.instance.OffchainWorker(header); err != nil {
+				logger.Error("failed to run offchain worker", "block", header.Number, "error", err)
+			}
+		case <-w.stop:
+			return
+		}
+	}
+}
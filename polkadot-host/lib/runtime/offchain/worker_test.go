@@ -0,0 +1,60 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package offchain
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/stretchr/testify/require"
+)
+
+type testInstance struct {
+	mu      sync.Mutex
+	headers []*types.Header
+}
+
+func (ti *testInstance) OffchainWorker(header *types.Header) error {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	ti.headers = append(ti.headers, header)
+	return nil
+}
+
+func (ti *testInstance) seen() []*types.Header {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	return ti.headers
+}
+
+func TestWorker_RunsOnEachImportedHeader(t *testing.T) {
+	inst := new(testInstance)
+	imported := make(chan *types.Header)
+
+	w := NewWorker(inst, imported)
+	w.Start()
+	defer w.Stop()
+
+	header := &types.Header{}
+	imported <- header
+
+	require.Eventually(t, func() bool {
+		return len(inst.seen()) == 1
+	}, time.Second, 10*time.Millisecond)
+}
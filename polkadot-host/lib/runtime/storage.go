@@ -0,0 +1,581 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package runtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/trie"
+)
+
+// Storage is the interface the ext_storage_* and ext_default_child_storage_* host functions
+// operate on. It's an interface, rather than *trie.Trie directly, so those host functions
+// never need to know that a transaction is just a stack of overlays sitting on top of the
+// trie; TrieState is the only implementation.
+type Storage interface {
+	Get(key []byte) ([]byte, error)
+	Set(key, value []byte) error
+	Clear(key []byte) error
+	ClearPrefix(prefix []byte) error
+	// ClearPrefixLimit deletes keys under prefix in lexicographic order, stopping once limit
+	// keys have been deleted. It returns how many keys were actually deleted and whether the
+	// prefix was fully cleared (false if the limit was hit before it was exhausted).
+	ClearPrefixLimit(prefix []byte, limit uint32) (deleted uint32, allDeleted bool, err error)
+	Append(key, value []byte) error
+	NextKey(key []byte) []byte
+	Root() (common.Hash, error)
+
+	// BeginStorageTransaction opens a new overlay on top of whatever is currently visible.
+	// Calls may nest to arbitrary depth.
+	BeginStorageTransaction()
+	// CommitStorageTransaction merges the innermost overlay into the one below it (or into
+	// the trie, if it was the outermost). It's a no-op if no transaction is open.
+	CommitStorageTransaction()
+	// RollbackStorageTransaction discards the innermost overlay and everything written to
+	// it, including changes merged in by a nested transaction's commit. It's a no-op if no
+	// transaction is open.
+	RollbackStorageTransaction()
+
+	GetChildStorage(keyToChild, key []byte) ([]byte, error)
+	SetChildStorage(keyToChild, key, value []byte) error
+	ClearChildStorage(keyToChild, key []byte) error
+	ClearPrefixInChild(keyToChild, prefix []byte) error
+	// ClearPrefixInChildWithLimit is ClearPrefixLimit, scoped to the child trie rooted at
+	// keyToChild.
+	ClearPrefixInChildWithLimit(keyToChild, prefix []byte, limit uint32) (uint32, bool, error)
+	GetChildNextKey(keyToChild, key []byte) ([]byte, error)
+	ChildStorageRoot(keyToChild []byte) (common.Hash, error)
+	DeleteChildStorage(keyToChild []byte) error
+}
+
+// storageOverlay is one layer of an in-flight nested storage transaction. A nil value
+// pointer in changes marks a key as deleted (as opposed to absent, which means "look at the
+// layer below"); clearedPrefixes records ClearPrefix calls so a prefix-wide delete also
+// shadows keys the layer below hasn't been asked about individually.
+type storageOverlay struct {
+	changes              map[string]*[]byte
+	clearedPrefixes      [][]byte
+	childChanges         map[string]map[string]*[]byte
+	clearedChildPrefixes map[string][][]byte
+	deletedChildren      map[string]bool
+}
+
+func newStorageOverlay() *storageOverlay {
+	return &storageOverlay{
+		changes:              make(map[string]*[]byte),
+		childChanges:         make(map[string]map[string]*[]byte),
+		clearedChildPrefixes: make(map[string][][]byte),
+		deletedChildren:      make(map[string]bool),
+	}
+}
+
+// TrieState is the default Storage implementation. With no transaction open, every method
+// reads and writes straight through to the trie; BeginStorageTransaction pushes a
+// storageOverlay that Set/Clear/ClearPrefix/Append write into instead, and Get/NextKey
+// consult the overlay stack top-to-bottom before falling through to the trie.
+type TrieState struct {
+	trie     *trie.Trie
+	overlays []*storageOverlay
+}
+
+// NewTrieState returns a TrieState backed by t. If t is nil, a new empty trie is used.
+func NewTrieState(t *trie.Trie) *TrieState {
+	if t == nil {
+		t = trie.NewEmptyTrie()
+	}
+	return &TrieState{trie: t}
+}
+
+// Get returns the value stored under key, consulting open transaction overlays from
+// innermost to outermost before falling through to the trie.
+func (s *TrieState) Get(key []byte) ([]byte, error) {
+	for i := len(s.overlays) - 1; i >= 0; i-- {
+		ov := s.overlays[i]
+		if v, ok := ov.changes[string(key)]; ok {
+			if v == nil {
+				return nil, nil
+			}
+			return *v, nil
+		}
+		for _, p := range ov.clearedPrefixes {
+			if bytes.HasPrefix(key, p) {
+				return nil, nil
+			}
+		}
+	}
+	return s.trie.Get(key)
+}
+
+// Set stores value under key in the innermost open transaction overlay, or directly in the
+// trie if no transaction is open.
+func (s *TrieState) Set(key, value []byte) error {
+	if len(s.overlays) == 0 {
+		return s.trie.Put(key, value)
+	}
+
+	v := append([]byte(nil), value...)
+	s.overlays[len(s.overlays)-1].changes[string(key)] = &v
+	return nil
+}
+
+// Clear deletes key in the innermost open transaction overlay, or directly in the trie if
+// no transaction is open.
+func (s *TrieState) Clear(key []byte) error {
+	if len(s.overlays) == 0 {
+		return s.trie.Delete(key)
+	}
+
+	s.overlays[len(s.overlays)-1].changes[string(key)] = nil
+	return nil
+}
+
+// ClearPrefix deletes every key under prefix in the innermost open transaction overlay, or
+// directly in the trie if no transaction is open.
+func (s *TrieState) ClearPrefix(prefix []byte) error {
+	if len(s.overlays) == 0 {
+		return s.trie.ClearPrefix(prefix)
+	}
+
+	top := s.overlays[len(s.overlays)-1]
+	for k := range top.changes {
+		if strings.HasPrefix(k, string(prefix)) {
+			delete(top.changes, k)
+		}
+	}
+	top.clearedPrefixes = append(top.clearedPrefixes, append([]byte(nil), prefix...))
+	return nil
+}
+
+// ClearPrefixLimit deletes keys under prefix in lexicographic order one at a time via NextKey
+// and Clear, stopping once limit keys have been deleted. Unlike ClearPrefix, which shadows the
+// whole prefix in one shot via clearedPrefixes, this has to delete key-by-key to be able to
+// stop partway through, so it doesn't touch clearedPrefixes at all.
+func (s *TrieState) ClearPrefixLimit(prefix []byte, limit uint32) (uint32, bool, error) {
+	return clearPrefixLimit(prefix, limit, s.NextKey, s.Clear)
+}
+
+// ClearPrefixInChildWithLimit is ClearPrefixLimit, scoped to the child trie rooted at
+// keyToChild.
+func (s *TrieState) ClearPrefixInChildWithLimit(keyToChild, prefix []byte, limit uint32) (uint32, bool, error) {
+	nextKey := func(key []byte) []byte {
+		next, err := s.GetChildNextKey(keyToChild, key)
+		if err != nil {
+			return nil
+		}
+		return next
+	}
+	clear := func(key []byte) error {
+		return s.ClearChildStorage(keyToChild, key)
+	}
+	return clearPrefixLimit(prefix, limit, nextKey, clear)
+}
+
+// clearPrefixLimit walks the keys under prefix in lexicographic order using nextKey, deleting
+// each with clear, until either limit keys have been deleted or there are no keys left under
+// prefix. It reports how many keys it deleted and whether the prefix was left fully cleared.
+func clearPrefixLimit(prefix []byte, limit uint32, nextKey func([]byte) []byte, clear func([]byte) error) (uint32, bool, error) {
+	var deleted uint32
+	key := append([]byte(nil), prefix...)
+
+	for deleted < limit {
+		next := nextKey(key)
+		if next == nil || !bytes.HasPrefix(next, prefix) {
+			return deleted, true, nil
+		}
+
+		if err := clear(next); err != nil {
+			return deleted, false, err
+		}
+		deleted++
+		key = next
+	}
+
+	next := nextKey(key)
+	allDeleted := next == nil || !bytes.HasPrefix(next, prefix)
+	return deleted, allDeleted, nil
+}
+
+// Append treats the value at key as a SCALE-encoded Vec<T> and appends value as a new
+// raw-encoded element, bumping the compact-encoded length prefix rather than decoding and
+// re-encoding the whole vector. It goes through Set, so it's overlay-aware like everything
+// else.
+func (s *TrieState) Append(key, value []byte) error {
+	existing, err := s.Get(key)
+	if err != nil {
+		return err
+	}
+
+	if len(existing) == 0 {
+		return s.Set(key, append(compactUint32(1), value...))
+	}
+
+	length, prefixLen, err := decodeCompactUint32(existing)
+	if err != nil {
+		// Not a previously-appended vec; treat this as the first element, same as
+		// Substrate does when ext_storage_append_version_1 sees malformed existing data.
+		return s.Set(key, append(compactUint32(1), value...))
+	}
+
+	updated := append(compactUint32(length+1), existing[prefixLen:]...)
+	updated = append(updated, value...)
+	return s.Set(key, updated)
+}
+
+// NextKey returns the lexicographically smallest key greater than key, across both the open
+// transaction overlays and the trie, skipping anything an overlay has deleted.
+func (s *TrieState) NextKey(key []byte) []byte {
+	for {
+		next := s.trie.NextKey(key)
+
+		for _, ov := range s.overlays {
+			for k, v := range ov.changes {
+				if v == nil {
+					continue
+				}
+				kb := []byte(k)
+				if bytes.Compare(kb, key) > 0 && (next == nil || bytes.Compare(kb, next) < 0) {
+					next = kb
+				}
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+		if !s.isDeleted(next) {
+			return next
+		}
+		key = next
+	}
+}
+
+func (s *TrieState) isDeleted(key []byte) bool {
+	for i := len(s.overlays) - 1; i >= 0; i-- {
+		ov := s.overlays[i]
+		if v, ok := ov.changes[string(key)]; ok {
+			return v == nil
+		}
+		for _, p := range ov.clearedPrefixes {
+			if bytes.HasPrefix(key, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Root returns the hash of the underlying trie. Like Substrate, it's only meaningful once
+// every open transaction has been committed or rolled back.
+func (s *TrieState) Root() (common.Hash, error) {
+	return s.trie.Hash()
+}
+
+// BeginStorageTransaction pushes a new overlay onto the transaction stack.
+func (s *TrieState) BeginStorageTransaction() {
+	s.overlays = append(s.overlays, newStorageOverlay())
+}
+
+// CommitStorageTransaction merges the innermost overlay into the one below it, or into the
+// trie if it was the outermost transaction.
+func (s *TrieState) CommitStorageTransaction() {
+	if len(s.overlays) == 0 {
+		return
+	}
+
+	top := s.overlays[len(s.overlays)-1]
+	s.overlays = s.overlays[:len(s.overlays)-1]
+
+	if len(s.overlays) > 0 {
+		parent := s.overlays[len(s.overlays)-1]
+
+		// A cleared prefix merged up from top must also purge keys the parent already holds
+		// in its own changes map: otherwise a Get against the parent overlay would find the
+		// parent's stale entry in ov.changes before it ever consults ov.clearedPrefixes.
+		for _, p := range top.clearedPrefixes {
+			ps := string(p)
+			for k := range parent.changes {
+				if strings.HasPrefix(k, ps) {
+					delete(parent.changes, k)
+				}
+			}
+		}
+		parent.clearedPrefixes = append(parent.clearedPrefixes, top.clearedPrefixes...)
+		for k, v := range top.changes {
+			parent.changes[k] = v
+		}
+
+		for child, prefixes := range top.clearedChildPrefixes {
+			dst := parent.childChanges[child]
+			for _, p := range prefixes {
+				ps := string(p)
+				for k := range dst {
+					if strings.HasPrefix(k, ps) {
+						delete(dst, k)
+					}
+				}
+			}
+			parent.clearedChildPrefixes[child] = append(parent.clearedChildPrefixes[child], prefixes...)
+		}
+		for child, changes := range top.childChanges {
+			dst, ok := parent.childChanges[child]
+			if !ok {
+				dst = make(map[string]*[]byte)
+				parent.childChanges[child] = dst
+			}
+			for k, v := range changes {
+				dst[k] = v
+			}
+		}
+		for child := range top.deletedChildren {
+			parent.deletedChildren[child] = true
+		}
+		return
+	}
+
+	for _, p := range top.clearedPrefixes {
+		_ = s.trie.ClearPrefix(p)
+	}
+	for k, v := range top.changes {
+		if v == nil {
+			_ = s.trie.Delete([]byte(k))
+		} else {
+			_ = s.trie.Put([]byte(k), *v)
+		}
+	}
+	for child := range top.deletedChildren {
+		_ = s.trie.DeleteChildTrie([]byte(child))
+	}
+	for child, prefixes := range top.clearedChildPrefixes {
+		for _, p := range prefixes {
+			_ = s.trie.ClearPrefixInChild([]byte(child), p)
+		}
+	}
+	for child, changes := range top.childChanges {
+		for k, v := range changes {
+			if v == nil {
+				_ = s.trie.DeleteChild([]byte(child), []byte(k))
+			} else {
+				_ = s.trie.PutChild([]byte(child), []byte(k), *v)
+			}
+		}
+	}
+}
+
+// RollbackStorageTransaction discards the innermost overlay, including any changes a nested
+// transaction merged into it with CommitStorageTransaction.
+func (s *TrieState) RollbackStorageTransaction() {
+	if len(s.overlays) == 0 {
+		return
+	}
+	s.overlays = s.overlays[:len(s.overlays)-1]
+}
+
+// GetChildStorage returns the value stored under key in the child trie rooted at keyToChild.
+func (s *TrieState) GetChildStorage(keyToChild, key []byte) ([]byte, error) {
+	for i := len(s.overlays) - 1; i >= 0; i-- {
+		ov := s.overlays[i]
+		if ov.deletedChildren[string(keyToChild)] {
+			return nil, nil
+		}
+		if v, ok := ov.childChanges[string(keyToChild)][string(key)]; ok {
+			if v == nil {
+				return nil, nil
+			}
+			return *v, nil
+		}
+		for _, p := range ov.clearedChildPrefixes[string(keyToChild)] {
+			if bytes.HasPrefix(key, p) {
+				return nil, nil
+			}
+		}
+	}
+	return s.trie.GetChild(keyToChild, key)
+}
+
+// SetChildStorage stores value under key in the child trie rooted at keyToChild.
+func (s *TrieState) SetChildStorage(keyToChild, key, value []byte) error {
+	if len(s.overlays) == 0 {
+		return s.trie.PutChild(keyToChild, key, value)
+	}
+
+	v := append([]byte(nil), value...)
+	s.childChanges(keyToChild)[string(key)] = &v
+	return nil
+}
+
+// ClearChildStorage deletes key from the child trie rooted at keyToChild.
+func (s *TrieState) ClearChildStorage(keyToChild, key []byte) error {
+	if len(s.overlays) == 0 {
+		return s.trie.DeleteChild(keyToChild, key)
+	}
+
+	s.childChanges(keyToChild)[string(key)] = nil
+	return nil
+}
+
+// ClearPrefixInChild deletes every key under prefix in the child trie rooted at keyToChild.
+func (s *TrieState) ClearPrefixInChild(keyToChild, prefix []byte) error {
+	if len(s.overlays) == 0 {
+		return s.trie.ClearPrefixInChild(keyToChild, prefix)
+	}
+
+	top := s.overlays[len(s.overlays)-1]
+	changes := s.childChanges(keyToChild)
+	for k := range changes {
+		if strings.HasPrefix(k, string(prefix)) {
+			delete(changes, k)
+		}
+	}
+	top.clearedChildPrefixes[string(keyToChild)] = append(top.clearedChildPrefixes[string(keyToChild)], append([]byte(nil), prefix...))
+	return nil
+}
+
+// GetChildNextKey returns the lexicographically smallest key greater than key in the child
+// trie rooted at keyToChild.
+func (s *TrieState) GetChildNextKey(keyToChild, key []byte) ([]byte, error) {
+	return s.trie.NextKeyChild(keyToChild, key), nil
+}
+
+// ChildStorageRoot returns the hash of the child trie rooted at keyToChild.
+func (s *TrieState) ChildStorageRoot(keyToChild []byte) (common.Hash, error) {
+	return s.trie.ChildRoot(keyToChild)
+}
+
+// DeleteChildStorage removes the entire child trie rooted at keyToChild.
+func (s *TrieState) DeleteChildStorage(keyToChild []byte) error {
+	if len(s.overlays) == 0 {
+		return s.trie.DeleteChildTrie(keyToChild)
+	}
+
+	top := s.overlays[len(s.overlays)-1]
+	top.deletedChildren[string(keyToChild)] = true
+	delete(top.childChanges, string(keyToChild))
+	delete(top.clearedChildPrefixes, string(keyToChild))
+	return nil
+}
+
+// childChanges returns the innermost overlay's change map for keyToChild, creating it if
+// this is the first write against that child trie within the transaction.
+func (s *TrieState) childChanges(keyToChild []byte) map[string]*[]byte {
+	top := s.overlays[len(s.overlays)-1]
+	changes, ok := top.childChanges[string(keyToChild)]
+	if !ok {
+		changes = make(map[string]*[]byte)
+		top.childChanges[string(keyToChild)] = changes
+	}
+	return changes
+}
+
+// compactUint32 SCALE-encodes n using the same compact integer format Substrate uses for a
+// Vec<T>'s length prefix.
+func compactUint32(n uint32) []byte {
+	switch {
+	case n < 1<<6:
+		return []byte{byte(n << 2)}
+	case n < 1<<14:
+		v := (n << 2) | 1
+		return []byte{byte(v), byte(v >> 8)}
+	case n < 1<<30:
+		v := (n << 2) | 2
+		return []byte{byte(v), byte(v >> 8), byte(v >> 16), byte(v >> 24)}
+	default:
+		b := []byte{byte(n), byte(n >> 8), byte(n >> 16), byte(n >> 24)}
+		return append([]byte{byte(len(b)-4)<<2 | 3}, b...)
+	}
+}
+
+// decodeCompactUint32 reads a SCALE compact-encoded length prefix from the front of b and
+// returns its value along with how many bytes it occupied.
+func decodeCompactUint32(b []byte) (n uint32, prefixLen int, err error) {
+	if len(b) == 0 {
+		return 0, 0, errors.New("empty compact length prefix")
+	}
+
+	switch b[0] & 0b11 {
+	case 0:
+		return uint32(b[0] >> 2), 1, nil
+	case 1:
+		if len(b) < 2 {
+			return 0, 0, errors.New("short compact length prefix")
+		}
+		return (uint32(b[0]) | uint32(b[1])<<8) >> 2, 2, nil
+	case 2:
+		if len(b) < 4 {
+			return 0, 0, errors.New("short compact length prefix")
+		}
+		return (uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24) >> 2, 4, nil
+	default:
+		numBytes := int(b[0]>>2) + 4
+		if len(b) < 1+numBytes {
+			return 0, 0, errors.New("short compact length prefix")
+		}
+		for i := 0; i < numBytes && i < 4; i++ {
+			n |= uint32(b[1+i]) << (8 * i)
+		}
+		return n, 1 + numBytes, nil
+	}
+}
+
+// EncodeKillStorageResult SCALE-encodes a KillStorageResult: the 0 variant (AllRemoved) if
+// allRemoved, otherwise the 1 variant (SomeRemaining), followed by numRemoved as a compact
+// u32. It backs the richer ext_storage_clear_prefix_version_2 and
+// ext_default_child_storage_clear_prefix_version_2 host functions, which superseded the
+// version_1 host functions' bare void return with a count of how many keys were removed.
+func EncodeKillStorageResult(allRemoved bool, numRemoved uint32) []byte {
+	variant := byte(0)
+	if !allRemoved {
+		variant = 1
+	}
+	return append([]byte{variant}, compactUint32(numRemoved)...)
+}
+
+// DecodeOptionalU32 decodes a SCALE-encoded Option<u32>: []byte{0} for None, or a leading 1
+// byte followed by a 4-byte little-endian u32 for Some(n). It backs decoding the deletion limit
+// argument to ext_storage_clear_prefix_version_2 and
+// ext_default_child_storage_clear_prefix_version_2.
+func DecodeOptionalU32(b []byte) (n uint32, some bool, err error) {
+	if len(b) == 0 {
+		return 0, false, errors.New("empty optional u32")
+	}
+	if b[0] == 0 {
+		return 0, false, nil
+	}
+	if len(b) < 5 {
+		return 0, false, errors.New("short optional u32")
+	}
+	return binary.LittleEndian.Uint32(b[1:5]), true, nil
+}
+
+// DecodeOptionalU64 decodes a SCALE-encoded Option<u64>: []byte{0} for None, or a leading 1
+// byte followed by an 8-byte little-endian u64 for Some(n). It backs decoding the deadline
+// argument the ext_offchain_http_* host functions take as a Unix-millisecond timestamp.
+func DecodeOptionalU64(b []byte) (n uint64, some bool, err error) {
+	if len(b) == 0 {
+		return 0, false, errors.New("empty optional u64")
+	}
+	if b[0] == 0 {
+		return 0, false, nil
+	}
+	if len(b) < 9 {
+		return 0, false, errors.New("short optional u64")
+	}
+	return binary.LittleEndian.Uint64(b[1:9]), true, nil
+}
@@ -0,0 +1,615 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package storage wraps a runtime.Storage with a write-through buffer and a bounded read
+// cache, so the host functions backing ext_storage_* and ext_default_child_storage_* don't pay
+// for a trie walk (and, for writes, a root-hash recomputation) on every single call.
+package storage
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+)
+
+var _ runtime.Storage = (*MemCachedStorage)(nil)
+
+// DefaultCacheSize is the read cache capacity MemCachedStorage uses when Config.CacheSize is
+// left at zero.
+const DefaultCacheSize = 1024
+
+// EvictionPolicyLRU evicts the least-recently-used entry once the read cache is full. It's
+// currently the only eviction policy MemCachedStorage implements; anything else passed as
+// Config.EvictionPolicy is treated the same way.
+const EvictionPolicyLRU = "lru"
+
+// Config configures a MemCachedStorage.
+type Config struct {
+	// CacheSize is the maximum number of entries the read cache holds before evicting. Zero
+	// means DefaultCacheSize.
+	CacheSize int
+	// EvictionPolicy selects how the read cache picks an entry to evict once it's full. See
+	// EvictionPolicyLRU.
+	EvictionPolicy string
+}
+
+// writeFrame is one level of MemCachedStorage's pending-write stack. It mirrors
+// runtime.TrieState's storageOverlay one layer up: a nil value marks a key as deleted rather
+// than absent, and clearedPrefixes/clearedChildPrefixes/deletedChildren record the same about
+// prefix- and child-trie-wide deletes.
+type writeFrame struct {
+	writes               map[string]*[]byte
+	clearedPrefixes      [][]byte
+	childWrites          map[string]map[string]*[]byte
+	clearedChildPrefixes map[string][][]byte
+	deletedChildren      map[string]bool
+}
+
+func newWriteFrame() *writeFrame {
+	return &writeFrame{
+		writes:               make(map[string]*[]byte),
+		childWrites:          make(map[string]map[string]*[]byte),
+		clearedChildPrefixes: make(map[string][][]byte),
+		deletedChildren:      make(map[string]bool),
+	}
+}
+
+// cacheEntry is one entry in the read cache. A nil value represents a cached negative hit
+// (the underlying Storage confirmed the key is absent), as opposed to no entry at all (meaning
+// the cache has nothing to say about the key).
+type cacheEntry struct {
+	key   string
+	value []byte
+}
+
+// MemCachedStorage wraps a runtime.Storage with a write-through buffer and a bounded read
+// cache. Writes accumulate in the top writeFrame and only reach the underlying Storage when
+// Commit flushes the base frame, which Root does on the caller's behalf; reads consult the
+// frame stack (innermost first), then the read cache, before falling through to the
+// underlying Storage. BeginStorageTransaction/CommitStorageTransaction/RollbackStorageTransaction
+// push, merge, and drop frames exactly like TrieState's overlay stack, one layer further out.
+type MemCachedStorage struct {
+	underlying runtime.Storage
+	frames     []*writeFrame
+
+	cacheSize int
+	cache     map[string]*list.Element
+	lru       *list.List
+}
+
+// New returns a MemCachedStorage wrapping underlying, with a single base write frame open.
+func New(underlying runtime.Storage, cfg Config) *MemCachedStorage {
+	size := cfg.CacheSize
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+
+	return &MemCachedStorage{
+		underlying: underlying,
+		frames:     []*writeFrame{newWriteFrame()},
+		cacheSize:  size,
+		cache:      make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func (m *MemCachedStorage) top() *writeFrame {
+	return m.frames[len(m.frames)-1]
+}
+
+func (m *MemCachedStorage) cacheGet(key string) ([]byte, bool) {
+	el, ok := m.cache[key]
+	if !ok {
+		return nil, false
+	}
+	m.lru.MoveToFront(el)
+	return el.Value.(*cacheEntry).value, true
+}
+
+func (m *MemCachedStorage) cachePut(key string, value []byte) {
+	if el, ok := m.cache[key]; ok {
+		el.Value.(*cacheEntry).value = value
+		m.lru.MoveToFront(el)
+		return
+	}
+
+	el := m.lru.PushFront(&cacheEntry{key: key, value: value})
+	m.cache[key] = el
+
+	for m.lru.Len() > m.cacheSize {
+		oldest := m.lru.Back()
+		if oldest == nil {
+			break
+		}
+		m.lru.Remove(oldest)
+		delete(m.cache, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (m *MemCachedStorage) cacheInvalidate(key string) {
+	if el, ok := m.cache[key]; ok {
+		m.lru.Remove(el)
+		delete(m.cache, key)
+	}
+}
+
+func (m *MemCachedStorage) cacheInvalidatePrefix(prefix []byte) {
+	for key, el := range m.cache {
+		if bytes.HasPrefix([]byte(key), prefix) {
+			m.lru.Remove(el)
+			delete(m.cache, key)
+		}
+	}
+}
+
+// Get returns the value stored under key, consulting the write frames (innermost to
+// outermost), then the read cache, before falling through to the underlying Storage.
+func (m *MemCachedStorage) Get(key []byte) ([]byte, error) {
+	k := string(key)
+
+	for i := len(m.frames) - 1; i >= 0; i-- {
+		f := m.frames[i]
+		if v, ok := f.writes[k]; ok {
+			if v == nil {
+				return nil, nil
+			}
+			return *v, nil
+		}
+		for _, p := range f.clearedPrefixes {
+			if bytes.HasPrefix(key, p) {
+				return nil, nil
+			}
+		}
+	}
+
+	if v, ok := m.cacheGet(k); ok {
+		return v, nil
+	}
+
+	value, err := m.underlying.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	m.cachePut(k, value)
+	return value, nil
+}
+
+// Set buffers value under key in the top write frame and invalidates any cached read for key.
+func (m *MemCachedStorage) Set(key, value []byte) error {
+	k := string(key)
+	v := append([]byte(nil), value...)
+	m.top().writes[k] = &v
+	m.cacheInvalidate(k)
+	return nil
+}
+
+// Clear buffers key's deletion in the top write frame and invalidates any cached read for key.
+func (m *MemCachedStorage) Clear(key []byte) error {
+	k := string(key)
+	m.top().writes[k] = nil
+	m.cacheInvalidate(k)
+	return nil
+}
+
+// ClearPrefix buffers prefix's deletion in the top write frame and invalidates every cached
+// read under prefix.
+func (m *MemCachedStorage) ClearPrefix(prefix []byte) error {
+	top := m.top()
+	for k := range top.writes {
+		if bytes.HasPrefix([]byte(k), prefix) {
+			delete(top.writes, k)
+		}
+	}
+	top.clearedPrefixes = append(top.clearedPrefixes, append([]byte(nil), prefix...))
+	m.cacheInvalidatePrefix(prefix)
+	return nil
+}
+
+// ClearPrefixLimit deletes keys under prefix in lexicographic order, stopping once limit keys
+// have been deleted, the same as runtime.TrieState.ClearPrefixLimit. Because it has to stop
+// partway through, it deletes key-by-key via NextKey/Clear rather than buffering a
+// clearedPrefixes entry.
+func (m *MemCachedStorage) ClearPrefixLimit(prefix []byte, limit uint32) (uint32, bool, error) {
+	var deleted uint32
+	key := append([]byte(nil), prefix...)
+
+	for deleted < limit {
+		next := m.NextKey(key)
+		if next == nil || !bytes.HasPrefix(next, prefix) {
+			return deleted, true, nil
+		}
+		if err := m.Clear(next); err != nil {
+			return deleted, false, err
+		}
+		deleted++
+		key = next
+	}
+
+	next := m.NextKey(key)
+	return deleted, next == nil || !bytes.HasPrefix(next, prefix), nil
+}
+
+// Append treats the value at key as a SCALE-encoded Vec<T> and appends value as a new
+// raw-encoded element, the same way runtime.TrieState.Append does; it's duplicated here,
+// rather than shared, because compactUint32/decodeCompactUint32 are private to the runtime
+// package, for the same reason storage.go's copy of them exists: the real scale package isn't
+// inspectable from this snapshot.
+func (m *MemCachedStorage) Append(key, value []byte) error {
+	existing, err := m.Get(key)
+	if err != nil {
+		return err
+	}
+
+	if len(existing) == 0 {
+		return m.Set(key, append(compactUint32(1), value...))
+	}
+
+	length, prefixLen, err := decodeCompactUint32(existing)
+	if err != nil {
+		return m.Set(key, append(compactUint32(1), value...))
+	}
+
+	updated := append(compactUint32(length+1), existing[prefixLen:]...)
+	updated = append(updated, value...)
+	return m.Set(key, updated)
+}
+
+// NextKey returns the lexicographically smallest key greater than key, across both the write
+// frames and the underlying Storage, skipping anything a frame has deleted.
+func (m *MemCachedStorage) NextKey(key []byte) []byte {
+	for {
+		next := m.underlying.NextKey(key)
+
+		for _, f := range m.frames {
+			for k, v := range f.writes {
+				if v == nil {
+					continue
+				}
+				kb := []byte(k)
+				if bytes.Compare(kb, key) > 0 && (next == nil || bytes.Compare(kb, next) < 0) {
+					next = kb
+				}
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+		if !m.isDeleted(next) {
+			return next
+		}
+		key = next
+	}
+}
+
+func (m *MemCachedStorage) isDeleted(key []byte) bool {
+	for i := len(m.frames) - 1; i >= 0; i-- {
+		f := m.frames[i]
+		if v, ok := f.writes[string(key)]; ok {
+			return v == nil
+		}
+		for _, p := range f.clearedPrefixes {
+			if bytes.HasPrefix(key, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Commit flushes the base write frame into the underlying Storage and clears the cache
+// entries it touched. It's a no-op if a transaction is still open (len(frames) > 1): like
+// TrieState.Root, Root is only meaningful once every transaction has been committed or rolled
+// back, so callers are expected to have unwound the stack to just the base frame first.
+func (m *MemCachedStorage) Commit() error {
+	if len(m.frames) != 1 {
+		return nil
+	}
+
+	base := m.frames[0]
+	// Cleared prefixes go first: ClearPrefix already purged the writes it shadowed, so any
+	// write still under a cleared prefix was made after the clear and has to survive it.
+	for _, prefix := range base.clearedPrefixes {
+		if err := m.underlying.ClearPrefix(prefix); err != nil {
+			return err
+		}
+	}
+	for k, v := range base.writes {
+		m.cacheInvalidate(k)
+		if v == nil {
+			if err := m.underlying.Clear([]byte(k)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := m.underlying.Set([]byte(k), *v); err != nil {
+			return err
+		}
+	}
+	for keyToChild, deleted := range base.deletedChildren {
+		if !deleted {
+			continue
+		}
+		if err := m.underlying.DeleteChildStorage([]byte(keyToChild)); err != nil {
+			return err
+		}
+	}
+	for keyToChild, prefixes := range base.clearedChildPrefixes {
+		for _, prefix := range prefixes {
+			if err := m.underlying.ClearPrefixInChild([]byte(keyToChild), prefix); err != nil {
+				return err
+			}
+		}
+	}
+	for keyToChild, writes := range base.childWrites {
+		for k, v := range writes {
+			if v == nil {
+				if err := m.underlying.ClearChildStorage([]byte(keyToChild), []byte(k)); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := m.underlying.SetChildStorage([]byte(keyToChild), []byte(k), *v); err != nil {
+				return err
+			}
+		}
+	}
+
+	m.frames[0] = newWriteFrame()
+	return nil
+}
+
+// Root flushes the base write frame via Commit, then returns the underlying Storage's root.
+func (m *MemCachedStorage) Root() (common.Hash, error) {
+	if err := m.Commit(); err != nil {
+		return common.Hash{}, err
+	}
+	return m.underlying.Root()
+}
+
+// BeginStorageTransaction pushes a new write frame onto the stack.
+func (m *MemCachedStorage) BeginStorageTransaction() {
+	m.frames = append(m.frames, newWriteFrame())
+}
+
+// CommitStorageTransaction merges the top write frame into the one below it. It's a no-op if
+// no transaction is open (just the base frame remains).
+func (m *MemCachedStorage) CommitStorageTransaction() {
+	if len(m.frames) <= 1 {
+		return
+	}
+
+	top := m.frames[len(m.frames)-1]
+	m.frames = m.frames[:len(m.frames)-1]
+	parent := m.top()
+
+	// A cleared prefix merged up from top must also purge keys the parent frame already
+	// holds in its own writes map: Get checks a frame's writes before its clearedPrefixes, so
+	// a stale parent entry would otherwise keep reading back instead of being shadowed.
+	for _, p := range top.clearedPrefixes {
+		for k := range parent.writes {
+			if bytes.HasPrefix([]byte(k), p) {
+				delete(parent.writes, k)
+			}
+		}
+	}
+	for k, v := range top.writes {
+		parent.writes[k] = v
+	}
+	parent.clearedPrefixes = append(parent.clearedPrefixes, top.clearedPrefixes...)
+	for keyToChild, prefixes := range top.clearedChildPrefixes {
+		dst := parent.childWrites[keyToChild]
+		for _, p := range prefixes {
+			for k := range dst {
+				if bytes.HasPrefix([]byte(k), p) {
+					delete(dst, k)
+				}
+			}
+		}
+		parent.clearedChildPrefixes[keyToChild] = append(parent.clearedChildPrefixes[keyToChild], prefixes...)
+	}
+	for keyToChild, writes := range top.childWrites {
+		if parent.childWrites[keyToChild] == nil {
+			parent.childWrites[keyToChild] = make(map[string]*[]byte)
+		}
+		for k, v := range writes {
+			parent.childWrites[keyToChild][k] = v
+		}
+	}
+	for keyToChild, deleted := range top.deletedChildren {
+		parent.deletedChildren[keyToChild] = deleted
+	}
+}
+
+// RollbackStorageTransaction discards the top write frame. It's a no-op if no transaction is
+// open (just the base frame remains).
+func (m *MemCachedStorage) RollbackStorageTransaction() {
+	if len(m.frames) <= 1 {
+		return
+	}
+	m.frames = m.frames[:len(m.frames)-1]
+}
+
+func (m *MemCachedStorage) childWrites(keyToChild []byte) map[string]*[]byte {
+	top := m.top()
+	if top.childWrites[string(keyToChild)] == nil {
+		top.childWrites[string(keyToChild)] = make(map[string]*[]byte)
+	}
+	return top.childWrites[string(keyToChild)]
+}
+
+// GetChildStorage returns the value stored under key in the child trie rooted at keyToChild,
+// consulting the write frames before falling through to the underlying Storage.
+func (m *MemCachedStorage) GetChildStorage(keyToChild, key []byte) ([]byte, error) {
+	for i := len(m.frames) - 1; i >= 0; i-- {
+		f := m.frames[i]
+		if writes, ok := f.childWrites[string(keyToChild)]; ok {
+			if v, ok := writes[string(key)]; ok {
+				if v == nil {
+					return nil, nil
+				}
+				return *v, nil
+			}
+		}
+		for _, p := range f.clearedChildPrefixes[string(keyToChild)] {
+			if bytes.HasPrefix(key, p) {
+				return nil, nil
+			}
+		}
+		if f.deletedChildren[string(keyToChild)] {
+			return nil, nil
+		}
+	}
+	return m.underlying.GetChildStorage(keyToChild, key)
+}
+
+// SetChildStorage buffers value under key in the child trie rooted at keyToChild.
+func (m *MemCachedStorage) SetChildStorage(keyToChild, key, value []byte) error {
+	v := append([]byte(nil), value...)
+	m.childWrites(keyToChild)[string(key)] = &v
+	return nil
+}
+
+// ClearChildStorage buffers key's deletion in the child trie rooted at keyToChild.
+func (m *MemCachedStorage) ClearChildStorage(keyToChild, key []byte) error {
+	m.childWrites(keyToChild)[string(key)] = nil
+	return nil
+}
+
+// ClearPrefixInChild buffers prefix's deletion in the child trie rooted at keyToChild.
+func (m *MemCachedStorage) ClearPrefixInChild(keyToChild, prefix []byte) error {
+	writes := m.childWrites(keyToChild)
+	for k := range writes {
+		if bytes.HasPrefix([]byte(k), prefix) {
+			delete(writes, k)
+		}
+	}
+	top := m.top()
+	top.clearedChildPrefixes[string(keyToChild)] = append(top.clearedChildPrefixes[string(keyToChild)], append([]byte(nil), prefix...))
+	return nil
+}
+
+// ClearPrefixInChildWithLimit is ClearPrefixLimit, scoped to the child trie rooted at
+// keyToChild.
+func (m *MemCachedStorage) ClearPrefixInChildWithLimit(keyToChild, prefix []byte, limit uint32) (uint32, bool, error) {
+	var deleted uint32
+	key := append([]byte(nil), prefix...)
+
+	for deleted < limit {
+		next, err := m.GetChildNextKey(keyToChild, key)
+		if err != nil {
+			return deleted, false, err
+		}
+		if next == nil || !bytes.HasPrefix(next, prefix) {
+			return deleted, true, nil
+		}
+		if err := m.ClearChildStorage(keyToChild, next); err != nil {
+			return deleted, false, err
+		}
+		deleted++
+		key = next
+	}
+
+	next, err := m.GetChildNextKey(keyToChild, key)
+	if err != nil {
+		return deleted, false, err
+	}
+	return deleted, next == nil || !bytes.HasPrefix(next, prefix), nil
+}
+
+// GetChildNextKey returns the lexicographically smallest key greater than key in the child
+// trie rooted at keyToChild, consulting pending child writes before falling through to the
+// underlying Storage.
+func (m *MemCachedStorage) GetChildNextKey(keyToChild, key []byte) ([]byte, error) {
+	next, err := m.underlying.GetChildNextKey(keyToChild, key)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range m.frames {
+		for k, v := range f.childWrites[string(keyToChild)] {
+			if v == nil {
+				continue
+			}
+			kb := []byte(k)
+			if bytes.Compare(kb, key) > 0 && (next == nil || bytes.Compare(kb, next) < 0) {
+				next = kb
+			}
+		}
+	}
+
+	return next, nil
+}
+
+// ChildStorageRoot returns the hash of the child trie rooted at keyToChild. Like Root, it's
+// only meaningful once pending writes have been flushed via Commit.
+func (m *MemCachedStorage) ChildStorageRoot(keyToChild []byte) (common.Hash, error) {
+	return m.underlying.ChildStorageRoot(keyToChild)
+}
+
+// DeleteChildStorage buffers the removal of the entire child trie rooted at keyToChild.
+func (m *MemCachedStorage) DeleteChildStorage(keyToChild []byte) error {
+	top := m.top()
+	top.deletedChildren[string(keyToChild)] = true
+	delete(top.childWrites, string(keyToChild))
+	delete(top.clearedChildPrefixes, string(keyToChild))
+	return nil
+}
+
+func compactUint32(n uint32) []byte {
+	switch {
+	case n < 1<<6:
+		return []byte{byte(n << 2)}
+	case n < 1<<14:
+		v := (n << 2) | 1
+		return []byte{byte(v), byte(v >> 8)}
+	case n < 1<<30:
+		v := (n << 2) | 2
+		return []byte{byte(v), byte(v >> 8), byte(v >> 16), byte(v >> 24)}
+	default:
+		b := []byte{byte(n), byte(n >> 8), byte(n >> 16), byte(n >> 24)}
+		return append([]byte{byte(len(b)-4)<<2 | 3}, b...)
+	}
+}
+
+func decodeCompactUint32(b []byte) (n uint32, prefixLen int, err error) {
+	if len(b) == 0 {
+		return 0, 0, errors.New("empty compact length prefix")
+	}
+
+	switch b[0] & 0b11 {
+	case 0:
+		return uint32(b[0] >> 2), 1, nil
+	case 1:
+		if len(b) < 2 {
+			return 0, 0, errors.New("short compact length prefix")
+		}
+		return (uint32(b[0]) | uint32(b[1])<<8) >> 2, 2, nil
+	case 2:
+		if len(b) < 4 {
+			return 0, 0, errors.New("short compact length prefix")
+		}
+		return (uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24) >> 2, 4, nil
+	default:
+		if len(b) < 5 {
+			return 0, 0, errors.New("short compact length prefix")
+		}
+		return uint32(b[1]) | uint32(b[2])<<8 | uint32(b[3])<<16 | uint32(b[4])<<24, 5, nil
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMemCachedStorage() *MemCachedStorage {
+	return New(runtime.NewTrieState(trie.NewEmptyTrie()), Config{})
+}
+
+func TestMemCachedStorage_SetGetBuffered(t *testing.T) {
+	m := newTestMemCachedStorage()
+
+	require.NoError(t, m.Set([]byte("a"), []byte("1")))
+
+	v, err := m.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+}
+
+func TestMemCachedStorage_CommitFlushesToUnderlying(t *testing.T) {
+	underlying := runtime.NewTrieState(trie.NewEmptyTrie())
+	m := New(underlying, Config{})
+
+	require.NoError(t, m.Set([]byte("a"), []byte("1")))
+
+	v, err := underlying.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Nil(t, v, "write should not reach the underlying storage before Commit")
+
+	require.NoError(t, m.Commit())
+
+	v, err = underlying.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+}
+
+func TestMemCachedStorage_ReadCacheServesRepeatedGets(t *testing.T) {
+	underlying := runtime.NewTrieState(trie.NewEmptyTrie())
+	require.NoError(t, underlying.Set([]byte("a"), []byte("1")))
+
+	m := New(underlying, Config{})
+
+	v, err := m.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+
+	// A change made directly against the underlying storage, bypassing the cache, should not
+	// be visible until the cache entry is invalidated: this confirms Get actually consulted
+	// the cache on the second call rather than going back to the underlying storage.
+	require.NoError(t, underlying.Set([]byte("a"), []byte("2")))
+
+	v, err = m.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+}
+
+func TestMemCachedStorage_SetInvalidatesCache(t *testing.T) {
+	m := newTestMemCachedStorage()
+
+	require.NoError(t, m.Set([]byte("a"), []byte("1")))
+	_, err := m.Get([]byte("a"))
+	require.NoError(t, err)
+
+	require.NoError(t, m.Set([]byte("a"), []byte("2")))
+
+	v, err := m.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("2"), v)
+}
+
+func TestMemCachedStorage_RollbackDiscardsNestedWrites(t *testing.T) {
+	m := newTestMemCachedStorage()
+	require.NoError(t, m.Set([]byte("a"), []byte("1")))
+
+	m.BeginStorageTransaction()
+	require.NoError(t, m.Set([]byte("a"), []byte("2")))
+
+	v, err := m.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("2"), v)
+
+	m.RollbackStorageTransaction()
+
+	v, err = m.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+}
+
+func TestMemCachedStorage_CommitTransactionMergesIntoParent(t *testing.T) {
+	m := newTestMemCachedStorage()
+
+	m.BeginStorageTransaction() // parent
+	m.BeginStorageTransaction() // nested
+
+	require.NoError(t, m.Set([]byte("a"), []byte("1")))
+	m.CommitStorageTransaction() // merges nested into parent, not the underlying storage
+
+	m.RollbackStorageTransaction() // discards the parent, and the nested commit along with it
+
+	v, err := m.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Nil(t, v)
+}
+
+func TestMemCachedStorage_ClearPrefixInNestedTransactionShadowsParentWrite(t *testing.T) {
+	m := newTestMemCachedStorage()
+
+	m.BeginStorageTransaction() // parent
+	require.NoError(t, m.Set([]byte("prefix:a"), []byte("2")))
+
+	m.BeginStorageTransaction() // nested
+	require.NoError(t, m.ClearPrefix([]byte("prefix:")))
+	m.CommitStorageTransaction() // merges the nested clearedPrefixes into the parent
+
+	v, err := m.Get([]byte("prefix:a"))
+	require.NoError(t, err)
+	require.Nil(t, v, "clear-prefix committed from a nested transaction must shadow a value the parent already set")
+
+	m.CommitStorageTransaction() // merges the parent into the base frame
+	v, err = m.Get([]byte("prefix:a"))
+	require.NoError(t, err)
+	require.Nil(t, v)
+}
+
+func TestMemCachedStorage_ClearPrefixOnUncachedUnderlyingKeyShadowsItBeforeCommit(t *testing.T) {
+	underlying := runtime.NewTrieState(trie.NewEmptyTrie())
+	require.NoError(t, underlying.Set([]byte("prefix:a"), []byte("1")))
+
+	m := New(underlying, Config{})
+
+	// "prefix:a" has never been Set, Clear'd, or Get through m, so it has no write-frame entry
+	// and no read-cache entry: ClearPrefix's only record of the clear is clearedPrefixes, which
+	// Get must consult directly rather than falling through to the underlying trie's stale value.
+	require.NoError(t, m.ClearPrefix([]byte("prefix:")))
+
+	v, err := m.Get([]byte("prefix:a"))
+	require.NoError(t, err)
+	require.Nil(t, v, "a cleared prefix must shadow an underlying key Get never cached, before Commit")
+}
+
+func TestMemCachedStorage_ClearPrefixLimit_PartialThenExhausted(t *testing.T) {
+	m := newTestMemCachedStorage()
+	require.NoError(t, m.Set([]byte("prefix:a"), []byte("1")))
+	require.NoError(t, m.Set([]byte("prefix:b"), []byte("2")))
+	require.NoError(t, m.Set([]byte("prefix:c"), []byte("3")))
+
+	deleted, allDeleted, err := m.ClearPrefixLimit([]byte("prefix:"), 2)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), deleted)
+	require.False(t, allDeleted)
+
+	deleted, allDeleted, err = m.ClearPrefixLimit([]byte("prefix:"), 2)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), deleted)
+	require.True(t, allDeleted)
+}
+
+func TestMemCachedStorage_ChildStorageRoundtrip(t *testing.T) {
+	m := newTestMemCachedStorage()
+	keyToChild := []byte("child")
+
+	require.NoError(t, m.SetChildStorage(keyToChild, []byte("a"), []byte("1")))
+
+	v, err := m.GetChildStorage(keyToChild, []byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+
+	require.NoError(t, m.ClearChildStorage(keyToChild, []byte("a")))
+
+	v, err = m.GetChildStorage(keyToChild, []byte("a"))
+	require.NoError(t, err)
+	require.Nil(t, v)
+}
+
+func TestMemCachedStorage_EvictsOldestBeyondCacheSize(t *testing.T) {
+	underlying := runtime.NewTrieState(trie.NewEmptyTrie())
+	require.NoError(t, underlying.Set([]byte("a"), []byte("1")))
+	require.NoError(t, underlying.Set([]byte("b"), []byte("2")))
+
+	m := New(underlying, Config{CacheSize: 1})
+
+	_, err := m.Get([]byte("a"))
+	require.NoError(t, err)
+	_, err = m.Get([]byte("b"))
+	require.NoError(t, err)
+
+	require.Len(t, m.cache, 1)
+	_, cached := m.cache["a"]
+	require.False(t, cached, "the least-recently-used entry should have been evicted")
+}
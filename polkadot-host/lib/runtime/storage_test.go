@@ -0,0 +1,244 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrieState_RollbackRevertsSetClearAndAppend(t *testing.T) {
+	s := NewTrieState(trie.NewEmptyTrie())
+
+	require.NoError(t, s.Set([]byte("a"), []byte("1")))
+	require.NoError(t, s.Set([]byte("b"), []byte("2")))
+
+	s.BeginStorageTransaction()
+	require.NoError(t, s.Set([]byte("a"), []byte("changed")))
+	require.NoError(t, s.Clear([]byte("b")))
+	require.NoError(t, s.Append([]byte("list"), []byte("x")))
+
+	v, err := s.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("changed"), v)
+
+	v, err = s.Get([]byte("b"))
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	s.RollbackStorageTransaction()
+
+	v, err = s.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+
+	v, err = s.Get([]byte("b"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("2"), v)
+
+	v, err = s.Get([]byte("list"))
+	require.NoError(t, err)
+	require.Nil(t, v)
+}
+
+func TestTrieState_CommitThenParentRollbackDiscardsEverything(t *testing.T) {
+	s := NewTrieState(trie.NewEmptyTrie())
+	require.NoError(t, s.Set([]byte("a"), []byte("1")))
+
+	s.BeginStorageTransaction() // parent
+	s.BeginStorageTransaction() // nested
+
+	require.NoError(t, s.Set([]byte("a"), []byte("2")))
+	s.CommitStorageTransaction() // merges the nested overlay into the parent, not the trie
+
+	v, err := s.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("2"), v)
+
+	s.RollbackStorageTransaction() // discards the parent, and the nested commit along with it
+
+	v, err = s.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+}
+
+func TestTrieState_ClearPrefixInNestedTransactionShadowsParentChange(t *testing.T) {
+	s := NewTrieState(trie.NewEmptyTrie())
+
+	s.BeginStorageTransaction() // parent
+	require.NoError(t, s.Set([]byte("prefix:a"), []byte("2")))
+
+	s.BeginStorageTransaction() // nested
+	require.NoError(t, s.ClearPrefix([]byte("prefix:")))
+	s.CommitStorageTransaction() // merges the nested clearedPrefixes into the parent
+
+	v, err := s.Get([]byte("prefix:a"))
+	require.NoError(t, err)
+	require.Nil(t, v, "clear-prefix committed from a nested transaction must shadow a value the parent already set")
+
+	s.CommitStorageTransaction() // merges the parent into the trie
+	v, err = s.Get([]byte("prefix:a"))
+	require.NoError(t, err)
+	require.Nil(t, v)
+}
+
+func TestTrieState_NestedTransactionsArbitraryDepth(t *testing.T) {
+	s := NewTrieState(trie.NewEmptyTrie())
+
+	for i := 0; i < 10; i++ {
+		s.BeginStorageTransaction()
+	}
+	require.Len(t, s.overlays, 10)
+
+	require.NoError(t, s.Set([]byte("k"), []byte("v")))
+
+	for i := 0; i < 10; i++ {
+		s.CommitStorageTransaction()
+	}
+	require.Len(t, s.overlays, 0)
+
+	v, err := s.Get([]byte("k"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("v"), v)
+}
+
+func TestTrieState_ChildStorageRollback(t *testing.T) {
+	s := NewTrieState(trie.NewEmptyTrie())
+	keyToChild := []byte("child")
+
+	require.NoError(t, s.SetChildStorage(keyToChild, []byte("a"), []byte("1")))
+
+	s.BeginStorageTransaction()
+	require.NoError(t, s.SetChildStorage(keyToChild, []byte("a"), []byte("2")))
+	require.NoError(t, s.ClearChildStorage(keyToChild, []byte("a")))
+
+	v, err := s.GetChildStorage(keyToChild, []byte("a"))
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	s.RollbackStorageTransaction()
+
+	v, err = s.GetChildStorage(keyToChild, []byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+}
+
+func TestTrieState_ClearPrefixLimit_ZeroDeletesNothing(t *testing.T) {
+	s := NewTrieState(trie.NewEmptyTrie())
+	require.NoError(t, s.Set([]byte("prefix:a"), []byte("1")))
+	require.NoError(t, s.Set([]byte("prefix:b"), []byte("2")))
+
+	deleted, allDeleted, err := s.ClearPrefixLimit([]byte("prefix:"), 0)
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), deleted)
+	require.False(t, allDeleted)
+
+	v, err := s.Get([]byte("prefix:a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+}
+
+func TestTrieState_ClearPrefixLimit_AboveSubtreeSizeClearsEverything(t *testing.T) {
+	s := NewTrieState(trie.NewEmptyTrie())
+	require.NoError(t, s.Set([]byte("prefix:a"), []byte("1")))
+	require.NoError(t, s.Set([]byte("prefix:b"), []byte("2")))
+	require.NoError(t, s.Set([]byte("prefix:c"), []byte("3")))
+	require.NoError(t, s.Set([]byte("other"), []byte("4")))
+
+	deleted, allDeleted, err := s.ClearPrefixLimit([]byte("prefix:"), 100)
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), deleted)
+	require.True(t, allDeleted)
+
+	for _, k := range []string{"prefix:a", "prefix:b", "prefix:c"} {
+		v, err := s.Get([]byte(k))
+		require.NoError(t, err)
+		require.Nil(t, v)
+	}
+
+	v, err := s.Get([]byte("other"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("4"), v)
+}
+
+func TestTrieState_ClearPrefixLimit_PartialDeletionContinuesAcrossCalls(t *testing.T) {
+	s := NewTrieState(trie.NewEmptyTrie())
+	require.NoError(t, s.Set([]byte("prefix:a"), []byte("1")))
+	require.NoError(t, s.Set([]byte("prefix:b"), []byte("2")))
+	require.NoError(t, s.Set([]byte("prefix:c"), []byte("3")))
+
+	deleted, allDeleted, err := s.ClearPrefixLimit([]byte("prefix:"), 2)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), deleted)
+	require.False(t, allDeleted)
+
+	v, err := s.Get([]byte("prefix:c"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("3"), v)
+
+	deleted, allDeleted, err = s.ClearPrefixLimit([]byte("prefix:"), 2)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), deleted)
+	require.True(t, allDeleted)
+
+	v, err = s.Get([]byte("prefix:c"))
+	require.NoError(t, err)
+	require.Nil(t, v)
+}
+
+// These Root tests check that Root/ChildStorageRoot respond to state changes the way a Merkle
+// root must, rather than comparing against known-good Substrate fixture bytes: this snapshot
+// has no runnable trie.Trie or network access to a real runtime to derive a golden vector from.
+func TestTrieState_RootReflectsState(t *testing.T) {
+	s := NewTrieState(trie.NewEmptyTrie())
+
+	emptyRoot, err := s.Root()
+	require.NoError(t, err)
+
+	require.NoError(t, s.Set([]byte("a"), []byte("1")))
+
+	root, err := s.Root()
+	require.NoError(t, err)
+	require.NotEqual(t, emptyRoot, root)
+
+	require.NoError(t, s.Clear([]byte("a")))
+
+	root, err = s.Root()
+	require.NoError(t, err)
+	require.Equal(t, emptyRoot, root)
+}
+
+func TestTrieState_ChildStorageRootUpdatesParentRoot(t *testing.T) {
+	s := NewTrieState(trie.NewEmptyTrie())
+	keyToChild := []byte("child")
+
+	parentRootBefore, err := s.Root()
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetChildStorage(keyToChild, []byte("a"), []byte("1")))
+
+	childRoot, err := s.ChildStorageRoot(keyToChild)
+	require.NoError(t, err)
+	require.NotEqual(t, common.Hash{}, childRoot)
+
+	parentRootAfter, err := s.Root()
+	require.NoError(t, err)
+	require.NotEqual(t, parentRootBefore, parentRootAfter)
+}
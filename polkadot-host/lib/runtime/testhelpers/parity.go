@@ -0,0 +1,58 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package testhelpers
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/stretchr/testify/require"
+)
+
+// ParityExports is the set of runtime calls RunParity cross-checks by default: read-only,
+// no-argument, and cheap enough to run once per backend inside a single test.
+var ParityExports = []string{
+	runtime.CoreVersion,
+	runtime.Metadata,
+}
+
+// RunParity instantiates fp under every backend in Interpreters with icfg, calls every export
+// in ParityExports against each, and fails t if any backend disagrees byte-for-byte with the
+// first one that ran a given export. This is the A/B coverage that lets a second interpreter
+// (wasmtime) be re-landed on equal footing with the long-standing default (wasmer): any
+// divergence surfaces here instead of downstream as a consensus-breaking bug.
+func RunParity(t *testing.T, fp string, icfg runtime.InstanceConfig) {
+	results := make(map[string][]byte, len(ParityExports))
+
+	for _, name := range Interpreters {
+		inst, err := NewInstance(name, fp, icfg)
+		require.NoError(t, err, "could not create %s instance", name)
+		defer inst.Stop()
+
+		for _, export := range ParityExports {
+			out, err := inst.Exec(export, []byte{})
+			require.NoError(t, err, "%s: %s failed", name, export)
+
+			want, seen := results[export]
+			if !seen {
+				results[export] = out
+				continue
+			}
+			require.Equal(t, want, out, "%s disagreed with the first interpreter on %s", name, export)
+		}
+	}
+}
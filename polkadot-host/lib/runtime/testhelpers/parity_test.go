@@ -0,0 +1,50 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package testhelpers
+
+import (
+	"path/filepath"
+	"testing"
+
+	database "github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	log "github.com/ChainSafe/log15"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpreterParity(t *testing.T) {
+	testRuntimeFilePath, testRuntimeURL := runtime.GetRuntimeVars(runtime.NODE_RUNTIME)
+	_, err := runtime.GetRuntimeBlob(testRuntimeFilePath, testRuntimeURL)
+	require.NoError(t, err, "could not get runtime %s", runtime.NODE_RUNTIME)
+
+	fp, err := filepath.Abs(testRuntimeFilePath)
+	require.NoError(t, err)
+
+	icfg := runtime.InstanceConfig{
+		Storage:  runtime.NewTestRuntimeStorage(t, nil),
+		Keystore: keystore.NewGenericKeystore("test"),
+		LogLvl:   log.LvlTrace,
+		NodeStorage: runtime.NodeStorage{
+			LocalStorage:      database.NewMemDatabase(),
+			PersistentStorage: database.NewMemDatabase(),
+		},
+		Network: new(runtime.TestRuntimeNetwork),
+	}
+
+	RunParity(t, fp, icfg)
+}
@@ -19,6 +19,7 @@ package runtime
 import (
 	"bytes"
 
+	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/keystore"
 	"github.com/ChainSafe/gossamer/lib/scale"
 
@@ -40,6 +41,70 @@ type NodeStorage struct {
 	PersistentStorage BasicStorage
 }
 
+// Offchain is implemented by backends that service the ext_offchain_*
+// host functions exposed to the runtime. kind distinguishes the PERSISTENT
+// and LOCAL storage partitions using the NodeStorageType values above.
+type Offchain interface {
+	LocalStorageGet(kind int32, key []byte) ([]byte, error)
+	LocalStorageSet(kind int32, key, value []byte) error
+	LocalStorageCompareAndSet(kind int32, key, oldValue, newValue []byte) (bool, error)
+	LocalStorageClear(kind int32, key []byte) error
+	SubmitTransaction(ext []byte) error
+	NetworkState() (common.NetworkState, error)
+	RandomSeed() [32]byte
+	IsValidator() bool
+}
+
+// HTTPOutcome is the status an in-flight offchain HTTP request reports to
+// ext_offchain_http_response_wait_version_1, mirroring Substrate's HttpRequestStatus enum.
+type HTTPOutcome byte
+
+const (
+	// HTTPOutcomeInvalid marks a request ID the backend has no record of.
+	HTTPOutcomeInvalid HTTPOutcome = iota
+	// HTTPOutcomeDeadlineReached marks a request still in flight when its deadline elapsed.
+	HTTPOutcomeDeadlineReached
+	// HTTPOutcomeIoError marks a request that failed at the transport level.
+	HTTPOutcomeIoError
+	// HTTPOutcomeFinished marks a request that completed; StatusCode carries the HTTP
+	// response status.
+	HTTPOutcomeFinished
+)
+
+// HTTPRequestStatus is one entry of the result ext_offchain_http_response_wait_version_1
+// reports, one per request ID it was asked about.
+type HTTPRequestStatus struct {
+	Outcome    HTTPOutcome
+	StatusCode uint16
+}
+
+// HTTPOffchain is implemented by Offchain backends that also service the offchain HTTP client
+// host functions (ext_offchain_http_*). It is kept separate from Offchain because not every
+// backend needs outbound HTTP (MemoryOffchainStore, for one, does not implement it); callers
+// type-assert for HTTPOffchain and report HTTPOutcomeIoError when a backend doesn't support it.
+type HTTPOffchain interface {
+	// HTTPRequestStart begins building a request for method and uri, returning the ID later
+	// calls use to refer to it.
+	HTTPRequestStart(method, uri string) (id uint16, err error)
+	// HTTPRequestAddHeader attaches a header to a request still being built.
+	HTTPRequestAddHeader(id uint16, name, value string) error
+	// HTTPRequestWriteBody appends chunk to the request body. A zero-length chunk (nil
+	// included) dispatches the request with the body written so far. deadline is a Unix millisecond
+	// timestamp past which the write gives up; nil means no deadline.
+	HTTPRequestWriteBody(id uint16, chunk []byte, deadline *uint64) error
+	// HTTPResponseWait blocks (up to deadline, a Unix millisecond timestamp; nil means no
+	// deadline) until every request in ids has a final status, and reports one
+	// HTTPRequestStatus per ID, in the same order.
+	HTTPResponseWait(ids []uint16, deadline *uint64) []HTTPRequestStatus
+	// HTTPResponseHeaders returns the response headers for a finished request as
+	// name/value byte-slice pairs.
+	HTTPResponseHeaders(id uint16) [][2][]byte
+	// HTTPResponseReadBody copies as much of the response body into buffer as fits,
+	// returning the number of bytes copied. deadline is a Unix millisecond timestamp past
+	// which the read gives up; nil means no deadline.
+	HTTPResponseReadBody(id uint16, buffer []byte, deadline *uint64) (int, error)
+}
+
 // InstanceConfig represents a runtime instance configuration
 type InstanceConfig struct {
 	Storage     Storage
@@ -49,8 +114,16 @@ type InstanceConfig struct {
 	NodeStorage NodeStorage
 	Network     BasicNetwork
 	Transaction TransactionState
+	Offchain    Offchain
+	// PoolSize is the number of pre-instantiated instances a wasmer.InstancePool holds
+	// ready for Acquire. Zero means DefaultPoolSize. Unused outside the wasmer backend.
+	PoolSize int
 }
 
+// DefaultPoolSize is the pool size a wasmer.InstancePool uses when InstanceConfig.PoolSize is
+// left at zero.
+const DefaultPoolSize = 4
+
 // Context is the context for the wasm interpreter's imported functions
 type Context struct {
 	Storage     Storage
@@ -60,6 +133,26 @@ type Context struct {
 	NodeStorage NodeStorage
 	Network     BasicNetwork
 	Transaction TransactionState
+
+	// Offchain backs the ext_offchain_* host functions. It may be nil, in which case
+	// those exports report failure/empty results rather than panicking.
+	Offchain Offchain
+
+	// Batch holds the in-flight signature batch verification scope, if any. It's non-nil
+	// between an ext_crypto_start_batch_verify_version_1 call and its matching
+	// ext_crypto_finish_batch_verify_version_1.
+	Batch *BatchVerifier
+
+	// CacheSize is a read cache bound for a storage.MemCachedStorage wrapping Storage, zero
+	// standing for storage.DefaultCacheSize. No code in this tree reads it: a
+	// MemCachedStorage takes its bound from the storage.Config passed to storage.New, and
+	// setting this field does not change it.
+	CacheSize int
+	// CacheEvictionPolicy is a read cache eviction policy for a storage.MemCachedStorage
+	// wrapping Storage, e.g. storage.EvictionPolicyLRU; empty stands for
+	// storage.EvictionPolicyLRU. Like CacheSize, nothing reads it, and a MemCachedStorage
+	// evicts least-recently-used entries whatever it is set to.
+	CacheEvictionPolicy string
 }
 
 // Version struct
@@ -117,6 +210,25 @@ func (v *VersionAPI) Decode(in []byte) error {
 	return nil
 }
 
+// InherentsCheck is the result of BlockBuilder_check_inherents, reporting
+// whether the inherents in a block are valid.
+type InherentsCheck struct {
+	Okay       bool
+	FatalError bool
+	Errors     []byte
+}
+
+// NewInherentsCheck scale-decodes the return value of BlockBuilder_check_inherents
+func NewInherentsCheck(in []byte) (*InherentsCheck, error) {
+	ic := new(InherentsCheck)
+	_, err := scale.Decode(in, ic)
+	if err != nil {
+		return nil, err
+	}
+
+	return ic, nil
+}
+
 // NewValidateTransactionError returns an error based on a return value from TaggedTransactionQueueValidateTransaction
 func NewValidateTransactionError(res []byte) error {
 	// confirm we have an error
@@ -0,0 +1,196 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmer
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"crypto/ed25519"
+	"unsafe"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+)
+
+//export ext_crypto_start_batch_verify_version_1
+func ext_crypto_start_batch_verify_version_1(context unsafe.Pointer) {
+	logger.Trace("[ext_crypto_start_batch_verify_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+
+	if err := newHostAPI(instanceContext).StartBatchVerify(); err != nil {
+		logger.Error("[ext_crypto_start_batch_verify_version_1]", "error", err)
+	}
+}
+
+//export ext_crypto_finish_batch_verify_version_1
+func ext_crypto_finish_batch_verify_version_1(context unsafe.Pointer) C.int32_t {
+	logger.Trace("[ext_crypto_finish_batch_verify_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+
+	ok, err := newHostAPI(instanceContext).FinishBatchVerify()
+	if err != nil {
+		logger.Error("[ext_crypto_finish_batch_verify_version_1]", "error", err)
+		return 0
+	}
+
+	if ok {
+		return 1
+	}
+	return 0
+}
+
+//export ext_crypto_ed25519_verify_version_1
+func ext_crypto_ed25519_verify_version_1(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, pubkey C.int32_t) C.int32_t {
+	logger.Trace("[ext_crypto_ed25519_verify_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	memory := instanceContext.Memory().Data()
+
+	sigData := memory[sig : int(sig)+ed25519.SignatureSize]
+	pubkeyData := memory[pubkey : int(pubkey)+ed25519.PublicKeySize]
+	msgData := asMemorySlice(instanceContext, msg)
+
+	if runtimeCtx.Batch != nil {
+		// The batch worker pool reads sigData/msgData/pubkeyData asynchronously, after this
+		// function - and the guest code that owns the wasm memory they point into - has
+		// already resumed; copy them out so the guest can't mutate its own verdict out from
+		// under the worker before it gets a chance to read them.
+		runtimeCtx.Batch.Enqueue(runtime.VerifyJob{
+			Scheme: runtime.Ed25519Scheme,
+			Sig:    copyBytes(sigData),
+			Msg:    copyBytes(msgData),
+			Pubkey: copyBytes(pubkeyData),
+		})
+		return 1
+	}
+
+	if runtime.VerifyEd25519Signature(sigData, msgData, pubkeyData) {
+		return 1
+	}
+	return 0
+}
+
+//export ext_crypto_sr25519_verify_version_1
+func ext_crypto_sr25519_verify_version_1(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, pubkey C.int32_t) C.int32_t {
+	logger.Trace("[ext_crypto_sr25519_verify_version_1] executing...")
+	return sr25519Verify(context, sig, msg, pubkey)
+}
+
+//export ext_crypto_sr25519_verify_version_2
+func ext_crypto_sr25519_verify_version_2(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, pubkey C.int32_t) C.int32_t {
+	logger.Trace("[ext_crypto_sr25519_verify_version_2] executing...")
+	return sr25519Verify(context, sig, msg, pubkey)
+}
+
+func sr25519Verify(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, pubkey C.int32_t) C.int32_t {
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	memory := instanceContext.Memory().Data()
+
+	sigData := memory[sig : int(sig)+64]
+	pubkeyData := memory[pubkey : int(pubkey)+32]
+	msgData := asMemorySlice(instanceContext, msg)
+
+	if runtimeCtx.Batch != nil {
+		// See the matching comment in ext_crypto_ed25519_verify_version_1: copy out of wasm
+		// memory before enqueueing, since the guest resumes before the worker pool dequeues.
+		runtimeCtx.Batch.Enqueue(runtime.VerifyJob{
+			Scheme: runtime.Sr25519Scheme,
+			Sig:    copyBytes(sigData),
+			Msg:    copyBytes(msgData),
+			Pubkey: copyBytes(pubkeyData),
+		})
+		return 1
+	}
+
+	if runtime.VerifySr25519Signature(sigData, msgData, pubkeyData) {
+		return 1
+	}
+	return 0
+}
+
+//export ext_crypto_ecdsa_verify_version_1
+func ext_crypto_ecdsa_verify_version_1(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, pubkey C.int32_t) C.int32_t {
+	logger.Trace("[ext_crypto_ecdsa_verify_version_1] executing...")
+	return ecdsaVerify(context, sig, msg, pubkey)
+}
+
+//export ext_crypto_ecdsa_verify_version_2
+func ext_crypto_ecdsa_verify_version_2(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, pubkey C.int32_t) C.int32_t {
+	logger.Trace("[ext_crypto_ecdsa_verify_version_2] executing...")
+	return ecdsaVerify(context, sig, msg, pubkey)
+}
+
+func ecdsaVerify(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, pubkey C.int32_t) C.int32_t {
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	memory := instanceContext.Memory().Data()
+
+	sigData := memory[sig : int(sig)+65]
+	pubkeyData := memory[pubkey : int(pubkey)+33]
+	msgData := asMemorySlice(instanceContext, msg)
+
+	if runtimeCtx.Batch != nil {
+		// See the matching comment in ext_crypto_ed25519_verify_version_1: copy out of wasm
+		// memory before enqueueing, since the guest resumes before the worker pool dequeues.
+		runtimeCtx.Batch.Enqueue(runtime.VerifyJob{
+			Scheme: runtime.EcdsaScheme,
+			Sig:    copyBytes(sigData),
+			Msg:    copyBytes(msgData),
+			Pubkey: copyBytes(pubkeyData),
+		})
+		return 1
+	}
+
+	if runtime.VerifyEcdsaSignature(sigData, msgData, pubkeyData) {
+		return 1
+	}
+	return 0
+}
+
+// copyBytes returns a fresh copy of b, rather than a slice sharing its backing array. It's used
+// before queueing a job onto a BatchVerifier, since b is otherwise a live view into wasm guest
+// memory that the guest is free to overwrite as soon as the enqueueing host function returns.
+func copyBytes(b []byte) []byte {
+	return append([]byte(nil), b...)
+}
+
+// ext_crypto_ecdsa_verify_prehashed_version_1 is not batched: BatchVerifier's queued jobs are
+// always hashed with blake2_256 before recovery, and the prehashed variant's whole point is
+// that the caller has already hashed msg some other way, so it verifies immediately.
+//
+//export ext_crypto_ecdsa_verify_prehashed_version_1
+func ext_crypto_ecdsa_verify_prehashed_version_1(context unsafe.Pointer, sig, msg, pubkey C.int32_t) C.int32_t {
+	logger.Trace("[ext_crypto_ecdsa_verify_prehashed_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	memory := instanceContext.Memory().Data()
+
+	sigData := memory[sig : int(sig)+65]
+	hashData := memory[msg : int(msg)+32]
+	pubkeyData := memory[pubkey : int(pubkey)+33]
+
+	if runtime.VerifyEcdsaSignaturePrehashed(sigData, hashData, pubkeyData) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,389 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmer
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"math"
+	"unsafe"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+)
+
+//export ext_storage_clear_prefix_version_1
+func ext_storage_clear_prefix_version_1(context unsafe.Pointer, prefixSpan C.int64_t) {
+	logger.Trace("[ext_storage_clear_prefix_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	prefix := asMemorySlice(instanceContext, prefixSpan)
+
+	if err := storage.ClearPrefix(prefix); err != nil {
+		logger.Error("[ext_storage_clear_prefix_version_1]", "error", err)
+	}
+}
+
+// ext_storage_clear_prefix_version_2 is ext_storage_clear_prefix_version_1 with an optional
+// deletion limit: limitSpan holds a SCALE-encoded Option<u32> that caps how many keys are
+// removed in one call, and the return value is a KillStorageResult recording how many keys
+// were actually deleted and whether the prefix was left fully cleared, in place of the
+// version_1 host function's void return.
+//
+//export ext_storage_clear_prefix_version_2
+func ext_storage_clear_prefix_version_2(context unsafe.Pointer, prefixSpan, limitSpan C.int64_t) C.int64_t {
+	logger.Trace("[ext_storage_clear_prefix_version_2] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	prefix := asMemorySlice(instanceContext, prefixSpan)
+
+	limit, limited, err := runtime.DecodeOptionalU32(asMemorySlice(instanceContext, limitSpan))
+	if err != nil {
+		logger.Error("[ext_storage_clear_prefix_version_2] failed to decode limit", "error", err)
+		return 0
+	}
+	if !limited {
+		limit = math.MaxUint32
+	}
+
+	deleted, allDeleted, err := storage.ClearPrefixLimit(prefix, limit)
+	if err != nil {
+		logger.Error("[ext_storage_clear_prefix_version_2]", "error", err)
+	}
+
+	resultSpan, err := toWasmMemory(instanceContext, runtime.EncodeKillStorageResult(allDeleted, deleted))
+	if err != nil {
+		logger.Error("[ext_storage_clear_prefix_version_2] failed to allocate", "error", err)
+		return 0
+	}
+
+	return C.int64_t(resultSpan)
+}
+
+//export ext_storage_commit_transaction_version_1
+func ext_storage_commit_transaction_version_1(context unsafe.Pointer) {
+	logger.Trace("[ext_storage_commit_transaction_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	storage.CommitStorageTransaction()
+}
+
+//export ext_storage_exists_version_1
+func ext_storage_exists_version_1(context unsafe.Pointer, keySpan C.int64_t) C.int32_t {
+	logger.Trace("[ext_storage_exists_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	key := asMemorySlice(instanceContext, keySpan)
+
+	value, err := storage.Get(key)
+	if err != nil {
+		logger.Error("[ext_storage_exists_version_1]", "error", err)
+		return 0
+	}
+
+	if value != nil {
+		return 1
+	}
+	return 0
+}
+
+//export ext_storage_read_version_1
+func ext_storage_read_version_1(context unsafe.Pointer, keySpan, bufferSpan C.int64_t, offset C.int32_t) C.int64_t {
+	logger.Trace("[ext_storage_read_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	key := asMemorySlice(instanceContext, keySpan)
+
+	value, err := storage.Get(key)
+	if err != nil {
+		logger.Error("[ext_storage_read_version_1]", "error", err)
+		return 0
+	}
+
+	readSpan, err := newHostAPI(instanceContext).ReadStorageValue(value, int64(bufferSpan), uint32(offset))
+	if err != nil {
+		logger.Error("[ext_storage_read_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return C.int64_t(readSpan)
+}
+
+//export ext_storage_rollback_transaction_version_1
+func ext_storage_rollback_transaction_version_1(context unsafe.Pointer) {
+	logger.Trace("[ext_storage_rollback_transaction_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	storage.RollbackStorageTransaction()
+}
+
+//export ext_storage_root_version_1
+func ext_storage_root_version_1(context unsafe.Pointer) C.int64_t {
+	logger.Trace("[ext_storage_root_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	root, err := storage.Root()
+	if err != nil {
+		logger.Error("[ext_storage_root_version_1]", "error", err)
+		return 0
+	}
+
+	rootSpan, err := toWasmMemory(instanceContext, root[:])
+	if err != nil {
+		logger.Error("[ext_storage_root_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return C.int64_t(rootSpan)
+}
+
+//export ext_storage_start_transaction_version_1
+func ext_storage_start_transaction_version_1(context unsafe.Pointer) {
+	logger.Trace("[ext_storage_start_transaction_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	storage.BeginStorageTransaction()
+}
+
+//export ext_default_child_storage_read_version_1
+func ext_default_child_storage_read_version_1(context unsafe.Pointer, childStorageKeySpan, keySpan, bufferSpan C.int64_t, offset C.int32_t) C.int64_t {
+	logger.Trace("[ext_default_child_storage_read_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	keyToChild := asMemorySlice(instanceContext, childStorageKeySpan)
+	key := asMemorySlice(instanceContext, keySpan)
+
+	value, err := storage.GetChildStorage(keyToChild, key)
+	if err != nil {
+		logger.Error("[ext_default_child_storage_read_version_1]", "error", err)
+		return 0
+	}
+
+	readSpan, err := newHostAPI(instanceContext).ReadStorageValue(value, int64(bufferSpan), uint32(offset))
+	if err != nil {
+		logger.Error("[ext_default_child_storage_read_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return C.int64_t(readSpan)
+}
+
+//export ext_default_child_storage_clear_version_1
+func ext_default_child_storage_clear_version_1(context unsafe.Pointer, childStorageKeySpan, keySpan C.int64_t) {
+	logger.Trace("[ext_default_child_storage_clear_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	keyToChild := asMemorySlice(instanceContext, childStorageKeySpan)
+	key := asMemorySlice(instanceContext, keySpan)
+
+	if err := storage.ClearChildStorage(keyToChild, key); err != nil {
+		logger.Error("[ext_default_child_storage_clear_version_1]", "error", err)
+	}
+}
+
+//export ext_default_child_storage_clear_prefix_version_1
+func ext_default_child_storage_clear_prefix_version_1(context unsafe.Pointer, childStorageKeySpan, prefixSpan C.int64_t) {
+	logger.Trace("[ext_default_child_storage_clear_prefix_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	keyToChild := asMemorySlice(instanceContext, childStorageKeySpan)
+	prefix := asMemorySlice(instanceContext, prefixSpan)
+
+	if err := storage.ClearPrefixInChild(keyToChild, prefix); err != nil {
+		logger.Error("[ext_default_child_storage_clear_prefix_version_1]", "error", err)
+	}
+}
+
+// ext_default_child_storage_clear_prefix_version_2 is ext_default_child_storage_clear_prefix_version_1
+// with an optional deletion limit and a KillStorageResult return value in place of the void
+// return; see ext_storage_clear_prefix_version_2 for the limit/result semantics, which are
+// identical here, just scoped to the child trie.
+//
+//export ext_default_child_storage_clear_prefix_version_2
+func ext_default_child_storage_clear_prefix_version_2(context unsafe.Pointer, childStorageKeySpan, prefixSpan, limitSpan C.int64_t) C.int64_t {
+	logger.Trace("[ext_default_child_storage_clear_prefix_version_2] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	keyToChild := asMemorySlice(instanceContext, childStorageKeySpan)
+	prefix := asMemorySlice(instanceContext, prefixSpan)
+
+	limit, limited, err := runtime.DecodeOptionalU32(asMemorySlice(instanceContext, limitSpan))
+	if err != nil {
+		logger.Error("[ext_default_child_storage_clear_prefix_version_2] failed to decode limit", "error", err)
+		return 0
+	}
+	if !limited {
+		limit = math.MaxUint32
+	}
+
+	deleted, allDeleted, err := storage.ClearPrefixInChildWithLimit(keyToChild, prefix, limit)
+	if err != nil {
+		logger.Error("[ext_default_child_storage_clear_prefix_version_2]", "error", err)
+	}
+
+	resultSpan, err := toWasmMemory(instanceContext, runtime.EncodeKillStorageResult(allDeleted, deleted))
+	if err != nil {
+		logger.Error("[ext_default_child_storage_clear_prefix_version_2] failed to allocate", "error", err)
+		return 0
+	}
+
+	return C.int64_t(resultSpan)
+}
+
+//export ext_default_child_storage_exists_version_1
+func ext_default_child_storage_exists_version_1(context unsafe.Pointer, childStorageKeySpan, keySpan C.int64_t) C.int32_t {
+	logger.Trace("[ext_default_child_storage_exists_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	keyToChild := asMemorySlice(instanceContext, childStorageKeySpan)
+	key := asMemorySlice(instanceContext, keySpan)
+
+	value, err := storage.GetChildStorage(keyToChild, key)
+	if err != nil {
+		logger.Error("[ext_default_child_storage_exists_version_1]", "error", err)
+		return 0
+	}
+
+	if value != nil {
+		return 1
+	}
+	return 0
+}
+
+//export ext_default_child_storage_get_version_1
+func ext_default_child_storage_get_version_1(context unsafe.Pointer, childStorageKeySpan, keySpan C.int64_t) C.int64_t {
+	logger.Trace("[ext_default_child_storage_get_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+
+	keyToChild := asMemorySlice(instanceContext, childStorageKeySpan)
+	key := asMemorySlice(instanceContext, keySpan)
+
+	valueSpan, err := newHostAPI(instanceContext).ChildStorageGet(keyToChild, key)
+	if err != nil {
+		logger.Error("[ext_default_child_storage_get_version_1]", "error", err)
+		return 0
+	}
+
+	return C.int64_t(valueSpan)
+}
+
+//export ext_default_child_storage_next_key_version_1
+func ext_default_child_storage_next_key_version_1(context unsafe.Pointer, childStorageKeySpan, keySpan C.int64_t) C.int64_t {
+	logger.Trace("[ext_default_child_storage_next_key_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	keyToChild := asMemorySlice(instanceContext, childStorageKeySpan)
+	key := asMemorySlice(instanceContext, keySpan)
+
+	next, err := storage.GetChildNextKey(keyToChild, key)
+	if err != nil {
+		logger.Error("[ext_default_child_storage_next_key_version_1]", "error", err)
+		return 0
+	}
+
+	nextSpan, err := toWasmMemoryOptional(instanceContext, next)
+	if err != nil {
+		logger.Error("[ext_default_child_storage_next_key_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return C.int64_t(nextSpan)
+}
+
+//export ext_default_child_storage_root_version_1
+func ext_default_child_storage_root_version_1(context unsafe.Pointer, childStorageKeySpan C.int64_t) C.int64_t {
+	logger.Trace("[ext_default_child_storage_root_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	keyToChild := asMemorySlice(instanceContext, childStorageKeySpan)
+
+	root, err := storage.ChildStorageRoot(keyToChild)
+	if err != nil {
+		logger.Error("[ext_default_child_storage_root_version_1]", "error", err)
+		return 0
+	}
+
+	rootSpan, err := toWasmMemory(instanceContext, root[:])
+	if err != nil {
+		logger.Error("[ext_default_child_storage_root_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return C.int64_t(rootSpan)
+}
+
+//export ext_default_child_storage_set_version_1
+func ext_default_child_storage_set_version_1(context unsafe.Pointer, childStorageKeySpan, keySpan, valueSpan C.int64_t) {
+	logger.Trace("[ext_default_child_storage_set_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+
+	keyToChild := asMemorySlice(instanceContext, childStorageKeySpan)
+	key := asMemorySlice(instanceContext, keySpan)
+	value := asMemorySlice(instanceContext, valueSpan)
+
+	if err := newHostAPI(instanceContext).ChildStorageSet(keyToChild, key, value); err != nil {
+		logger.Error("[ext_default_child_storage_set_version_1]", "error", err)
+	}
+}
+
+//export ext_default_child_storage_storage_kill_version_1
+func ext_default_child_storage_storage_kill_version_1(context unsafe.Pointer, childStorageKeySpan C.int64_t) {
+	logger.Trace("[ext_default_child_storage_storage_kill_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	keyToChild := asMemorySlice(instanceContext, childStorageKeySpan)
+
+	if err := storage.DeleteChildStorage(keyToChild); err != nil {
+		logger.Error("[ext_default_child_storage_storage_kill_version_1]", "error", err)
+	}
+}
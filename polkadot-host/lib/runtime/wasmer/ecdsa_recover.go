@@ -0,0 +1,70 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmer
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+)
+
+//export ext_crypto_secp256k1_ecdsa_recover_version_1
+func ext_crypto_secp256k1_ecdsa_recover_version_1(context unsafe.Pointer, sig, msg C.int32_t) C.int64_t {
+	logger.Trace("[ext_crypto_secp256k1_ecdsa_recover_version_1] executing...")
+	return secp256k1Recover(context, sig, msg, false)
+}
+
+//export ext_crypto_secp256k1_ecdsa_recover_compressed_version_1
+func ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(context unsafe.Pointer, sig, msg C.int32_t) C.int64_t {
+	logger.Trace("[ext_crypto_secp256k1_ecdsa_recover_compressed_version_1] executing...")
+	return secp256k1Recover(context, sig, msg, true)
+}
+
+// secp256k1Recover reads the 65-byte compact signature at sig and the 32-byte message hash
+// at msg, recovers the public key, and writes back a SCALE-encoded Result<[u8;N], EcdsaVerifyError>
+// where N is 64 (uncompressed, 0x04 prefix dropped) or 33 (compressed).
+func secp256k1Recover(context unsafe.Pointer, sig, msg C.int32_t, compressed bool) C.int64_t {
+	instanceContext := wasm.IntoInstanceContext(context)
+	memory := instanceContext.Memory().Data()
+
+	sigData := memory[sig : int(sig)+65]
+	msgData := memory[msg : int(msg)+32]
+
+	pubkey, recoverErr := runtime.RecoverSecp256k1PublicKey(sigData, msgData)
+
+	var enc []byte
+	if recoverErr != nil {
+		enc = []byte{1, byte(*recoverErr)}
+	} else if compressed {
+		enc = append([]byte{0}, runtime.CompressSecp256k1PublicKey(pubkey)...)
+	} else {
+		enc = append([]byte{0}, pubkey[1:]...)
+	}
+
+	out, err := toWasmMemory(instanceContext, enc)
+	if err != nil {
+		logger.Error("[ext_crypto_secp256k1_ecdsa_recover_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return C.int64_t(out)
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common/optional"
 	"github.com/ChainSafe/gossamer/lib/runtime"
 	"github.com/ChainSafe/gossamer/lib/scale"
 	"github.com/ChainSafe/gossamer/lib/transaction"
@@ -150,6 +151,116 @@ func (in *LegacyInstance) ExecuteBlock(block *types.Block) ([]byte, error) {
 	return in.exec(runtime.CoreExecuteBlock, bdEnc)
 }
 
+// CheckInherents calls runtime function BlockBuilder_check_inherents
+func (in *LegacyInstance) CheckInherents(block *types.Block, data []byte) (*runtime.InherentsCheck, error) {
+	// copy block since we're going to modify it
+	b := block.DeepCopy()
+
+	b.Header.Digest = [][]byte{} // TODO: remove only seal digest
+	bdEnc, err := b.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := in.exec(runtime.BlockBuilderCheckInherents, append(bdEnc, data...))
+	if err != nil {
+		return nil, err
+	}
+
+	return runtime.NewInherentsCheck(ret)
+}
+
+// RandomSeed calls runtime function Core_random_seed
+func (in *LegacyInstance) RandomSeed() ([32]byte, error) {
+	var seed [32]byte
+
+	ret, err := in.exec(runtime.CoreRandomSeed, []byte{})
+	if err != nil {
+		return seed, err
+	}
+
+	copy(seed[:], ret)
+	return seed, nil
+}
+
+// OffchainWorker calls runtime function OffchainWorkerApi_offchain_worker with the block header
+func (in *LegacyInstance) OffchainWorker(header *types.Header) error {
+	encodedHeader, err := scale.Encode(header)
+	if err != nil {
+		return fmt.Errorf("cannot encode header: %w", err)
+	}
+
+	_, err = in.exec(runtime.OffchainWorkerAPIOffchainWorker, encodedHeader)
+	return err
+}
+
+// GenerateSessionKeys calls runtime function SessionKeys_generate_session_keys and returns the
+// SCALE-encoded public keys. If seed is nil, the runtime generates its own random seed.
+func (in *LegacyInstance) GenerateSessionKeys(seed *[]byte) ([]byte, error) {
+	var opt *optional.Bytes
+	if seed == nil {
+		opt = optional.NewBytes(false, nil)
+	} else {
+		opt = optional.NewBytes(true, *seed)
+	}
+
+	encodedSeed, err := opt.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode seed: %w", err)
+	}
+
+	return in.exec(runtime.SessionKeysGenerateSessionKeys, encodedSeed)
+}
+
+// BabeGenerateKeyOwnershipProof calls runtime function
+// BabeApi_generate_key_ownership_proof for the authority identified by authorityID at slot. A
+// nil, zero-length result means the runtime has no proof for that authority (it may no longer
+// be in the active set); that is not itself an error.
+func (in *LegacyInstance) BabeGenerateKeyOwnershipProof(slot uint64, authorityID [32]byte) ([]byte, error) {
+	encodedSlot, err := scale.Encode(slot)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode slot: %w", err)
+	}
+
+	ret, err := in.exec(runtime.BabeAPIGenerateKeyOwnershipProof, append(encodedSlot, authorityID[:]...))
+	if err != nil {
+		return nil, err
+	}
+
+	var proof *optional.Bytes
+	_, err = scale.Decode(ret, &proof)
+	if err != nil {
+		return nil, err
+	}
+
+	if !proof.Exists() {
+		return nil, nil
+	}
+	return proof.Value(), nil
+}
+
+// BabeSubmitReportEquivocationUnsignedExtrinsic calls runtime function
+// BabeApi_submit_report_equivocation_unsigned_extrinsic, which builds and imports an unsigned
+// extrinsic reporting the given equivocation into the runtime's transaction pool.
+func (in *LegacyInstance) BabeSubmitReportEquivocationUnsignedExtrinsic(equivocationProof, keyOwnershipProof []byte) error {
+	_, err := in.exec(runtime.BabeAPISubmitReportEquivocationUnsignedExtrinsic,
+		append(equivocationProof, keyOwnershipProof...))
+	return err
+}
+
+// PaymentQueryInfo calls runtime function TransactionPaymentApi_query_info, which computes the
+// weight, dispatch class, and fee the runtime would charge to include ext in a block.
+func (in *LegacyInstance) PaymentQueryInfo(ext types.Extrinsic) (*types.RuntimeDispatchInfo, error) {
+	ret, err := in.exec(runtime.TransactionPaymentAPIQueryInfo, ext)
+	if err != nil {
+		return nil, err
+	}
+
+	info := new(types.RuntimeDispatchInfo)
+	_, err = scale.Decode(ret, info)
+	return info, err
+}
+
 // ValidateTransaction runs the extrinsic through runtime function TaggedTransactionQueue_validate_transaction and returns *Validity
 func (in *Instance) ValidateTransaction(e types.Extrinsic) (*transaction.Validity, error) {
 	return in.inst.ValidateTransaction(e)
@@ -200,7 +311,38 @@ func (in *Instance) ExecuteBlock(block *types.Block) ([]byte, error) {
 	return in.inst.ExecuteBlock(block)
 }
 
-func (in *Instance) CheckInherents()      {} //nolint
-func (in *Instance) RandomSeed()          {} //nolint
-func (in *Instance) OffchainWorker()      {} //nolint
-func (in *Instance) GenerateSessionKeys() {} //nolint
+// CheckInherents calls runtime function BlockBuilder_check_inherents
+func (in *Instance) CheckInherents(block *types.Block, data []byte) (*runtime.InherentsCheck, error) {
+	return in.inst.CheckInherents(block, data)
+}
+
+// RandomSeed calls runtime function Core_random_seed
+func (in *Instance) RandomSeed() ([32]byte, error) {
+	return in.inst.RandomSeed()
+}
+
+// OffchainWorker calls runtime function OffchainWorkerApi_offchain_worker with the block header
+func (in *Instance) OffchainWorker(header *types.Header) error {
+	return in.inst.OffchainWorker(header)
+}
+
+// GenerateSessionKeys calls runtime function SessionKeys_generate_session_keys
+func (in *Instance) GenerateSessionKeys(seed *[]byte) ([]byte, error) {
+	return in.inst.GenerateSessionKeys(seed)
+}
+
+// BabeGenerateKeyOwnershipProof calls runtime function BabeApi_generate_key_ownership_proof
+func (in *Instance) BabeGenerateKeyOwnershipProof(slot uint64, authorityID [32]byte) ([]byte, error) {
+	return in.inst.BabeGenerateKeyOwnershipProof(slot, authorityID)
+}
+
+// BabeSubmitReportEquivocationUnsignedExtrinsic calls runtime function
+// BabeApi_submit_report_equivocation_unsigned_extrinsic
+func (in *Instance) BabeSubmitReportEquivocationUnsignedExtrinsic(equivocationProof, keyOwnershipProof []byte) error {
+	return in.inst.BabeSubmitReportEquivocationUnsignedExtrinsic(equivocationProof, keyOwnershipProof)
+}
+
+// PaymentQueryInfo calls runtime function TransactionPaymentApi_query_info
+func (in *Instance) PaymentQueryInfo(ext types.Extrinsic) (*types.RuntimeDispatchInfo, error) {
+	return in.inst.PaymentQueryInfo(ext)
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmer
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstance_RandomSeed(t *testing.T) {
+	instance := NewTestInstance(t, runtime.NODE_RUNTIME)
+
+	seed, err := instance.RandomSeed()
+	require.NoError(t, err)
+	require.NotEqual(t, [32]byte{}, seed)
+}
+
+func TestInstance_OffchainWorker(t *testing.T) {
+	instance := NewTestInstance(t, runtime.NODE_RUNTIME)
+
+	header := &types.Header{
+		Number: big.NewInt(1),
+	}
+	err := instance.OffchainWorker(header)
+	require.NoError(t, err)
+}
+
+func TestInstance_CheckInherents(t *testing.T) {
+	instance := NewTestInstance(t, runtime.NODE_RUNTIME)
+
+	header := &types.Header{
+		Number: big.NewInt(1),
+	}
+	err := instance.InitializeBlock(header)
+	require.NoError(t, err)
+
+	inherentData, err := instance.InherentExtrinsics([]byte{})
+	require.NoError(t, err)
+
+	finalized, err := instance.FinalizeBlock()
+	require.NoError(t, err)
+
+	check, err := instance.CheckInherents(&types.Block{Header: finalized}, inherentData)
+	require.NoError(t, err)
+	require.True(t, check.Okay)
+}
+
+func TestInstance_GenerateSessionKeys(t *testing.T) {
+	instance := NewTestInstance(t, runtime.NODE_RUNTIME)
+
+	keys, err := instance.GenerateSessionKeys(nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, keys)
+}
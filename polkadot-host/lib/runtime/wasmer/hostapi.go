@@ -0,0 +1,51 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmer
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"github.com/ChainSafe/gossamer/lib/runtime"
+
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+)
+
+// instanceMemory adapts a wasmer instance's linear memory to runtime.Memory, so the shared
+// runtime.HostAPI can read and write guest bytes without depending on wasmer's own types.
+type instanceMemory struct {
+	context wasm.InstanceContext
+}
+
+func (m instanceMemory) Data() []byte {
+	return m.context.Memory().Data()
+}
+
+func (m instanceMemory) Length() uint32 {
+	return m.context.Memory().Length()
+}
+
+// newHostAPI builds the runtime.HostAPI for a single host function call, wiring up the
+// instance's memory and the *runtime.Context wasmer stashed on it as instance data.
+//
+// lib/runtime/life doesn't vendor this package yet; once it lands, it should grow an
+// equivalent adapter here that unpacks its own calling convention and builds a HostAPI the
+// same way, rather than re-implementing these host functions a second time.
+func newHostAPI(context wasm.InstanceContext) *runtime.HostAPI {
+	ctx := context.Data().(*runtime.Context)
+	return runtime.NewHostAPI(instanceMemory{context}, ctx.Allocator, ctx)
+}
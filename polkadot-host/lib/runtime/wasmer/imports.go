@@ -58,6 +58,7 @@ package wasmer
 // extern void ext_default_child_storage_set_version_1(void *context, int64_t a, int64_t b, int64_t c);
 // extern void ext_default_child_storage_storage_kill_version_1(void *context, int64_t a);
 // extern void ext_default_child_storage_clear_prefix_version_1(void *context, int64_t a, int64_t b);
+// extern int64_t ext_default_child_storage_clear_prefix_version_2(void *context, int64_t a, int64_t b, int64_t c);
 // extern int32_t ext_default_child_storage_exists_version_1(void *context, int64_t a, int64_t b);
 //
 // extern void ext_allocator_free_version_1(void *context, int32_t a);
@@ -76,14 +77,17 @@ package wasmer
 // extern int32_t ext_offchain_local_storage_compare_and_set_version_1(void *context, int32_t a, int64_t b, int64_t c, int64_t d);
 // extern int64_t ext_offchain_local_storage_get_version_1(void *context, int32_t a, int64_t b);
 // extern void ext_offchain_local_storage_set_version_1(void *context, int32_t a, int64_t b, int64_t c);
+// extern void ext_offchain_local_storage_clear_version_1(void *context, int32_t a, int64_t b);
 // extern int64_t ext_offchain_network_state_version_1(void *context);
 // extern int32_t ext_offchain_random_seed_version_1(void *context);
 // extern int64_t ext_offchain_submit_transaction_version_1(void *context, int64_t a);
+// extern int64_t ext_offchain_timestamp_version_1(void *context);
 //
 // extern void ext_storage_append_version_1(void *context, int64_t a, int64_t b);
 // extern int64_t ext_storage_changes_root_version_1(void *context, int64_t a);
 // extern void ext_storage_clear_version_1(void *context, int64_t a);
 // extern void ext_storage_clear_prefix_version_1(void *context, int64_t a);
+// extern int64_t ext_storage_clear_prefix_version_2(void *context, int64_t a, int64_t b);
 // extern void ext_storage_commit_transaction_version_1(void *context);
 // extern int32_t ext_storage_exists_version_1(void *context, int64_t a);
 // extern int64_t ext_storage_get_version_1(void *context, int64_t a);
@@ -96,13 +100,12 @@ package wasmer
 import "C"
 
 import (
-	"errors"
 	"fmt"
 	"unsafe"
 
 	"github.com/ChainSafe/gossamer/lib/common"
-	"github.com/ChainSafe/gossamer/lib/common/optional"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/scale"
 	"github.com/ChainSafe/gossamer/lib/trie"
 
 	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
@@ -133,45 +136,7 @@ func ext_logging_log_version_1(context unsafe.Pointer, level C.int32_t, targetDa
 	}
 }
 
-//export ext_sandbox_instance_teardown_version_1
-func ext_sandbox_instance_teardown_version_1(context unsafe.Pointer, a C.int32_t) {
-	logger.Trace("[ext_sandbox_instance_teardown_version_1] executing...")
-}
-
-//export ext_sandbox_instantiate_version_1
-func ext_sandbox_instantiate_version_1(context unsafe.Pointer, a C.int32_t, x, y C.int64_t, z C.int32_t) C.int32_t {
-	logger.Trace("[ext_sandbox_instantiate_version_1] executing...")
-	return 0
-}
-
-//export ext_sandbox_invoke_version_1
-func ext_sandbox_invoke_version_1(context unsafe.Pointer, a C.int32_t, x, y C.int64_t, z, d, e C.int32_t) C.int32_t {
-	logger.Trace("[ext_sandbox_invoke_version_1] executing...")
-	return 0
-}
-
-//export ext_sandbox_memory_get_version_1
-func ext_sandbox_memory_get_version_1(context unsafe.Pointer, a, z, d, e C.int32_t) C.int32_t {
-	logger.Trace("[ext_sandbox_memory_get_version_1] executing...")
-	return 0
-}
-
-//export ext_sandbox_memory_new_version_1
-func ext_sandbox_memory_new_version_1(context unsafe.Pointer, a, z C.int32_t) C.int32_t {
-	logger.Trace("[ext_sandbox_memory_new_version_1] executing...")
-	return 0
-}
-
-//export ext_sandbox_memory_set_version_1
-func ext_sandbox_memory_set_version_1(context unsafe.Pointer, a, z, d, e C.int32_t) C.int32_t {
-	logger.Trace("[ext_sandbox_memory_set_version_1] executing...")
-	return 0
-}
-
-//export ext_sandbox_memory_teardown_version_1
-func ext_sandbox_memory_teardown_version_1(context unsafe.Pointer, a C.int32_t) {
-	logger.Trace("[ext_sandbox_memory_teardown_version_1] executing...")
-}
+// ext_sandbox_* exports are implemented in sandbox.go
 
 //export ext_crypto_ed25519_generate_version_1
 func ext_crypto_ed25519_generate_version_1(context unsafe.Pointer, a C.int32_t, z C.int64_t) C.int32_t {
@@ -191,29 +156,7 @@ func ext_crypto_ed25519_sign_version_1(context unsafe.Pointer, a, z C.int32_t, y
 	return 0
 }
 
-//export ext_crypto_ed25519_verify_version_1
-func ext_crypto_ed25519_verify_version_1(context unsafe.Pointer, a C.int32_t, z C.int64_t, y C.int32_t) C.int32_t {
-	logger.Trace("[ext_crypto_ed25519_verify_version_1] executing...")
-	return 0
-}
-
-//export ext_crypto_finish_batch_verify_version_1
-func ext_crypto_finish_batch_verify_version_1(context unsafe.Pointer) C.int32_t {
-	logger.Trace("[ext_crypto_finish_batch_verify_version_1] executing...")
-	return 0
-}
-
-//export ext_crypto_secp256k1_ecdsa_recover_version_1
-func ext_crypto_secp256k1_ecdsa_recover_version_1(context unsafe.Pointer, a, z C.int32_t) C.int64_t {
-	logger.Trace("[ext_crypto_secp256k1_ecdsa_recover_version_1] executing...")
-	return 0
-}
-
-//export ext_crypto_secp256k1_ecdsa_recover_compressed_version_1
-func ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(context unsafe.Pointer, a, z C.int32_t) C.int64_t {
-	logger.Trace("[ext_crypto_secp256k1_ecdsa_recover_compressed_version_1] executing...")
-	return 0
-}
+// ext_crypto_secp256k1_ecdsa_recover_* exports are implemented in ecdsa_recover.go
 
 //export ext_crypto_sr25519_generate_version_1
 func ext_crypto_sr25519_generate_version_1(context unsafe.Pointer, a C.int32_t, z C.int64_t) C.int32_t {
@@ -233,23 +176,6 @@ func ext_crypto_sr25519_sign_version_1(context unsafe.Pointer, a, z C.int32_t, y
 	return 0
 }
 
-//export ext_crypto_sr25519_verify_version_1
-func ext_crypto_sr25519_verify_version_1(context unsafe.Pointer, a C.int32_t, z C.int64_t, y C.int32_t) C.int32_t {
-	logger.Trace("[ext_crypto_sr25519_verify_version_1] executing...")
-	return 0
-}
-
-//export ext_crypto_sr25519_verify_version_2
-func ext_crypto_sr25519_verify_version_2(context unsafe.Pointer, a C.int32_t, z C.int64_t, y C.int32_t) C.int32_t {
-	logger.Trace("[ext_crypto_sr25519_verify_version_2] executing...")
-	return 0
-}
-
-//export ext_crypto_start_batch_verify_version_1
-func ext_crypto_start_batch_verify_version_1(context unsafe.Pointer) {
-	logger.Trace("[ext_crypto_start_batch_verify_version_1] executing...")
-}
-
 //export ext_trie_blake2_256_root_version_1
 func ext_trie_blake2_256_root_version_1(context unsafe.Pointer, data C.int64_t) C.int32_t {
 	logger.Trace("[ext_trie_blake2_256_root_version_1] executing...")
@@ -299,55 +225,7 @@ func ext_misc_runtime_version_version_1(context unsafe.Pointer, z C.int64_t) C.i
 	return 0
 }
 
-//export ext_default_child_storage_read_version_1
-func ext_default_child_storage_read_version_1(context unsafe.Pointer, a C.int64_t, b C.int64_t, c C.int64_t, d C.int32_t) C.int64_t {
-	logger.Trace("[ext_default_child_storage_read_version_1] executing...")
-	return 0
-}
-
-//export ext_default_child_storage_clear_version_1
-func ext_default_child_storage_clear_version_1(context unsafe.Pointer, a, b C.int64_t) {
-	logger.Trace("[ext_default_child_storage_clear_version_1] executing...")
-}
-
-//export ext_default_child_storage_clear_prefix_version_1
-func ext_default_child_storage_clear_prefix_version_1(context unsafe.Pointer, a C.int64_t, b C.int64_t) {
-	logger.Trace("[ext_default_child_storage_clear_prefix_version_1] executing...")
-}
-
-//export ext_default_child_storage_exists_version_1
-func ext_default_child_storage_exists_version_1(context unsafe.Pointer, a C.int64_t, b C.int64_t) C.int32_t {
-	logger.Trace("[ext_default_child_storage_exists_version_1] executing...")
-	return 0
-}
-
-//export ext_default_child_storage_get_version_1
-func ext_default_child_storage_get_version_1(context unsafe.Pointer, a, b C.int64_t) C.int64_t {
-	logger.Trace("[ext_default_child_storage_get_version_1] executing...")
-	return 0
-}
-
-//export ext_default_child_storage_next_key_version_1
-func ext_default_child_storage_next_key_version_1(context unsafe.Pointer, a C.int64_t, b C.int64_t) C.int64_t {
-	logger.Trace("[ext_default_child_storage_next_key_version_1] executing...")
-	return 0
-}
-
-//export ext_default_child_storage_root_version_1
-func ext_default_child_storage_root_version_1(context unsafe.Pointer, z C.int64_t) C.int64_t {
-	logger.Trace("[ext_default_child_storage_root_version_1] executing...")
-	return 0
-}
-
-//export ext_default_child_storage_set_version_1
-func ext_default_child_storage_set_version_1(context unsafe.Pointer, a, b, z C.int64_t) {
-	logger.Trace("[ext_default_child_storage_set_version_1] executing...")
-}
-
-//export ext_default_child_storage_storage_kill_version_1
-func ext_default_child_storage_storage_kill_version_1(context unsafe.Pointer, a C.int64_t) {
-	logger.Trace("[ext_default_child_storage_storage_kill_version_1] executing...")
-}
+// ext_default_child_storage_* exports are implemented in child_storage.go
 
 //export ext_allocator_free_version_1
 func ext_allocator_free_version_1(context unsafe.Pointer, addr C.int32_t) {
@@ -456,18 +334,18 @@ func ext_hashing_twox_256_version_1(context unsafe.Pointer, dataSpan C.int64_t)
 }
 
 //export ext_hashing_twox_128_version_1
-func ext_hashing_twox_128_version_1(context unsafe.Pointer, data C.int64_t) C.int32_t {
+func ext_hashing_twox_128_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
 	logger.Trace("[ext_hashing_twox_128_version_1] executing...")
-	ptr, size := int64ToPointerAndSize(int64(data))
 
 	instanceContext := wasm.IntoInstanceContext(context)
-	ctx := instanceContext.Data().(*runtime.Context)
-	out, err := ctx.Allocator.Allocate(16)
+	data := asMemorySlice(instanceContext, dataSpan)
+
+	out, err := newHostAPI(instanceContext).Twox128(data)
 	if err != nil {
 		logger.Error("[ext_hashing_twox_128_version_1] failed to allocate", "error", err)
 		panic(err)
 	}
-	ext_twox_128(context, C.int32_t(ptr), C.int32_t(size), C.int32_t(out))
+
 	return C.int32_t(out)
 }
 
@@ -493,61 +371,38 @@ func ext_hashing_twox_64_version_1(context unsafe.Pointer, dataSpan C.int64_t) C
 	return C.int32_t(out)
 }
 
-//export ext_offchain_index_set_version_1
-func ext_offchain_index_set_version_1(context unsafe.Pointer, a, b C.int64_t) {
-	logger.Trace("[ext_offchain_index_set_version_1] executing...")
-}
-
-//export ext_offchain_is_validator_version_1
-func ext_offchain_is_validator_version_1(context unsafe.Pointer) C.int32_t {
-	logger.Trace("[ext_offchain_is_validator_version_1] executing...")
-	return 0
-}
-
-//export ext_offchain_local_storage_compare_and_set_version_1
-func ext_offchain_local_storage_compare_and_set_version_1(context unsafe.Pointer, a C.int32_t, x, y, z C.int64_t) C.int32_t {
-	logger.Trace("[ext_offchain_local_storage_compare_and_set_version_1] executing...")
-	return 0
-}
-
-//export ext_offchain_local_storage_get_version_1
-func ext_offchain_local_storage_get_version_1(context unsafe.Pointer, a C.int32_t, x C.int64_t) C.int64_t {
-	logger.Trace("[ext_offchain_local_storage_get_version_1] executing...")
-	return 0
-}
-
-//export ext_offchain_local_storage_set_version_1
-func ext_offchain_local_storage_set_version_1(context unsafe.Pointer, a C.int32_t, x, y C.int64_t) {
-	logger.Trace("[ext_offchain_local_storage_set_version_1] executing...")
-}
-
-//export ext_offchain_network_state_version_1
-func ext_offchain_network_state_version_1(context unsafe.Pointer) C.int64_t {
-	logger.Trace("[ext_offchain_network_state_version_1] executing...")
-	return 0
-}
-
-//export ext_offchain_random_seed_version_1
-func ext_offchain_random_seed_version_1(context unsafe.Pointer) C.int32_t {
-	logger.Trace("[ext_offchain_random_seed_version_1] executing...")
-	return 0
-}
-
-//export ext_offchain_submit_transaction_version_1
-func ext_offchain_submit_transaction_version_1(context unsafe.Pointer, z C.int64_t) C.int64_t {
-	logger.Trace("[ext_offchain_submit_transaction_version_1] executing...")
-	return 0
-}
+// ext_offchain_* exports are implemented in offchain.go
 
 //export ext_storage_append_version_1
 func ext_storage_append_version_1(context unsafe.Pointer, a, b C.int64_t) {
 	logger.Trace("[ext_storage_append_version_1] executing...")
 }
 
+// ext_storage_changes_root_version_1 decodes the parent block hash it is given and always
+// answers None for the storage changes root, a feature removed from the runtime in favour of
+// light-client proofs:
+// that's the spec-compliant response for runtimes built against post-v0.9 Substrate, which no
+// longer maintain a changes trie at all.
+//
 //export ext_storage_changes_root_version_1
-func ext_storage_changes_root_version_1(context unsafe.Pointer, z C.int64_t) C.int64_t {
+func ext_storage_changes_root_version_1(context unsafe.Pointer, parentHashSpan C.int64_t) C.int64_t {
 	logger.Trace("[ext_storage_changes_root_version_1] executing...")
-	return 0
+
+	instanceContext := wasm.IntoInstanceContext(context)
+
+	var parentHash common.Hash
+	if _, err := scale.Decode(asMemorySlice(instanceContext, parentHashSpan), &parentHash); err != nil {
+		logger.Error("[ext_storage_changes_root_version_1] failed to decode parent hash", "error", err)
+		return 0
+	}
+
+	resultSpan, err := toWasmMemoryOptional(instanceContext, nil)
+	if err != nil {
+		logger.Error("[ext_storage_changes_root_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return C.int64_t(resultSpan)
 }
 
 //export ext_storage_clear_version_1
@@ -555,21 +410,9 @@ func ext_storage_clear_version_1(context unsafe.Pointer, a C.int64_t) {
 	logger.Trace("[ext_storage_clear_version_1] executing...")
 }
 
-//export ext_storage_clear_prefix_version_1
-func ext_storage_clear_prefix_version_1(context unsafe.Pointer, a C.int64_t) {
-	logger.Trace("[ext_storage_clear_prefix_version_1] executing...")
-}
-
-//export ext_storage_commit_transaction_version_1
-func ext_storage_commit_transaction_version_1(context unsafe.Pointer) {
-	logger.Trace("[ext_storage_commit_transaction_version_1] executing...")
-}
-
-//export ext_storage_exists_version_1
-func ext_storage_exists_version_1(context unsafe.Pointer, a C.int64_t) C.int32_t {
-	logger.Trace("[ext_storage_exists_version_1] executing...")
-	return 0
-}
+// ext_storage_clear_prefix_version_1, ext_storage_commit_transaction_version_1 and
+// ext_storage_exists_version_1 are implemented in child_storage.go, alongside the rest of the
+// transactional storage and default child storage host functions.
 
 //export ext_storage_get_version_1
 func ext_storage_get_version_1(context unsafe.Pointer, keySpan C.int64_t) C.int64_t {
@@ -619,23 +462,6 @@ func ext_storage_next_key_version_1(context unsafe.Pointer, keySpan C.int64_t) C
 	return C.int64_t(nextSpan)
 }
 
-//export ext_storage_read_version_1
-func ext_storage_read_version_1(context unsafe.Pointer, a, b C.int64_t, x C.int32_t) C.int64_t {
-	logger.Trace("[ext_storage_read_version_1] executing...")
-	return 0
-}
-
-//export ext_storage_rollback_transaction_version_1
-func ext_storage_rollback_transaction_version_1(context unsafe.Pointer) {
-	logger.Trace("[ext_storage_rollback_transaction_version_1] executing...")
-}
-
-//export ext_storage_root_version_1
-func ext_storage_root_version_1(context unsafe.Pointer) C.int64_t {
-	logger.Trace("[ext_storage_root_version_1] executing...")
-	return 0
-}
-
 //export ext_storage_set_version_1
 func ext_storage_set_version_1(context unsafe.Pointer, keySpan C.int64_t, valueSpan C.int64_t) {
 	logger.Trace("[ext_storage_set_version_1] executing...")
@@ -654,354 +480,349 @@ func ext_storage_set_version_1(context unsafe.Pointer, keySpan C.int64_t, valueS
 	}
 }
 
-//export ext_storage_start_transaction_version_1
-func ext_storage_start_transaction_version_1(context unsafe.Pointer) {
-	logger.Trace("[ext_storage_start_transaction_version_1] executing...")
-}
-
 // Convert 64bit wasm span descriptor to Go memory slice
 func asMemorySlice(context wasm.InstanceContext, span C.int64_t) []byte {
-	memory := context.Memory().Data()
-
-	ptr, size := int64ToPointerAndSize(int64(span))
-
-	return memory[ptr : ptr+size]
+	return newHostAPI(context).ReadSpan(int64(span))
 }
 
 // Copy a byte slice to wasm memory and return the resulting 64bit span descriptor
 func toWasmMemory(context wasm.InstanceContext, data []byte) (int64, error) {
-	memory := context.Memory().Data()
-	allocator := context.Data().(*runtime.Context).Allocator
-
-	size := uint32(len(data))
-
-	out, err := allocator.Allocate(size)
-	if err != nil {
-		return 0, err
-	}
-
-	copy(memory[out:out+size], data[:])
-
-	return pointerAndSizeToInt64(int32(out), int32(size)), nil
+	return newHostAPI(context).WriteToMemory(data)
 }
 
 // Copy a byte slice of a fixed size to wasm memory and return resulting pointer
 func toWasmMemorySized(context wasm.InstanceContext, data []byte, size uint32) (uint32, error) {
+	return newHostAPI(context).WriteSized(data, size)
+}
 
-	if int(size) != len(data) {
-		return 0, errors.New("internal byte array size missmatch")
-	}
+// Wraps slice in optional and copies result to wasm memory. Returns resulting 64bit span descriptor
+func toWasmMemoryOptional(context wasm.InstanceContext, data []byte) (int64, error) {
+	return newHostAPI(context).WriteOptional(data)
+}
 
-	memory := context.Memory().Data()
-	allocator := context.Data().(*runtime.Context).Allocator
+// ImportsNodeRuntime returns the imports for the v0.8 runtime
+func ImportsNodeRuntime() (*wasm.Imports, error) { //nolint
+	imports := wasm.NewImports()
 
-	out, err := allocator.Allocate(size)
-	if err != nil {
-		return 0, err
+	if err := appendCommonImports(imports); err != nil {
+		return nil, err
 	}
 
-	copy(memory[out:out+size], data[:])
-
-	return out, nil
+	return imports, nil
 }
 
-// Wraps slice in optional and copies result to wasm memory. Returns resulting 64bit span descriptor
-func toWasmMemoryOptional(context wasm.InstanceContext, data []byte) (int64, error) {
+// ImportsParachainRuntime returns the imports for a parachain candidate validation runtime
+// (the PVF interface): every import ImportsNodeRuntime registers, plus the extra host
+// functions a runtime built for parachain candidate validation expects, such as ecdsa
+// signing/verification, keccak-rooted tries and offchain HTTP.
+func ImportsParachainRuntime() (*wasm.Imports, error) { //nolint
+	imports := wasm.NewImports()
 
-	var opt *optional.Bytes
-	if len(data) == 0 {
-		opt = optional.NewBytes(false, nil)
-	} else {
-		opt = optional.NewBytes(true, data)
+	if err := appendCommonImports(imports); err != nil {
+		return nil, err
 	}
 
-	enc, err := opt.Encode()
-	if err != nil {
-		return 0, err
+	if err := appendParachainImports(imports); err != nil {
+		return nil, err
 	}
 
-	return toWasmMemory(context, enc)
+	return imports, nil
 }
 
-// ImportsNodeRuntime returns the imports for the v0.8 runtime
-func ImportsNodeRuntime() (*wasm.Imports, error) { //nolint
+// appendCommonImports registers the host functions every gossamer wasm runtime expects,
+// shared by both ImportsNodeRuntime and ImportsParachainRuntime.
+func appendCommonImports(imports *wasm.Imports) error { //nolint
 	var err error
 
-	imports := wasm.NewImports()
-
 	_, err = imports.Append("ext_allocator_free_version_1", ext_allocator_free_version_1, C.ext_allocator_free_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_allocator_malloc_version_1", ext_allocator_malloc_version_1, C.ext_allocator_malloc_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	_, err = imports.Append("ext_crypto_ed25519_generate_version_1", ext_crypto_ed25519_generate_version_1, C.ext_crypto_ed25519_generate_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_crypto_ed25519_public_keys_version_1", ext_crypto_ed25519_public_keys_version_1, C.ext_crypto_ed25519_public_keys_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_crypto_ed25519_sign_version_1", ext_crypto_ed25519_sign_version_1, C.ext_crypto_ed25519_sign_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_crypto_ed25519_verify_version_1", ext_crypto_ed25519_verify_version_1, C.ext_crypto_ed25519_verify_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_crypto_finish_batch_verify_version_1", ext_crypto_finish_batch_verify_version_1, C.ext_crypto_finish_batch_verify_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_crypto_secp256k1_ecdsa_recover_version_1", ext_crypto_secp256k1_ecdsa_recover_version_1, C.ext_crypto_secp256k1_ecdsa_recover_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_crypto_secp256k1_ecdsa_recover_compressed_version_1", ext_crypto_secp256k1_ecdsa_recover_compressed_version_1, C.ext_crypto_secp256k1_ecdsa_recover_compressed_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_crypto_sr25519_generate_version_1", ext_crypto_sr25519_generate_version_1, C.ext_crypto_sr25519_generate_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_crypto_sr25519_public_keys_version_1", ext_crypto_sr25519_public_keys_version_1, C.ext_crypto_sr25519_public_keys_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_crypto_sr25519_sign_version_1", ext_crypto_sr25519_sign_version_1, C.ext_crypto_sr25519_sign_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_crypto_sr25519_verify_version_1", ext_crypto_sr25519_verify_version_1, C.ext_crypto_sr25519_verify_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_crypto_sr25519_verify_version_2", ext_crypto_sr25519_verify_version_2, C.ext_crypto_sr25519_verify_version_2)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_crypto_start_batch_verify_version_1", ext_crypto_start_batch_verify_version_1, C.ext_crypto_start_batch_verify_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	_, err = imports.Append("ext_default_child_storage_clear_version_1", ext_default_child_storage_clear_version_1, C.ext_default_child_storage_clear_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_default_child_storage_clear_prefix_version_1", ext_default_child_storage_clear_prefix_version_1, C.ext_default_child_storage_clear_prefix_version_1)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	_, err = imports.Append("ext_default_child_storage_clear_prefix_version_2", ext_default_child_storage_clear_prefix_version_2, C.ext_default_child_storage_clear_prefix_version_2)
+	if err != nil {
+		return err
 	}
 	_, err = imports.Append("ext_default_child_storage_exists_version_1", ext_default_child_storage_exists_version_1, C.ext_default_child_storage_exists_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_default_child_storage_get_version_1", ext_default_child_storage_get_version_1, C.ext_default_child_storage_get_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_default_child_storage_next_key_version_1", ext_default_child_storage_next_key_version_1, C.ext_default_child_storage_next_key_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_default_child_storage_read_version_1", ext_default_child_storage_read_version_1, C.ext_default_child_storage_read_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_default_child_storage_root_version_1", ext_default_child_storage_root_version_1, C.ext_default_child_storage_root_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_default_child_storage_set_version_1", ext_default_child_storage_set_version_1, C.ext_default_child_storage_set_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_default_child_storage_storage_kill_version_1", ext_default_child_storage_storage_kill_version_1, C.ext_default_child_storage_storage_kill_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	_, err = imports.Append("ext_hashing_blake2_128_version_1", ext_hashing_blake2_128_version_1, C.ext_hashing_blake2_128_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_hashing_blake2_256_version_1", ext_hashing_blake2_256_version_1, C.ext_hashing_blake2_256_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_hashing_keccak_256_version_1", ext_hashing_keccak_256_version_1, C.ext_hashing_keccak_256_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_hashing_sha2_256_version_1", ext_hashing_sha2_256_version_1, C.ext_hashing_sha2_256_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_hashing_twox_256_version_1", ext_hashing_twox_256_version_1, C.ext_hashing_twox_256_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_hashing_twox_128_version_1", ext_hashing_twox_128_version_1, C.ext_hashing_twox_128_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_hashing_twox_64_version_1", ext_hashing_twox_64_version_1, C.ext_hashing_twox_64_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	_, err = imports.Append("ext_logging_log_version_1", ext_logging_log_version_1, C.ext_logging_log_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	_, err = imports.Append("ext_misc_print_hex_version_1", ext_misc_print_hex_version_1, C.ext_misc_print_hex_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_misc_print_num_version_1", ext_misc_print_num_version_1, C.ext_misc_print_num_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_misc_print_utf8_version_1", ext_misc_print_utf8_version_1, C.ext_misc_print_utf8_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_misc_runtime_version_version_1", ext_misc_runtime_version_version_1, C.ext_misc_runtime_version_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	_, err = imports.Append("ext_offchain_index_set_version_1", ext_offchain_index_set_version_1, C.ext_offchain_index_set_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_offchain_is_validator_version_1", ext_offchain_is_validator_version_1, C.ext_offchain_is_validator_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_offchain_local_storage_compare_and_set_version_1", ext_offchain_local_storage_compare_and_set_version_1, C.ext_offchain_local_storage_compare_and_set_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_offchain_local_storage_get_version_1", ext_offchain_local_storage_get_version_1, C.ext_offchain_local_storage_get_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_offchain_local_storage_set_version_1", ext_offchain_local_storage_set_version_1, C.ext_offchain_local_storage_set_version_1)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	_, err = imports.Append("ext_offchain_local_storage_clear_version_1", ext_offchain_local_storage_clear_version_1, C.ext_offchain_local_storage_clear_version_1)
+	if err != nil {
+		return err
 	}
 	_, err = imports.Append("ext_offchain_network_state_version_1", ext_offchain_network_state_version_1, C.ext_offchain_network_state_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_offchain_random_seed_version_1", ext_offchain_random_seed_version_1, C.ext_offchain_random_seed_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_offchain_submit_transaction_version_1", ext_offchain_submit_transaction_version_1, C.ext_offchain_submit_transaction_version_1)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	_, err = imports.Append("ext_offchain_timestamp_version_1", ext_offchain_timestamp_version_1, C.ext_offchain_timestamp_version_1)
+	if err != nil {
+		return err
 	}
 
 	_, err = imports.Append("ext_sandbox_instance_teardown_version_1", ext_sandbox_instance_teardown_version_1, C.ext_sandbox_instance_teardown_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_sandbox_instantiate_version_1", ext_sandbox_instantiate_version_1, C.ext_sandbox_instantiate_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_sandbox_invoke_version_1", ext_sandbox_invoke_version_1, C.ext_sandbox_invoke_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_sandbox_memory_get_version_1", ext_sandbox_memory_get_version_1, C.ext_sandbox_memory_get_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_sandbox_memory_new_version_1", ext_sandbox_memory_new_version_1, C.ext_sandbox_memory_new_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_sandbox_memory_set_version_1", ext_sandbox_memory_set_version_1, C.ext_sandbox_memory_set_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_sandbox_memory_teardown_version_1", ext_sandbox_memory_teardown_version_1, C.ext_sandbox_memory_teardown_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	_, err = imports.Append("ext_storage_append_version_1", ext_storage_append_version_1, C.ext_storage_append_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_storage_changes_root_version_1", ext_storage_changes_root_version_1, C.ext_storage_changes_root_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_storage_clear_version_1", ext_storage_clear_version_1, C.ext_storage_clear_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_storage_clear_prefix_version_1", ext_storage_clear_prefix_version_1, C.ext_storage_clear_prefix_version_1)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	_, err = imports.Append("ext_storage_clear_prefix_version_2", ext_storage_clear_prefix_version_2, C.ext_storage_clear_prefix_version_2)
+	if err != nil {
+		return err
 	}
 	_, err = imports.Append("ext_storage_commit_transaction_version_1", ext_storage_commit_transaction_version_1, C.ext_storage_commit_transaction_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_storage_exists_version_1", ext_storage_exists_version_1, C.ext_storage_exists_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_storage_get_version_1", ext_storage_get_version_1, C.ext_storage_get_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_storage_next_key_version_1", ext_storage_next_key_version_1, C.ext_storage_next_key_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_storage_read_version_1", ext_storage_read_version_1, C.ext_storage_read_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_storage_rollback_transaction_version_1", ext_storage_rollback_transaction_version_1, C.ext_storage_rollback_transaction_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_storage_root_version_1", ext_storage_root_version_1, C.ext_storage_root_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_storage_set_version_1", ext_storage_set_version_1, C.ext_storage_set_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_storage_start_transaction_version_1", ext_storage_start_transaction_version_1, C.ext_storage_start_transaction_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	_, err = imports.Append("ext_trie_blake2_256_ordered_root_version_1", ext_trie_blake2_256_ordered_root_version_1, C.ext_trie_blake2_256_ordered_root_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	_, err = imports.Append("ext_trie_blake2_256_root_version_1", ext_trie_blake2_256_root_version_1, C.ext_trie_blake2_256_root_version_1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return imports, nil
+	return nil
 }
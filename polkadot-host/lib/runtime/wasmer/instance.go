@@ -32,6 +32,7 @@ const Name = "wasmer"
 // Check that runtime interfaces are satisfied
 var _ runtime.LegacyInstance = (*LegacyInstance)(nil)
 var _ runtime.Memory = (*wasm.Memory)(nil)
+var _ runtime.Interpreter = (*Instance)(nil)
 
 var logger = log.New("pkg", "runtime", "module", "go-wasmer")
 
@@ -113,11 +114,6 @@ func (in *Instance) Exec(function string, data []byte) ([]byte, error) {
 	return in.inst.Exec(function, data)
 }
 
-// Exec func
-func (in *Instance) exec(function string, data []byte) ([]byte, error) {
-	return in.inst.exec(function, data)
-}
-
 // NodeStorage to get reference to runtime node service
 func (in *Instance) NodeStorage() runtime.NodeStorage {
 	return in.inst.ctx.NodeStorage
@@ -128,6 +124,24 @@ func (in *Instance) NetworkService() runtime.BasicNetwork {
 	return in.inst.ctx.Network
 }
 
+// FuelConsumed always returns 0: go-ext-wasm has no fuel-metering equivalent to wasmtime's, so
+// this is a no-op shim that keeps Instance interchangeable with the wasmtime runtime for
+// weight-charging callers.
+func (in *Instance) FuelConsumed() uint64 {
+	return in.inst.FuelConsumed()
+}
+
+// Name returns the interpreter backend name, "wasmer".
+func (in *Instance) Name() string {
+	return Name
+}
+
+// Metrics returns the instance's execution metrics. go-ext-wasm has no fuel-metering
+// equivalent to wasmtime's, so FuelConsumed is always 0.
+func (in *Instance) Metrics() runtime.Metrics {
+	return runtime.Metrics{FuelConsumed: in.FuelConsumed()}
+}
+
 func newLegacyInstance(code []byte, cfg *Config) (*LegacyInstance, error) {
 	// if cfg.LogLvl set to < 0, then don't change package log level
 	if cfg.LogLvl >= 0 {
@@ -173,10 +187,12 @@ func newLegacyInstance(code []byte, cfg *Config) (*LegacyInstance, error) {
 		NodeStorage: cfg.NodeStorage,
 		Network:     cfg.Network,
 		Transaction: cfg.Transaction,
+		Offchain:    cfg.Offchain,
 	}
 
 	logger.Debug("NewInstance", "runtimeCtx", runtimeCtx)
 	instance.SetContextData(runtimeCtx)
+	setSupervisorInstance(runtimeCtx, instance)
 
 	return &LegacyInstance{
 		vm:  instance,
@@ -195,16 +211,11 @@ func (in *LegacyInstance) Stop() {
 	in.vm.Close()
 }
 
-// Store func
-func (in *LegacyInstance) store(data []byte, location int32) {
-	mem := in.vm.Memory.Data()
-	copy(mem[location:location+int32(len(data))], data)
-}
-
-// Load load
-func (in *LegacyInstance) load(location, length int32) []byte {
-	mem := in.vm.Memory.Data()
-	return mem[location : location+length]
+// FuelConsumed always returns 0: go-ext-wasm has no fuel-metering equivalent to wasmtime's, so
+// this is a no-op shim that keeps LegacyInstance interchangeable with the wasmtime runtime for
+// weight-charging callers.
+func (in *LegacyInstance) FuelConsumed() uint64 {
+	return 0
 }
 
 // Exec calls the given function with the given data
@@ -212,46 +223,44 @@ func (in *LegacyInstance) Exec(function string, data []byte) ([]byte, error) {
 	return in.exec(function, data)
 }
 
-// Exec func
-func (in *LegacyInstance) exec(function string, data []byte) ([]byte, error) {
-	if in.ctx.Storage == nil {
-		return nil, runtime.ErrNilStorage
-	}
+// exec calls the given exported function with the given data. Callers must
+// have called SetContext to give the instance a non-nil Storage before
+// invoking exec; a temporary instance that never touches storage has no
+// need to.
+func (in *LegacyInstance) exec(function string, data []byte) (result []byte, err error) {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
 
-	ptr, err := in.malloc(uint32(len(data)))
+	ptr, err := in.ctx.Allocator.Allocate(uint32(len(data)))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %s", runtime.ErrAllocatorFailed, err)
 	}
+	defer in.ctx.Allocator.Clear()
 
-	defer in.clear()
-
-	in.mutex.Lock()
-	defer in.mutex.Unlock()
-
-	// Store the data into memory
-	in.store(data, int32(ptr))
-	datalen := int32(len(data))
+	mem := in.vm.Memory.Data()
+	if uint64(ptr)+uint64(len(data)) > uint64(len(mem)) {
+		return nil, runtime.ErrPointerOverflow
+	}
+	copy(mem[ptr:ptr+uint32(len(data))], data)
 
 	runtimeFunc, ok := in.vm.Exports[function]
 	if !ok {
-		return nil, fmt.Errorf("could not find exported function %s", function)
+		return nil, fmt.Errorf("%w: %s", runtime.ErrExportNotFound, function)
 	}
 
-	res, err := runtimeFunc(int32(ptr), datalen)
+	res, err := runtimeFunc(int32(ptr), int32(len(data)))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %s", runtime.ErrRuntimeCallFailed, err)
 	}
 
 	offset, length := int64ToPointerAndSize(res.ToI64())
-	return in.load(offset, length), nil
-}
 
-func (in *LegacyInstance) malloc(size uint32) (uint32, error) {
-	return in.ctx.Allocator.Allocate(size)
-}
+	mem = in.vm.Memory.Data()
+	if uint64(offset)+uint64(length) > uint64(len(mem)) {
+		return nil, runtime.ErrPointerOverflow
+	}
 
-func (in *LegacyInstance) clear() {
-	in.ctx.Allocator.Clear()
+	return mem[offset : offset+length], nil
 }
 
 // NodeStorage to get reference to runtime node service
@@ -264,12 +273,18 @@ func (in *LegacyInstance) NetworkService() runtime.BasicNetwork {
 	return in.ctx.Network
 }
 
-// int64ToPointerAndSize converts an int64 into a int32 pointer and a int32 length
-func int64ToPointerAndSize(in int64) (ptr int32, length int32) {
-	return int32(in), int32(in >> 32)
+// int64ToPointerAndSize converts an int64 into a uint32 pointer and a uint32
+// length. Both halves of the wasm address space are unsigned, so this must
+// not go through int32: a pointer or length with the high bit set would be
+// mangled into a negative number and read back as a bogus, wildly different
+// offset. This is the same span encoding every wasm backend uses, so the
+// actual packing lives on runtime.UnpackSpan.
+func int64ToPointerAndSize(in int64) (ptr, length uint32) {
+	return runtime.UnpackSpan(in)
 }
 
-// pointerAndSizeToInt64 converts int32 pointer and size to a int64
-func pointerAndSizeToInt64(ptr, size int32) int64 {
-	return int64(ptr) + (int64(size) << 32)
+// pointerAndSizeToInt64 converts a uint32 pointer and size to an int64. See
+// int64ToPointerAndSize.
+func pointerAndSizeToInt64(ptr, size uint32) int64 {
+	return runtime.PackSpan(ptr, size)
 }
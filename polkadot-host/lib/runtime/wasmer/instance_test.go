@@ -29,18 +29,18 @@ func TestConcurrentRuntimeCalls(t *testing.T) {
 
 	// execute 2 concurrent calls to the runtime
 	go func() {
-		_, _ = instance.exec(runtime.CoreVersion, []byte{})
+		_, _ = instance.Exec(runtime.CoreVersion, []byte{})
 	}()
 	go func() {
-		_, _ = instance.exec(runtime.CoreVersion, []byte{})
+		_, _ = instance.Exec(runtime.CoreVersion, []byte{})
 	}()
 }
 
 func TestPointerSize(t *testing.T) {
 	in := int64(8) + int64(32)<<32
 	ptr, length := int64ToPointerAndSize(in)
-	require.Equal(t, int32(8), ptr)
-	require.Equal(t, int32(32), length)
+	require.Equal(t, uint32(8), ptr)
+	require.Equal(t, uint32(32), length)
 	res := pointerAndSizeToInt64(ptr, length)
 	require.Equal(t, in, res)
 }
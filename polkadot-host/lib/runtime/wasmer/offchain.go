@@ -0,0 +1,443 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmer
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"path/filepath"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/scale"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+)
+
+// NetworkStater is implemented by the node's network service to supply the
+// information ext_offchain_network_state_version_1 reports to the runtime.
+type NetworkStater interface {
+	NetworkState() common.NetworkState
+}
+
+// MemoryOffchainStore is the default runtime.Offchain backend. It keeps
+// PERSISTENT and LOCAL key/value pairs in memory, so PERSISTENT values do
+// not survive a restart; use NewLevelDBOffchainStore for that.
+type MemoryOffchainStore struct {
+	mu                sync.Mutex
+	persistent        map[string][]byte
+	local             map[string][]byte
+	submitTransaction func([]byte) error
+	network           NetworkStater
+	validator         bool
+}
+
+// NewMemoryOffchainStore creates an in-memory runtime.Offchain. Extrinsics
+// submitted by the runtime are passed to submitTransaction for the node to
+// inject into its transaction pool.
+func NewMemoryOffchainStore(submitTransaction func([]byte) error, network NetworkStater, validator bool) *MemoryOffchainStore {
+	return &MemoryOffchainStore{
+		persistent:        make(map[string][]byte),
+		local:             make(map[string][]byte),
+		submitTransaction: submitTransaction,
+		network:           network,
+		validator:         validator,
+	}
+}
+
+func (o *MemoryOffchainStore) storeFor(kind int32) map[string][]byte {
+	if runtime.NodeStorageType(kind) == runtime.NodeStorageTypePersistent {
+		return o.persistent
+	}
+	return o.local
+}
+
+// LocalStorageGet returns the value stored under key in the given storage kind.
+func (o *MemoryOffchainStore) LocalStorageGet(kind int32, key []byte) ([]byte, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.storeFor(kind)[string(key)], nil
+}
+
+// LocalStorageSet stores value under key in the given storage kind.
+func (o *MemoryOffchainStore) LocalStorageSet(kind int32, key, value []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.storeFor(kind)[string(key)] = value
+	return nil
+}
+
+// LocalStorageCompareAndSet atomically sets newValue for key if and only if
+// the current value equals oldValue.
+func (o *MemoryOffchainStore) LocalStorageCompareAndSet(kind int32, key, oldValue, newValue []byte) (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	store := o.storeFor(kind)
+	if !bytes.Equal(store[string(key)], oldValue) {
+		return false, nil
+	}
+
+	store[string(key)] = newValue
+	return true, nil
+}
+
+// LocalStorageClear removes key from the given storage kind.
+func (o *MemoryOffchainStore) LocalStorageClear(kind int32, key []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.storeFor(kind), string(key))
+	return nil
+}
+
+// SubmitTransaction hands a SCALE-encoded extrinsic to the node's transaction pool callback.
+func (o *MemoryOffchainStore) SubmitTransaction(ext []byte) error {
+	if o.submitTransaction == nil {
+		return errors.New("no transaction pool callback configured")
+	}
+	return o.submitTransaction(ext)
+}
+
+// NetworkState returns the libp2p peer ID and multiaddrs of the host.
+func (o *MemoryOffchainStore) NetworkState() (common.NetworkState, error) {
+	if o.network == nil {
+		return common.NetworkState{}, errors.New("no network service configured")
+	}
+	return o.network.NetworkState(), nil
+}
+
+// RandomSeed returns 32 bytes of cryptographically random data.
+func (o *MemoryOffchainStore) RandomSeed() [32]byte {
+	var seed [32]byte
+	_, _ = rand.Read(seed[:])
+	return seed
+}
+
+// IsValidator reports whether the node is running with an authoring role.
+func (o *MemoryOffchainStore) IsValidator() bool {
+	return o.validator
+}
+
+// LevelDBOffchainStore is a runtime.Offchain backend that persists PERSISTENT
+// and LOCAL values in separate on-disk leveldb databases, so offchain worker
+// state survives restarts.
+type LevelDBOffchainStore struct {
+	persistent        *leveldb.DB
+	local             *leveldb.DB
+	submitTransaction func([]byte) error
+	network           NetworkStater
+	validator         bool
+}
+
+// NewLevelDBOffchainStore creates a runtime.Offchain backed by leveldb databases rooted at basePath.
+func NewLevelDBOffchainStore(basePath string, submitTransaction func([]byte) error, network NetworkStater, validator bool) (*LevelDBOffchainStore, error) {
+	persistent, err := leveldb.OpenFile(filepath.Join(basePath, "offchain", "persistent"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := leveldb.OpenFile(filepath.Join(basePath, "offchain", "local"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LevelDBOffchainStore{
+		persistent:        persistent,
+		local:             local,
+		submitTransaction: submitTransaction,
+		network:           network,
+		validator:         validator,
+	}, nil
+}
+
+func (o *LevelDBOffchainStore) storeFor(kind int32) *leveldb.DB {
+	if runtime.NodeStorageType(kind) == runtime.NodeStorageTypePersistent {
+		return o.persistent
+	}
+	return o.local
+}
+
+// LocalStorageGet returns the value stored under key in the given storage kind.
+func (o *LevelDBOffchainStore) LocalStorageGet(kind int32, key []byte) ([]byte, error) {
+	value, err := o.storeFor(kind).Get(key, nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, nil
+	}
+	return value, err
+}
+
+// LocalStorageSet stores value under key in the given storage kind.
+func (o *LevelDBOffchainStore) LocalStorageSet(kind int32, key, value []byte) error {
+	return o.storeFor(kind).Put(key, value, nil)
+}
+
+// LocalStorageCompareAndSet atomically sets newValue for key if and only if
+// the current value equals oldValue.
+func (o *LevelDBOffchainStore) LocalStorageCompareAndSet(kind int32, key, oldValue, newValue []byte) (bool, error) {
+	store := o.storeFor(kind)
+
+	cur, err := store.Get(key, nil)
+	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+		return false, err
+	}
+
+	if !bytes.Equal(cur, oldValue) {
+		return false, nil
+	}
+
+	if err := store.Put(key, newValue, nil); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// LocalStorageClear removes key from the given storage kind.
+func (o *LevelDBOffchainStore) LocalStorageClear(kind int32, key []byte) error {
+	return o.storeFor(kind).Delete(key, nil)
+}
+
+// SubmitTransaction hands a SCALE-encoded extrinsic to the node's transaction pool callback.
+func (o *LevelDBOffchainStore) SubmitTransaction(ext []byte) error {
+	if o.submitTransaction == nil {
+		return errors.New("no transaction pool callback configured")
+	}
+	return o.submitTransaction(ext)
+}
+
+// NetworkState returns the libp2p peer ID and multiaddrs of the host.
+func (o *LevelDBOffchainStore) NetworkState() (common.NetworkState, error) {
+	if o.network == nil {
+		return common.NetworkState{}, errors.New("no network service configured")
+	}
+	return o.network.NetworkState(), nil
+}
+
+// RandomSeed returns 32 bytes of cryptographically random data.
+func (o *LevelDBOffchainStore) RandomSeed() [32]byte {
+	var seed [32]byte
+	_, _ = rand.Read(seed[:])
+	return seed
+}
+
+// IsValidator reports whether the node is running with an authoring role.
+func (o *LevelDBOffchainStore) IsValidator() bool {
+	return o.validator
+}
+
+// toWasmMemoryResult encodes err as a Substrate Result<(), ()>: the zero
+// variant byte for Ok, the one variant byte for Err, matching the ABI the
+// runtime expects back from ext_offchain_submit_transaction_version_1.
+func toWasmMemoryResult(instanceContext wasm.InstanceContext, err error) (int64, error) {
+	if err != nil {
+		return toWasmMemory(instanceContext, []byte{1})
+	}
+	return toWasmMemory(instanceContext, []byte{0})
+}
+
+//export ext_offchain_index_set_version_1
+func ext_offchain_index_set_version_1(context unsafe.Pointer, keySpan, valueSpan C.int64_t) {
+	logger.Trace("[ext_offchain_index_set_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	offchain := instanceContext.Data().(*runtime.Context).Offchain
+	if offchain == nil {
+		return
+	}
+
+	key := asMemorySlice(instanceContext, keySpan)
+	value := asMemorySlice(instanceContext, valueSpan)
+
+	if err := offchain.LocalStorageSet(int32(runtime.NodeStorageTypePersistent), key, value); err != nil {
+		logger.Error("[ext_offchain_index_set_version_1]", "error", err)
+	}
+}
+
+//export ext_offchain_is_validator_version_1
+func ext_offchain_is_validator_version_1(context unsafe.Pointer) C.int32_t {
+	logger.Trace("[ext_offchain_is_validator_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	offchain := instanceContext.Data().(*runtime.Context).Offchain
+	if offchain == nil {
+		return 0
+	}
+
+	if offchain.IsValidator() {
+		return 1
+	}
+	return 0
+}
+
+//export ext_offchain_local_storage_compare_and_set_version_1
+func ext_offchain_local_storage_compare_and_set_version_1(context unsafe.Pointer, kind C.int32_t, keySpan, oldValueSpan, newValueSpan C.int64_t) C.int32_t {
+	logger.Trace("[ext_offchain_local_storage_compare_and_set_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	offchain := instanceContext.Data().(*runtime.Context).Offchain
+	if offchain == nil {
+		return 0
+	}
+
+	key := asMemorySlice(instanceContext, keySpan)
+	oldValue := asMemorySlice(instanceContext, oldValueSpan)
+	newValue := asMemorySlice(instanceContext, newValueSpan)
+
+	ok, err := offchain.LocalStorageCompareAndSet(int32(kind), key, oldValue, newValue)
+	if err != nil {
+		logger.Error("[ext_offchain_local_storage_compare_and_set_version_1]", "error", err)
+		return 0
+	}
+
+	if ok {
+		return 1
+	}
+	return 0
+}
+
+//export ext_offchain_local_storage_get_version_1
+func ext_offchain_local_storage_get_version_1(context unsafe.Pointer, kind C.int32_t, keySpan C.int64_t) C.int64_t {
+	logger.Trace("[ext_offchain_local_storage_get_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	key := asMemorySlice(instanceContext, keySpan)
+
+	valueSpan, err := newHostAPI(instanceContext).OffchainLocalStorageGet(int32(kind), key)
+	if err != nil {
+		logger.Error("[ext_offchain_local_storage_get_version_1]", "error", err)
+		return 0
+	}
+
+	return C.int64_t(valueSpan)
+}
+
+//export ext_offchain_local_storage_set_version_1
+func ext_offchain_local_storage_set_version_1(context unsafe.Pointer, kind C.int32_t, keySpan, valueSpan C.int64_t) {
+	logger.Trace("[ext_offchain_local_storage_set_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	key := asMemorySlice(instanceContext, keySpan)
+	value := asMemorySlice(instanceContext, valueSpan)
+
+	if err := newHostAPI(instanceContext).OffchainLocalStorageSet(int32(kind), key, value); err != nil {
+		logger.Error("[ext_offchain_local_storage_set_version_1]", "error", err)
+	}
+}
+
+//export ext_offchain_local_storage_clear_version_1
+func ext_offchain_local_storage_clear_version_1(context unsafe.Pointer, kind C.int32_t, keySpan C.int64_t) {
+	logger.Trace("[ext_offchain_local_storage_clear_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	offchain := instanceContext.Data().(*runtime.Context).Offchain
+	if offchain == nil {
+		return
+	}
+
+	key := asMemorySlice(instanceContext, keySpan)
+
+	if err := offchain.LocalStorageClear(int32(kind), key); err != nil {
+		logger.Error("[ext_offchain_local_storage_clear_version_1]", "error", err)
+	}
+}
+
+//export ext_offchain_network_state_version_1
+func ext_offchain_network_state_version_1(context unsafe.Pointer) C.int64_t {
+	logger.Trace("[ext_offchain_network_state_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	offchain := instanceContext.Data().(*runtime.Context).Offchain
+	if offchain == nil {
+		return 0
+	}
+
+	state, err := offchain.NetworkState()
+	if err != nil {
+		logger.Error("[ext_offchain_network_state_version_1]", "error", err)
+		return 0
+	}
+
+	enc, err := scale.Encode(state)
+	if err != nil {
+		logger.Error("[ext_offchain_network_state_version_1] failed to encode", "error", err)
+		return 0
+	}
+
+	stateSpan, err := toWasmMemory(instanceContext, enc)
+	if err != nil {
+		logger.Error("[ext_offchain_network_state_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return C.int64_t(stateSpan)
+}
+
+//export ext_offchain_random_seed_version_1
+func ext_offchain_random_seed_version_1(context unsafe.Pointer) C.int32_t {
+	logger.Trace("[ext_offchain_random_seed_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	offchain := instanceContext.Data().(*runtime.Context).Offchain
+
+	var seed [32]byte
+	if offchain != nil {
+		seed = offchain.RandomSeed()
+	}
+
+	out, err := toWasmMemorySized(instanceContext, seed[:], 32)
+	if err != nil {
+		logger.Error("[ext_offchain_random_seed_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return C.int32_t(out)
+}
+
+//export ext_offchain_submit_transaction_version_1
+func ext_offchain_submit_transaction_version_1(context unsafe.Pointer, extSpan C.int64_t) C.int64_t {
+	logger.Trace("[ext_offchain_submit_transaction_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	offchain := instanceContext.Data().(*runtime.Context).Offchain
+	if offchain == nil {
+		return 0
+	}
+
+	ext := asMemorySlice(instanceContext, extSpan)
+
+	resultSpan, err := toWasmMemoryResult(instanceContext, offchain.SubmitTransaction(ext))
+	if err != nil {
+		logger.Error("[ext_offchain_submit_transaction_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return C.int64_t(resultSpan)
+}
+
+//export ext_offchain_timestamp_version_1
+func ext_offchain_timestamp_version_1(context unsafe.Pointer) C.int64_t {
+	logger.Trace("[ext_offchain_timestamp_version_1] executing...")
+	return C.int64_t(time.Now().UnixMilli())
+}
@@ -0,0 +1,514 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmer
+
+// #include <stdlib.h>
+//
+// extern int32_t ext_crypto_ecdsa_generate_version_1(void *context, int32_t a, int64_t b);
+// extern int64_t ext_crypto_ecdsa_public_keys_version_1(void *context, int32_t a);
+// extern int64_t ext_crypto_ecdsa_sign_version_1(void *context, int32_t a, int32_t b, int64_t c);
+// extern int64_t ext_crypto_ecdsa_sign_prehashed_version_1(void *context, int32_t a, int32_t b, int64_t c);
+// extern int32_t ext_crypto_ecdsa_verify_version_1(void *context, int32_t a, int64_t b, int32_t c);
+// extern int32_t ext_crypto_ecdsa_verify_version_2(void *context, int32_t a, int64_t b, int32_t c);
+// extern int32_t ext_crypto_ecdsa_verify_prehashed_version_1(void *context, int32_t a, int32_t b, int32_t c);
+//
+// extern int32_t ext_hashing_keccak_512_version_1(void *context, int64_t a);
+//
+// extern int32_t ext_trie_keccak_256_root_version_1(void *context, int64_t a);
+// extern int32_t ext_trie_keccak_256_ordered_root_version_1(void *context, int64_t a);
+// extern int32_t ext_trie_blake2_256_verify_proof_version_1(void *context, int32_t a, int64_t b, int64_t c, int64_t d);
+// extern int32_t ext_trie_blake2_256_verify_proof_version_2(void *context, int32_t a, int64_t b, int64_t c, int64_t d, int32_t e);
+//
+// extern int64_t ext_offchain_http_request_start_version_1(void *context, int64_t a, int64_t b, int64_t c);
+// extern int64_t ext_offchain_http_request_add_header_version_1(void *context, int32_t a, int64_t b, int64_t c);
+// extern int64_t ext_offchain_http_request_write_body_version_1(void *context, int32_t a, int64_t b, int64_t c);
+// extern int64_t ext_offchain_http_response_wait_version_1(void *context, int64_t a, int64_t b);
+// extern int64_t ext_offchain_http_response_headers_version_1(void *context, int32_t a);
+// extern int64_t ext_offchain_http_response_read_body_version_1(void *context, int32_t a, int64_t b, int64_t c);
+import "C"
+
+import (
+	"encoding/binary"
+	"errors"
+	"unsafe"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/scale"
+	"github.com/ChainSafe/gossamer/lib/trie"
+
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+)
+
+// errOffchainHTTPUnsupported is reported by the ext_offchain_http_* host functions when the
+// runtime.Offchain backend wired into this instance doesn't also implement
+// runtime.HTTPOffchain. MemoryOffchainStore and LevelDBOffchainStore, in offchain.go, do not;
+// lib/runtime/offchain.Session does.
+var errOffchainHTTPUnsupported = errors.New("offchain http not supported by this backend")
+
+//export ext_crypto_ecdsa_generate_version_1
+func ext_crypto_ecdsa_generate_version_1(context unsafe.Pointer, a C.int32_t, z C.int64_t) C.int32_t {
+	logger.Trace("[ext_crypto_ecdsa_generate_version_1] executing...")
+	return 0
+}
+
+//export ext_crypto_ecdsa_public_keys_version_1
+func ext_crypto_ecdsa_public_keys_version_1(context unsafe.Pointer, a C.int32_t) C.int64_t {
+	logger.Trace("[ext_crypto_ecdsa_public_keys_version_1] executing...")
+	return 0
+}
+
+//export ext_crypto_ecdsa_sign_version_1
+func ext_crypto_ecdsa_sign_version_1(context unsafe.Pointer, a, z C.int32_t, y C.int64_t) C.int64_t {
+	logger.Trace("[ext_crypto_ecdsa_sign_version_1] executing...")
+	return 0
+}
+
+//export ext_crypto_ecdsa_sign_prehashed_version_1
+func ext_crypto_ecdsa_sign_prehashed_version_1(context unsafe.Pointer, a, z C.int32_t, y C.int64_t) C.int64_t {
+	logger.Trace("[ext_crypto_ecdsa_sign_prehashed_version_1] executing...")
+	return 0
+}
+
+// ext_crypto_ecdsa_verify_* exports are implemented in batch_verify.go, alongside the rest of
+// the signature verification host functions.
+
+//export ext_hashing_keccak_512_version_1
+func ext_hashing_keccak_512_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
+	logger.Trace("[ext_hashing_keccak_512_version_1] executing...")
+	instanceContext := wasm.IntoInstanceContext(context)
+
+	data := asMemorySlice(instanceContext, dataSpan)
+
+	hash, err := common.Keccak512(data)
+	if err != nil {
+		logger.Error("[ext_hashing_keccak_512_version_1]", "error", err)
+		panic(err)
+	}
+
+	out, err := toWasmMemorySized(instanceContext, hash[:], 64)
+	if err != nil {
+		logger.Error("[ext_hashing_keccak_512_version_1] failed to allocate", "error", err)
+		panic(err)
+	}
+
+	return C.int32_t(out)
+}
+
+//export ext_trie_keccak_256_root_version_1
+func ext_trie_keccak_256_root_version_1(context unsafe.Pointer, data C.int64_t) C.int32_t {
+	logger.Trace("[ext_trie_keccak_256_root_version_1] executing...")
+	return 0
+}
+
+// ext_trie_keccak_256_ordered_root_version_1 mirrors ext_trie_blake2_256_ordered_root_version_1's
+// current stub: it always reports the hash of an empty trie rather than building one out of
+// data's encoded Vec<Vec<u8>>.
+//
+//export ext_trie_keccak_256_ordered_root_version_1
+func ext_trie_keccak_256_ordered_root_version_1(context unsafe.Pointer, data C.int64_t) C.int32_t {
+	logger.Trace("[ext_trie_keccak_256_ordered_root_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	memory := instanceContext.Memory().Data()
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	ptr, err := runtimeCtx.Allocator.Allocate(32)
+	if err != nil {
+		logger.Error("[ext_trie_keccak_256_ordered_root_version_1]", "error", err)
+		return 0
+	}
+
+	copy(memory[ptr:ptr+32], trie.EmptyHash[:])
+	return C.int32_t(ptr)
+}
+
+//export ext_trie_blake2_256_verify_proof_version_1
+func ext_trie_blake2_256_verify_proof_version_1(context unsafe.Pointer, rootPtr C.int32_t, proofSpan, keySpan, valueSpan C.int64_t) C.int32_t {
+	logger.Trace("[ext_trie_blake2_256_verify_proof_version_1] executing...")
+	return trieVerifyProof(context, rootPtr, proofSpan, keySpan, valueSpan)
+}
+
+// ext_trie_blake2_256_verify_proof_version_2 adds a trailing state-version parameter that
+// distinguishes how leaf values over a threshold size are stored in the trie; proof
+// verification here does not yet need to tell the two encodings apart.
+//
+//export ext_trie_blake2_256_verify_proof_version_2
+func ext_trie_blake2_256_verify_proof_version_2(context unsafe.Pointer, rootPtr C.int32_t, proofSpan, keySpan, valueSpan C.int64_t, stateVersion C.int32_t) C.int32_t {
+	logger.Trace("[ext_trie_blake2_256_verify_proof_version_2] executing...")
+	return trieVerifyProof(context, rootPtr, proofSpan, keySpan, valueSpan)
+}
+
+func trieVerifyProof(context unsafe.Pointer, rootPtr C.int32_t, proofSpan, keySpan, valueSpan C.int64_t) C.int32_t {
+	instanceContext := wasm.IntoInstanceContext(context)
+	memory := instanceContext.Memory().Data()
+
+	var root common.Hash
+	copy(root[:], memory[rootPtr:int(rootPtr)+32])
+
+	var proof [][]byte
+	decoded, err := scale.Decode(asMemorySlice(instanceContext, proofSpan), proof)
+	if err != nil {
+		logger.Error("[ext_trie_blake2_256_verify_proof_version_1] failed to decode proof", "error", err)
+		return 0
+	}
+	proof = decoded.([][]byte)
+
+	key := asMemorySlice(instanceContext, keySpan)
+	value := asMemorySlice(instanceContext, valueSpan)
+
+	if trie.VerifyProof(root, proof, key, value) {
+		return 1
+	}
+	return 0
+}
+
+// httpOffchain returns the instance's Offchain backend as a runtime.HTTPOffchain, and whether
+// it supports HTTP at all.
+func httpOffchain(instanceContext wasm.InstanceContext) (runtime.HTTPOffchain, bool) {
+	offchain := instanceContext.Data().(*runtime.Context).Offchain
+	if offchain == nil {
+		return nil, false
+	}
+	http, ok := offchain.(runtime.HTTPOffchain)
+	return http, ok
+}
+
+// decodeHTTPDeadline decodes span as a SCALE-encoded Option<u64> Unix-millisecond deadline.
+func decodeHTTPDeadline(instanceContext wasm.InstanceContext, span C.int64_t) *uint64 {
+	deadline, some, err := runtime.DecodeOptionalU64(asMemorySlice(instanceContext, span))
+	if err != nil || !some {
+		return nil
+	}
+	return &deadline
+}
+
+// encodeHTTPStartResult SCALE-encodes Result<RequestId, ()>: the 0 (Ok) variant followed by a
+// little-endian u16 request ID, or the 1 (Err) variant alone.
+func encodeHTTPStartResult(id uint16, err error) []byte {
+	if err != nil {
+		return []byte{1}
+	}
+	return []byte{0, byte(id), byte(id >> 8)}
+}
+
+// encodeHTTPUnitResult SCALE-encodes the Result<(), ()>/Result<(), HttpError> shape every
+// ext_offchain_http_* host function other than request_start and response_read_body returns:
+// the 0 (Ok) variant, or the 1 (Err) variant alone, collapsing every HttpError case to one
+// failure byte.
+func encodeHTTPUnitResult(err error) []byte {
+	if err != nil {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// encodeHTTPReadResult SCALE-encodes Result<u32, HttpError>: the 0 (Ok) variant followed by a
+// little-endian u32 byte count, or the 1 (Err) variant alone.
+func encodeHTTPReadResult(n int, err error) []byte {
+	if err != nil {
+		return []byte{1}
+	}
+	buf := make([]byte, 5)
+	binary.LittleEndian.PutUint32(buf[1:], uint32(n))
+	return buf
+}
+
+// encodeHTTPStatuses SCALE-encodes a Vec<HttpRequestStatus>, one variant byte per status (with
+// Finished additionally carrying its little-endian u16 status code) behind a single-byte
+// compact length prefix. The single-byte form only covers fewer than 64 statuses, which is
+// every caller this backs: a runtime waits on at most the handful of requests one offchain
+// worker run opened.
+func encodeHTTPStatuses(statuses []runtime.HTTPRequestStatus) []byte {
+	buf := []byte{byte(len(statuses)) << 2}
+	for _, status := range statuses {
+		buf = append(buf, byte(status.Outcome))
+		if status.Outcome == runtime.HTTPOutcomeFinished {
+			buf = append(buf, byte(status.StatusCode), byte(status.StatusCode>>8))
+		}
+	}
+	return buf
+}
+
+// encodeHTTPHeaders SCALE-encodes a Vec<(Vec<u8>, Vec<u8>)> of header name/value pairs behind
+// single-byte compact length prefixes, the same simplification encodeHTTPStatuses makes:
+// fewer than 64 headers, each under 64 bytes, comfortably covers real HTTP responses.
+func encodeHTTPHeaders(pairs [][2][]byte) []byte {
+	buf := []byte{byte(len(pairs)) << 2}
+	for _, pair := range pairs {
+		for _, part := range pair {
+			buf = append(buf, byte(len(part))<<2)
+			buf = append(buf, part...)
+		}
+	}
+	return buf
+}
+
+// decodeU16RequestIDs decodes a SCALE-encoded Vec<RequestId> (u16 elements) behind a
+// single-byte compact length prefix; see encodeHTTPStatuses for why that's sufficient here.
+func decodeU16RequestIDs(b []byte) ([]uint16, error) {
+	if len(b) == 0 {
+		return nil, errors.New("empty request id vector")
+	}
+	if b[0]&0x3 != 0 {
+		return nil, errors.New("unsupported compact length prefix")
+	}
+
+	n := int(b[0] >> 2)
+	b = b[1:]
+	if len(b) < n*2 {
+		return nil, errors.New("short request id vector")
+	}
+
+	ids := make([]uint16, n)
+	for i := 0; i < n; i++ {
+		ids[i] = binary.LittleEndian.Uint16(b[i*2 : i*2+2])
+	}
+	return ids, nil
+}
+
+//export ext_offchain_http_request_start_version_1
+func ext_offchain_http_request_start_version_1(context unsafe.Pointer, methodSpan, uriSpan, metaSpan C.int64_t) C.int64_t {
+	logger.Trace("[ext_offchain_http_request_start_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+
+	var result []byte
+	if http, ok := httpOffchain(instanceContext); !ok {
+		result = encodeHTTPStartResult(0, errOffchainHTTPUnsupported)
+	} else {
+		method := string(asMemorySlice(instanceContext, methodSpan))
+		uri := string(asMemorySlice(instanceContext, uriSpan))
+		id, err := http.HTTPRequestStart(method, uri)
+		result = encodeHTTPStartResult(id, err)
+	}
+
+	out, err := toWasmMemory(instanceContext, result)
+	if err != nil {
+		logger.Error("[ext_offchain_http_request_start_version_1] failed to allocate", "error", err)
+		return 0
+	}
+	return C.int64_t(out)
+}
+
+//export ext_offchain_http_request_add_header_version_1
+func ext_offchain_http_request_add_header_version_1(context unsafe.Pointer, requestID C.int32_t, nameSpan, valueSpan C.int64_t) C.int64_t {
+	logger.Trace("[ext_offchain_http_request_add_header_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+
+	var resultErr error
+	if http, ok := httpOffchain(instanceContext); !ok {
+		resultErr = errOffchainHTTPUnsupported
+	} else {
+		name := string(asMemorySlice(instanceContext, nameSpan))
+		value := string(asMemorySlice(instanceContext, valueSpan))
+		resultErr = http.HTTPRequestAddHeader(uint16(requestID), name, value)
+	}
+
+	out, err := toWasmMemory(instanceContext, encodeHTTPUnitResult(resultErr))
+	if err != nil {
+		logger.Error("[ext_offchain_http_request_add_header_version_1] failed to allocate", "error", err)
+		return 0
+	}
+	return C.int64_t(out)
+}
+
+//export ext_offchain_http_request_write_body_version_1
+func ext_offchain_http_request_write_body_version_1(context unsafe.Pointer, requestID C.int32_t, chunkSpan, deadlineSpan C.int64_t) C.int64_t {
+	logger.Trace("[ext_offchain_http_request_write_body_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+
+	var resultErr error
+	if http, ok := httpOffchain(instanceContext); !ok {
+		resultErr = errOffchainHTTPUnsupported
+	} else {
+		chunk := asMemorySlice(instanceContext, chunkSpan)
+		deadline := decodeHTTPDeadline(instanceContext, deadlineSpan)
+		resultErr = http.HTTPRequestWriteBody(uint16(requestID), chunk, deadline)
+	}
+
+	out, err := toWasmMemory(instanceContext, encodeHTTPUnitResult(resultErr))
+	if err != nil {
+		logger.Error("[ext_offchain_http_request_write_body_version_1] failed to allocate", "error", err)
+		return 0
+	}
+	return C.int64_t(out)
+}
+
+//export ext_offchain_http_response_wait_version_1
+func ext_offchain_http_response_wait_version_1(context unsafe.Pointer, idsSpan, deadlineSpan C.int64_t) C.int64_t {
+	logger.Trace("[ext_offchain_http_response_wait_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+
+	ids, err := decodeU16RequestIDs(asMemorySlice(instanceContext, idsSpan))
+	if err != nil {
+		logger.Error("[ext_offchain_http_response_wait_version_1] failed to decode ids", "error", err)
+		return 0
+	}
+
+	var statuses []runtime.HTTPRequestStatus
+	if http, ok := httpOffchain(instanceContext); !ok {
+		statuses = make([]runtime.HTTPRequestStatus, len(ids))
+		for i := range statuses {
+			statuses[i] = runtime.HTTPRequestStatus{Outcome: runtime.HTTPOutcomeIoError}
+		}
+	} else {
+		deadline := decodeHTTPDeadline(instanceContext, deadlineSpan)
+		statuses = http.HTTPResponseWait(ids, deadline)
+	}
+
+	out, err := toWasmMemory(instanceContext, encodeHTTPStatuses(statuses))
+	if err != nil {
+		logger.Error("[ext_offchain_http_response_wait_version_1] failed to allocate", "error", err)
+		return 0
+	}
+	return C.int64_t(out)
+}
+
+//export ext_offchain_http_response_headers_version_1
+func ext_offchain_http_response_headers_version_1(context unsafe.Pointer, requestID C.int32_t) C.int64_t {
+	logger.Trace("[ext_offchain_http_response_headers_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+
+	var headers [][2][]byte
+	if http, ok := httpOffchain(instanceContext); ok {
+		headers = http.HTTPResponseHeaders(uint16(requestID))
+	}
+
+	out, err := toWasmMemory(instanceContext, encodeHTTPHeaders(headers))
+	if err != nil {
+		logger.Error("[ext_offchain_http_response_headers_version_1] failed to allocate", "error", err)
+		return 0
+	}
+	return C.int64_t(out)
+}
+
+//export ext_offchain_http_response_read_body_version_1
+func ext_offchain_http_response_read_body_version_1(context unsafe.Pointer, requestID C.int32_t, bufferSpan, deadlineSpan C.int64_t) C.int64_t {
+	logger.Trace("[ext_offchain_http_response_read_body_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	memory := instanceContext.Memory().Data()
+
+	bufferPtr, bufferLen := int64ToPointerAndSize(int64(bufferSpan))
+	buffer := memory[bufferPtr : bufferPtr+bufferLen]
+
+	var n int
+	var resultErr error
+	if http, ok := httpOffchain(instanceContext); !ok {
+		resultErr = errOffchainHTTPUnsupported
+	} else {
+		deadline := decodeHTTPDeadline(instanceContext, deadlineSpan)
+		n, resultErr = http.HTTPResponseReadBody(uint16(requestID), buffer, deadline)
+	}
+
+	out, err := toWasmMemory(instanceContext, encodeHTTPReadResult(n, resultErr))
+	if err != nil {
+		logger.Error("[ext_offchain_http_response_read_body_version_1] failed to allocate", "error", err)
+		return 0
+	}
+	return C.int64_t(out)
+}
+
+// appendParachainImports registers the additional host functions a runtime built for
+// parachain candidate validation expects on top of appendCommonImports: ecdsa signing and
+// verification, keccak-rooted tries, trie proof verification, and offchain HTTP.
+func appendParachainImports(imports *wasm.Imports) error { //nolint
+	var err error
+
+	_, err = imports.Append("ext_crypto_ecdsa_generate_version_1", ext_crypto_ecdsa_generate_version_1, C.ext_crypto_ecdsa_generate_version_1)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_crypto_ecdsa_public_keys_version_1", ext_crypto_ecdsa_public_keys_version_1, C.ext_crypto_ecdsa_public_keys_version_1)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_crypto_ecdsa_sign_version_1", ext_crypto_ecdsa_sign_version_1, C.ext_crypto_ecdsa_sign_version_1)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_crypto_ecdsa_sign_prehashed_version_1", ext_crypto_ecdsa_sign_prehashed_version_1, C.ext_crypto_ecdsa_sign_prehashed_version_1)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_crypto_ecdsa_verify_version_1", ext_crypto_ecdsa_verify_version_1, C.ext_crypto_ecdsa_verify_version_1)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_crypto_ecdsa_verify_version_2", ext_crypto_ecdsa_verify_version_2, C.ext_crypto_ecdsa_verify_version_2)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_crypto_ecdsa_verify_prehashed_version_1", ext_crypto_ecdsa_verify_prehashed_version_1, C.ext_crypto_ecdsa_verify_prehashed_version_1)
+	if err != nil {
+		return err
+	}
+
+	_, err = imports.Append("ext_hashing_keccak_512_version_1", ext_hashing_keccak_512_version_1, C.ext_hashing_keccak_512_version_1)
+	if err != nil {
+		return err
+	}
+
+	_, err = imports.Append("ext_trie_keccak_256_root_version_1", ext_trie_keccak_256_root_version_1, C.ext_trie_keccak_256_root_version_1)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_trie_keccak_256_ordered_root_version_1", ext_trie_keccak_256_ordered_root_version_1, C.ext_trie_keccak_256_ordered_root_version_1)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_trie_blake2_256_verify_proof_version_1", ext_trie_blake2_256_verify_proof_version_1, C.ext_trie_blake2_256_verify_proof_version_1)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_trie_blake2_256_verify_proof_version_2", ext_trie_blake2_256_verify_proof_version_2, C.ext_trie_blake2_256_verify_proof_version_2)
+	if err != nil {
+		return err
+	}
+
+	_, err = imports.Append("ext_offchain_http_request_start_version_1", ext_offchain_http_request_start_version_1, C.ext_offchain_http_request_start_version_1)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_offchain_http_request_add_header_version_1", ext_offchain_http_request_add_header_version_1, C.ext_offchain_http_request_add_header_version_1)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_offchain_http_request_write_body_version_1", ext_offchain_http_request_write_body_version_1, C.ext_offchain_http_request_write_body_version_1)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_offchain_http_response_wait_version_1", ext_offchain_http_response_wait_version_1, C.ext_offchain_http_response_wait_version_1)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_offchain_http_response_headers_version_1", ext_offchain_http_response_headers_version_1, C.ext_offchain_http_response_headers_version_1)
+	if err != nil {
+		return err
+	}
+	_, err = imports.Append("ext_offchain_http_response_read_body_version_1", ext_offchain_http_response_read_body_version_1, C.ext_offchain_http_response_read_body_version_1)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmer
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInstance_ParachainValidateBlock instantiates a parachain-validation-style test wasm
+// against ImportsParachainRuntime and confirms the extra PVF imports (ecdsa, keccak, trie
+// proof verification) are wired up well enough for the runtime to call validate_block.
+func TestInstance_ParachainValidateBlock(t *testing.T) {
+	instance := NewTestInstance(t, runtime.PARACHAIN_VALIDATION_RUNTIME)
+
+	_, err := instance.Exec("validate_block", []byte{})
+	require.NoError(t, err)
+}
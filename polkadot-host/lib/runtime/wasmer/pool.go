@@ -0,0 +1,144 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+)
+
+// ErrPoolClosed is returned by Acquire once Close has been called.
+var ErrPoolClosed = errors.New("instance pool closed")
+
+// InstancePool owns a fixed number of pre-instantiated wasmer instances, all built from the
+// same wasm code and Config, and hands them out to callers that would otherwise serialise on
+// a single *Instance behind an ad-hoc mutex (BABE block production competing with RPC state
+// queries, for example). Each Instance already protects its own linear memory and
+// FreeingBumpHeapAllocator via LegacyInstance.exec's mutex; the pool's job is purely to let
+// independent callers run on different instances at once instead of queueing on one.
+type InstancePool struct {
+	code []byte
+	cfg  *Config
+
+	slots chan *Instance
+	close chan struct{}
+}
+
+// NewInstancePoolFromFile instantiates a pool of size instances from the wasm file at fp.
+func NewInstancePoolFromFile(fp string, cfg *Config, size int) (*InstancePool, error) {
+	code, err := wasm.ReadBytes(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewInstancePool(code, cfg, size)
+}
+
+// NewInstancePool instantiates size instances from code and cfg and returns a pool ready to
+// hand them out. size <= 0 means runtime.DefaultPoolSize.
+func NewInstancePool(code []byte, cfg *Config, size int) (*InstancePool, error) {
+	if size <= 0 {
+		size = runtime.DefaultPoolSize
+	}
+
+	p := &InstancePool{
+		code:  code,
+		cfg:   cfg,
+		slots: make(chan *Instance, size),
+		close: make(chan struct{}),
+	}
+
+	for i := 0; i < size; i++ {
+		inst, err := NewInstance(code, cfg)
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("instantiating pool member %d/%d: %w", i+1, size, err)
+		}
+		p.slots <- inst
+	}
+
+	return p, nil
+}
+
+// Acquire blocks until an instance is free or ctx is done, whichever comes first.
+func (p *InstancePool) Acquire(ctx context.Context) (*Instance, error) {
+	select {
+	case <-p.close:
+		return nil, ErrPoolClosed
+	default:
+	}
+
+	select {
+	case inst, ok := <-p.slots:
+		if !ok {
+			return nil, ErrPoolClosed
+		}
+		return inst, nil
+	case <-p.close:
+		return nil, ErrPoolClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Release returns inst to the pool. If broken is true (the caller observed inst panic or
+// otherwise end up in an unusable state), Release discards it and instantiates a fresh
+// replacement from the pool's original code and Config, so one bad instance doesn't
+// permanently shrink the pool or poison later callers.
+func (p *InstancePool) Release(inst *Instance, broken bool) {
+	if broken {
+		inst.Stop()
+
+		replacement, err := NewInstance(p.code, p.cfg)
+		if err != nil {
+			logger.Error("InstancePool failed to recycle broken instance", "error", err)
+			return
+		}
+		inst = replacement
+	}
+
+	select {
+	case p.slots <- inst:
+	case <-p.close:
+		inst.Stop()
+	}
+}
+
+// Close makes all pending and future Acquire calls fail with ErrPoolClosed and stops every
+// instance currently sitting idle in the pool. Instances a caller is holding at the time of
+// Close are stopped as they're Released rather than by Close itself.
+func (p *InstancePool) Close() {
+	select {
+	case <-p.close:
+		return
+	default:
+		close(p.close)
+	}
+
+	for {
+		select {
+		case inst := <-p.slots:
+			inst.Stop()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	database "github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+)
+
+// benchSetupConfig mirrors setupConfig but takes a *testing.B, since that helper is pinned to
+// *testing.T and the require-based fatals it relies on don't fit a parallel benchmark body.
+func benchSetupConfig(b *testing.B, targetRuntime string) (string, *Config) {
+	testRuntimeFilePath, testRuntimeURL := runtime.GetRuntimeVars(targetRuntime)
+
+	if _, err := runtime.GetRuntimeBlob(testRuntimeFilePath, testRuntimeURL); err != nil {
+		b.Fatalf("could not get runtime %s: %s", targetRuntime, err)
+	}
+
+	fp, err := filepath.Abs(testRuntimeFilePath)
+	if err != nil {
+		b.Fatalf("could not create testRuntimeFilePath: %s", err)
+	}
+
+	cfg := &Config{
+		Imports: GetRuntimeImports(targetRuntime),
+	}
+	cfg.Storage = runtime.NewTestRuntimeStorage(b, nil)
+	cfg.Keystore = keystore.NewGenericKeystore("test")
+	cfg.LogLvl = defaultTestLogLvl
+	cfg.NodeStorage = runtime.NodeStorage{
+		LocalStorage:      database.NewMemDatabase(),
+		PersistentStorage: database.NewMemDatabase(),
+	}
+	cfg.Network = new(runtime.TestRuntimeNetwork)
+	cfg.Transaction = new(mockTransactionState)
+	return fp, cfg
+}
+
+// benchmarkPoolExec drives b.N calls to fn, split across parallel goroutines that each Acquire
+// an instance from a pool of the given size, run fn, and Release it. Comparing BenchmarkPool_*
+// runs across pool sizes 1, 2, 4 shows whether throughput actually scales with the pool, as
+// opposed to every goroutine queueing on the same instance's exec mutex.
+func benchmarkPoolExec(b *testing.B, poolSize int, fn string) {
+	fp, cfg := benchSetupConfig(b, runtime.TEST_RUNTIME)
+	pool, err := NewInstancePoolFromFile(fp, cfg, poolSize)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pool.Close()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			inst, err := pool.Acquire(context.Background())
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			if _, err := inst.Exec(fn, []byte{}); err != nil {
+				b.Fatal(err)
+			}
+
+			pool.Release(inst, false)
+		}
+	})
+}
+
+func BenchmarkPool_CoreVersion_Size1(b *testing.B) {
+	benchmarkPoolExec(b, 1, runtime.CoreVersion)
+}
+
+func BenchmarkPool_CoreVersion_Size2(b *testing.B) {
+	benchmarkPoolExec(b, 2, runtime.CoreVersion)
+}
+
+func BenchmarkPool_CoreVersion_Size4(b *testing.B) {
+	benchmarkPoolExec(b, 4, runtime.CoreVersion)
+}
+
+func BenchmarkPool_ExecuteBlock_Size1(b *testing.B) {
+	benchmarkPoolExec(b, 1, runtime.CoreExecuteBlock)
+}
+
+func BenchmarkPool_ExecuteBlock_Size4(b *testing.B) {
+	benchmarkPoolExec(b, 4, runtime.CoreExecuteBlock)
+}
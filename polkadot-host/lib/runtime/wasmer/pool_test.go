@@ -0,0 +1,111 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmer
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstancePool_AcquireRelease(t *testing.T) {
+	pool := NewTestInstancePool(t, runtime.TEST_RUNTIME, 2)
+	defer pool.Close()
+
+	inst1, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	inst2, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	require.NotSame(t, inst1, inst2)
+
+	// the pool is exhausted: a third Acquire must block until one is released
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_, err := pool.Acquire(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+		close(done)
+	}()
+	cancel()
+	<-done
+
+	pool.Release(inst1, false)
+	inst3, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	require.Same(t, inst1, inst3)
+}
+
+// TestInstancePool_RecyclesBrokenInstance checks that releasing an instance flagged as broken
+// (the caller recovered from a panic while using it, say) replaces it rather than returning the
+// poisoned instance to later callers, and that the pool's other members are unaffected.
+func TestInstancePool_RecyclesBrokenInstance(t *testing.T) {
+	pool := NewTestInstancePool(t, runtime.TEST_RUNTIME, 2)
+	defer pool.Close()
+
+	broken, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	healthy, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+
+	pool.Release(broken, true)
+	pool.Release(healthy, false)
+
+	seen := map[*Instance]bool{}
+	for i := 0; i < 2; i++ {
+		inst, err := pool.Acquire(context.Background())
+		require.NoError(t, err)
+		seen[inst] = true
+	}
+	require.True(t, seen[healthy])
+	require.False(t, seen[broken])
+}
+
+func TestInstancePool_AcquireAfterClose(t *testing.T) {
+	pool := NewTestInstancePool(t, runtime.TEST_RUNTIME, 1)
+	pool.Close()
+
+	_, err := pool.Acquire(context.Background())
+	require.ErrorIs(t, err, ErrPoolClosed)
+}
+
+// TestInstancePool_ConcurrentCoreVersion exercises the same concern as the older
+// TestConcurrentRuntimeCalls, but against the pool: many goroutines calling Core_version at
+// once should all succeed, each served by one of the pool's instances rather than queueing on
+// a single one.
+func TestInstancePool_ConcurrentCoreVersion(t *testing.T) {
+	pool := NewTestInstancePool(t, runtime.TEST_RUNTIME, 4)
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			inst, err := pool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer pool.Release(inst, false)
+
+			_, err = inst.Exec(runtime.CoreVersion, []byte{})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
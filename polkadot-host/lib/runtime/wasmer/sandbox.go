@@ -0,0 +1,464 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmer
+
+// #include <stdlib.h>
+//
+// extern int32_t ext_sandbox_dispatch_0(void *context, int32_t a, int32_t b, int32_t c, int32_t d);
+// extern int32_t ext_sandbox_dispatch_1(void *context, int32_t a, int32_t b, int32_t c, int32_t d);
+// extern int32_t ext_sandbox_dispatch_2(void *context, int32_t a, int32_t b, int32_t c, int32_t d);
+// extern int32_t ext_sandbox_dispatch_3(void *context, int32_t a, int32_t b, int32_t c, int32_t d);
+// extern int32_t ext_sandbox_dispatch_4(void *context, int32_t a, int32_t b, int32_t c, int32_t d);
+// extern int32_t ext_sandbox_dispatch_5(void *context, int32_t a, int32_t b, int32_t c, int32_t d);
+// extern int32_t ext_sandbox_dispatch_6(void *context, int32_t a, int32_t b, int32_t c, int32_t d);
+// extern int32_t ext_sandbox_dispatch_7(void *context, int32_t a, int32_t b, int32_t c, int32_t d);
+import "C"
+
+import (
+	"errors"
+	"sync"
+	"unsafe"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/scale"
+
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+)
+
+// sandboxDispatchSlots bounds how many distinct guest imports a single sandboxed instance can
+// bind to a host dispatch trampoline. go-ext-wasm resolves each imported function to a
+// pre-compiled cgo export, so the trampolines can't be generated per-contract at runtime; instead
+// a fixed pool of generic slots is declared above and entries beyond the pool are rejected at
+// instantiate time.
+const sandboxDispatchSlots = 8
+
+// sandboxEnvDefEntry is one guest-declared import: a (module, field) pair bound to the dispatch
+// thunk index the supervisor uses to identify which host call is being made.
+type sandboxEnvDefEntry struct {
+	Module    []byte
+	Field     []byte
+	FuncIndex uint32
+}
+
+// sandboxEnvDef is the SCALE-decoded form of the env-def blob passed to
+// ext_sandbox_instantiate_version_1.
+type sandboxEnvDef struct {
+	Entries []sandboxEnvDefEntry
+}
+
+// sandboxInstance is one nested wasm.Instance together with the dispatch thunk index its guest
+// imports were bound against, so invoke calls and dispatch trampolines share it.
+type sandboxInstance struct {
+	vm            wasm.Instance
+	dispatchThunk int32
+	reg           *registry
+}
+
+// registry holds the nested instances and memories created by one supervisor wasm.Instance,
+// keyed by the int32 handles returned to the guest, plus a reference back to the supervisor
+// instance so dispatch trampolines can call its exported dispatch_thunk.
+type registry struct {
+	mu sync.Mutex
+
+	supervisor wasm.Instance
+
+	instances  map[int32]*sandboxInstance
+	nextInstID int32
+
+	memories  map[int32]*wasm.Memory
+	nextMemID int32
+}
+
+func newRegistry() *registry {
+	return &registry{
+		instances: make(map[int32]*sandboxInstance),
+		memories:  make(map[int32]*wasm.Memory),
+	}
+}
+
+// registries maps a running supervisor instance's shared runtime.Context to its sandbox registry.
+// Keying on the *runtime.Context pointer (rather than threading a new field through the shared
+// struct) keeps the nested wasm.Instance/wasm.Memory types, which are wasmer-specific, out of the
+// backend-agnostic runtime package.
+var registries sync.Map // map[*runtime.Context]*registry
+
+func registryFor(ctx *runtime.Context) *registry {
+	if v, ok := registries.Load(ctx); ok {
+		return v.(*registry)
+	}
+	reg := newRegistry()
+	registries.Store(ctx, reg)
+	return reg
+}
+
+// setSupervisorInstance records the top-level wasm.Instance a runtime.Context belongs to, so
+// sandboxed guests instantiated against that context can dispatch calls back out to it. Called
+// once from newLegacyInstance after the supervisor instance is built.
+func setSupervisorInstance(ctx *runtime.Context, inst wasm.Instance) {
+	registryFor(ctx).supervisor = inst
+}
+
+//export ext_sandbox_instantiate_version_1
+func ext_sandbox_instantiate_version_1(context unsafe.Pointer, dispatchThunk C.int32_t, wasmCodeSpan, envDefSpan C.int64_t, stateSpan C.int32_t) C.int32_t {
+	logger.Trace("[ext_sandbox_instantiate_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	code := asMemorySlice(instanceContext, wasmCodeSpan)
+	rawEnvDef := asMemorySlice(instanceContext, envDefSpan)
+
+	envDef := new(sandboxEnvDef)
+	if _, err := scale.Decode(rawEnvDef, envDef); err != nil {
+		logger.Error("[ext_sandbox_instantiate_version_1] failed to decode env def", "error", err)
+		return -1
+	}
+
+	reg := registryFor(runtimeCtx)
+
+	imports := wasm.NewImports()
+	for _, entry := range envDef.Entries {
+		if entry.FuncIndex >= sandboxDispatchSlots {
+			logger.Error("[ext_sandbox_instantiate_version_1] guest import exceeds dispatch slot pool",
+				"module", string(entry.Module), "field", string(entry.Field), "index", entry.FuncIndex)
+			return -1
+		}
+
+		goFn, cFn := sandboxDispatchSlot(entry.FuncIndex)
+		if _, err := imports.Append(string(entry.Field), goFn, cFn); err != nil {
+			logger.Error("[ext_sandbox_instantiate_version_1] failed to bind import", "error", err)
+			return -1
+		}
+	}
+
+	vm, err := wasm.NewInstanceWithImports(code, imports)
+	if err != nil {
+		logger.Error("[ext_sandbox_instantiate_version_1] failed to instantiate nested module", "error", err)
+		return -1
+	}
+
+	sb := &sandboxInstance{
+		vm:            vm,
+		dispatchThunk: int32(dispatchThunk),
+		reg:           reg,
+	}
+	vm.SetContextData(sb)
+
+	reg.mu.Lock()
+	id := reg.nextInstID
+	reg.nextInstID++
+	reg.instances[id] = sb
+	reg.mu.Unlock()
+
+	return C.int32_t(id)
+}
+
+//export ext_sandbox_instance_teardown_version_1
+func ext_sandbox_instance_teardown_version_1(context unsafe.Pointer, instanceIdx C.int32_t) {
+	logger.Trace("[ext_sandbox_instance_teardown_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	reg := registryFor(runtimeCtx)
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	sb, ok := reg.instances[int32(instanceIdx)]
+	if !ok {
+		return
+	}
+	sb.vm.Close()
+	delete(reg.instances, int32(instanceIdx))
+}
+
+//export ext_sandbox_invoke_version_1
+func ext_sandbox_invoke_version_1(context unsafe.Pointer, instanceIdx C.int32_t, exportSpan, argsSpan C.int64_t, returnValPtr, returnValLen, state C.int32_t) C.int32_t {
+	logger.Trace("[ext_sandbox_invoke_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	reg := registryFor(runtimeCtx)
+
+	reg.mu.Lock()
+	sb, ok := reg.instances[int32(instanceIdx)]
+	reg.mu.Unlock()
+	if !ok {
+		logger.Error("[ext_sandbox_invoke_version_1] unknown sandbox instance", "idx", instanceIdx)
+		return -1
+	}
+
+	exportName := string(asMemorySlice(instanceContext, exportSpan))
+	rawArgs := asMemorySlice(instanceContext, argsSpan)
+
+	var rawInts []int64
+	if _, err := scale.Decode(rawArgs, &rawInts); err != nil {
+		logger.Error("[ext_sandbox_invoke_version_1] failed to decode args", "error", err)
+		return -1
+	}
+
+	args := make([]interface{}, len(rawInts))
+	for i, v := range rawInts {
+		args[i] = v
+	}
+
+	export, ok := sb.vm.Exports[exportName]
+	if !ok {
+		logger.Error("[ext_sandbox_invoke_version_1] export not found", "export", exportName)
+		return -1
+	}
+
+	res, err := export(args...)
+	if err != nil {
+		logger.Error("[ext_sandbox_invoke_version_1] invocation failed", "error", err)
+		return -1
+	}
+
+	enc, err := scale.Encode(res.ToI64())
+	if err != nil {
+		logger.Error("[ext_sandbox_invoke_version_1] failed to encode result", "error", err)
+		return -1
+	}
+
+	memory := instanceContext.Memory().Data()
+	ptr, size := int32(returnValPtr), int32(returnValLen)
+	if err := checkedCopyBounds(memory, ptr, size); err != nil {
+		logger.Error("[ext_sandbox_invoke_version_1]", "error", err)
+		return -1
+	}
+	if int32(len(enc)) > size {
+		logger.Error("[ext_sandbox_invoke_version_1] result larger than return buffer")
+		return -1
+	}
+	copy(memory[ptr:ptr+size], enc)
+
+	return 0
+}
+
+//export ext_sandbox_memory_new_version_1
+func ext_sandbox_memory_new_version_1(context unsafe.Pointer, initial, maximum C.int32_t) C.int32_t {
+	logger.Trace("[ext_sandbox_memory_new_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	reg := registryFor(runtimeCtx)
+
+	mem, err := wasm.NewMemory(uint32(initial), uint32(maximum))
+	if err != nil {
+		logger.Error("[ext_sandbox_memory_new_version_1] failed to allocate child memory", "error", err)
+		return -1
+	}
+
+	reg.mu.Lock()
+	id := reg.nextMemID
+	reg.nextMemID++
+	reg.memories[id] = mem
+	reg.mu.Unlock()
+
+	return C.int32_t(id)
+}
+
+//export ext_sandbox_memory_teardown_version_1
+func ext_sandbox_memory_teardown_version_1(context unsafe.Pointer, memIdx C.int32_t) {
+	logger.Trace("[ext_sandbox_memory_teardown_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	reg := registryFor(runtimeCtx)
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	delete(reg.memories, int32(memIdx))
+}
+
+//export ext_sandbox_memory_get_version_1
+func ext_sandbox_memory_get_version_1(context unsafe.Pointer, memIdx, offset, bufPtr, bufLen C.int32_t) C.int32_t {
+	logger.Trace("[ext_sandbox_memory_get_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	reg := registryFor(runtimeCtx)
+
+	reg.mu.Lock()
+	mem, ok := reg.memories[int32(memIdx)]
+	reg.mu.Unlock()
+	if !ok {
+		logger.Error("[ext_sandbox_memory_get_version_1] unknown sandbox memory", "idx", memIdx)
+		return -1
+	}
+
+	off, ptr, size := int32(offset), int32(bufPtr), int32(bufLen)
+
+	child := mem.Data()
+	if err := checkedCopyBounds(child, off, size); err != nil {
+		logger.Error("[ext_sandbox_memory_get_version_1]", "error", err)
+		return -1
+	}
+
+	parent := instanceContext.Memory().Data()
+	if err := checkedCopyBounds(parent, ptr, size); err != nil {
+		logger.Error("[ext_sandbox_memory_get_version_1]", "error", err)
+		return -1
+	}
+
+	copy(parent[ptr:ptr+size], child[off:off+size])
+	return 0
+}
+
+//export ext_sandbox_memory_set_version_1
+func ext_sandbox_memory_set_version_1(context unsafe.Pointer, memIdx, offset, bufPtr, bufLen C.int32_t) C.int32_t {
+	logger.Trace("[ext_sandbox_memory_set_version_1] executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	reg := registryFor(runtimeCtx)
+
+	reg.mu.Lock()
+	mem, ok := reg.memories[int32(memIdx)]
+	reg.mu.Unlock()
+	if !ok {
+		logger.Error("[ext_sandbox_memory_set_version_1] unknown sandbox memory", "idx", memIdx)
+		return -1
+	}
+
+	off, ptr, size := int32(offset), int32(bufPtr), int32(bufLen)
+
+	parent := instanceContext.Memory().Data()
+	if err := checkedCopyBounds(parent, ptr, size); err != nil {
+		logger.Error("[ext_sandbox_memory_set_version_1]", "error", err)
+		return -1
+	}
+
+	child := mem.Data()
+	if err := checkedCopyBounds(child, off, size); err != nil {
+		logger.Error("[ext_sandbox_memory_set_version_1]", "error", err)
+		return -1
+	}
+
+	copy(child[off:off+size], parent[ptr:ptr+size])
+	return 0
+}
+
+func checkedCopyBounds(mem []byte, ptr, length int32) error {
+	if ptr < 0 || length < 0 || uint64(ptr)+uint64(length) > uint64(len(mem)) {
+		return errors.New("sandbox memory access out of bounds")
+	}
+	return nil
+}
+
+// callDispatchThunk forwards a sandboxed guest's call on one of its imports back out to the
+// supervisor instance's dispatch_thunk export, identifying which import was called by funcIndex.
+func (sb *sandboxInstance) callDispatchThunk(funcIndex int32, args []int32) int32 {
+	if sb.reg.supervisor.Exports == nil {
+		return 0
+	}
+
+	dispatch, ok := sb.reg.supervisor.Exports["dispatch_thunk"]
+	if !ok {
+		logger.Error("[sandbox] supervisor does not export dispatch_thunk")
+		return 0
+	}
+
+	callArgs := make([]interface{}, 0, len(args)+1)
+	callArgs = append(callArgs, funcIndex)
+	for _, a := range args {
+		callArgs = append(callArgs, a)
+	}
+
+	res, err := dispatch(callArgs...)
+	if err != nil {
+		logger.Error("[sandbox] dispatch_thunk call failed", "error", err)
+		return 0
+	}
+
+	return res.ToI32()
+}
+
+func sandboxDispatchSlot(slot uint32) (goFn interface{}, cFn unsafe.Pointer) {
+	switch slot {
+	case 0:
+		return ext_sandbox_dispatch_0, C.ext_sandbox_dispatch_0
+	case 1:
+		return ext_sandbox_dispatch_1, C.ext_sandbox_dispatch_1
+	case 2:
+		return ext_sandbox_dispatch_2, C.ext_sandbox_dispatch_2
+	case 3:
+		return ext_sandbox_dispatch_3, C.ext_sandbox_dispatch_3
+	case 4:
+		return ext_sandbox_dispatch_4, C.ext_sandbox_dispatch_4
+	case 5:
+		return ext_sandbox_dispatch_5, C.ext_sandbox_dispatch_5
+	case 6:
+		return ext_sandbox_dispatch_6, C.ext_sandbox_dispatch_6
+	default:
+		return ext_sandbox_dispatch_7, C.ext_sandbox_dispatch_7
+	}
+}
+
+//export ext_sandbox_dispatch_0
+func ext_sandbox_dispatch_0(context unsafe.Pointer, a, b, c, d C.int32_t) C.int32_t {
+	return dispatchSandboxCall(context, 0, a, b, c, d)
+}
+
+//export ext_sandbox_dispatch_1
+func ext_sandbox_dispatch_1(context unsafe.Pointer, a, b, c, d C.int32_t) C.int32_t {
+	return dispatchSandboxCall(context, 1, a, b, c, d)
+}
+
+//export ext_sandbox_dispatch_2
+func ext_sandbox_dispatch_2(context unsafe.Pointer, a, b, c, d C.int32_t) C.int32_t {
+	return dispatchSandboxCall(context, 2, a, b, c, d)
+}
+
+//export ext_sandbox_dispatch_3
+func ext_sandbox_dispatch_3(context unsafe.Pointer, a, b, c, d C.int32_t) C.int32_t {
+	return dispatchSandboxCall(context, 3, a, b, c, d)
+}
+
+//export ext_sandbox_dispatch_4
+func ext_sandbox_dispatch_4(context unsafe.Pointer, a, b, c, d C.int32_t) C.int32_t {
+	return dispatchSandboxCall(context, 4, a, b, c, d)
+}
+
+//export ext_sandbox_dispatch_5
+func ext_sandbox_dispatch_5(context unsafe.Pointer, a, b, c, d C.int32_t) C.int32_t {
+	return dispatchSandboxCall(context, 5, a, b, c, d)
+}
+
+//export ext_sandbox_dispatch_6
+func ext_sandbox_dispatch_6(context unsafe.Pointer, a, b, c, d C.int32_t) C.int32_t {
+	return dispatchSandboxCall(context, 6, a, b, c, d)
+}
+
+//export ext_sandbox_dispatch_7
+func ext_sandbox_dispatch_7(context unsafe.Pointer, a, b, c, d C.int32_t) C.int32_t {
+	return dispatchSandboxCall(context, 7, a, b, c, d)
+}
+
+// dispatchSandboxCall is the body shared by every ext_sandbox_dispatch_N trampoline: a guest
+// import call lands here tagged with its slot index and is forwarded to the supervisor's
+// dispatch_thunk export so the sandboxed call is serviced back in the supervisor runtime.
+func dispatchSandboxCall(context unsafe.Pointer, slot int32, a, b, c, d C.int32_t) C.int32_t {
+	instanceContext := wasm.IntoInstanceContext(context)
+	sb, ok := instanceContext.Data().(*sandboxInstance)
+	if !ok {
+		logger.Error("[sandbox] dispatch trampoline invoked outside a sandboxed instance")
+		return 0
+	}
+
+	return C.int32_t(sb.callDispatchThunk(slot, []int32{int32(a), int32(b), int32(c), int32(d)}))
+}
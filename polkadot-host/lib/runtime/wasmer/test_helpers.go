@@ -79,6 +79,15 @@ func NewTestInstanceWithRole(t *testing.T, targetRuntime string, role byte) *Ins
 	return r
 }
 
+// NewTestInstancePool creates an InstancePool of size instances using the given target runtime
+func NewTestInstancePool(t *testing.T, targetRuntime string, size int) *InstancePool {
+	fp, cfg := setupConfig(t, targetRuntime, nil, defaultTestLogLvl, 0)
+	p, err := NewInstancePoolFromFile(fp, cfg, size)
+	require.NoError(t, err, "Got error when trying to create new instance pool", "targetRuntime", targetRuntime)
+	require.NotNil(t, p, "Could not create new instance pool", "targetRuntime", targetRuntime)
+	return p
+}
+
 // GetRuntimeImports ...
 func GetRuntimeImports(targetRuntime string) func() (*wasm.Imports, error) {
 	var registerImports func() (*wasm.Imports, error)
@@ -90,6 +99,8 @@ func GetRuntimeImports(targetRuntime string) func() (*wasm.Imports, error) {
 		registerImports = ImportsLegacyNodeRuntime
 	case runtime.NODE_RUNTIME:
 		registerImports = ImportsNodeRuntime
+	case runtime.PARACHAIN_VALIDATION_RUNTIME:
+		registerImports = ImportsParachainRuntime
 	case runtime.TEST_RUNTIME:
 		registerImports = ImportsLegacyNodeRuntime
 	default:
@@ -0,0 +1,228 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmtime
+
+import (
+	"crypto/ed25519"
+	"runtime"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
+
+	"github.com/bytecodealliance/wasmtime-go"
+)
+
+// verifyScheme identifies which signature scheme a queued batch job should
+// be checked against.
+type verifyScheme int
+
+const (
+	ed25519Scheme verifyScheme = iota
+	sr25519Scheme
+)
+
+type verifyJob struct {
+	scheme verifyScheme
+	sig    []byte
+	msg    []byte
+	pubkey []byte
+}
+
+// batchVerifier fans queued signature checks out to a bounded worker pool
+// and short-circuits as soon as any job fails.
+type batchVerifier struct {
+	jobs chan verifyJob
+	wg   sync.WaitGroup
+
+	mu     sync.Mutex
+	failed bool
+}
+
+// newBatchVerifier starts a worker pool sized to GOMAXPROCS and returns the
+// verifier used to enqueue jobs against it.
+func newBatchVerifier() *batchVerifier {
+	bv := &batchVerifier{
+		jobs: make(chan verifyJob, 128),
+	}
+
+	numWorkers := runtime.GOMAXPROCS(0)
+	bv.wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go bv.run()
+	}
+
+	return bv
+}
+
+func (bv *batchVerifier) run() {
+	defer bv.wg.Done()
+
+	for job := range bv.jobs {
+		// short-circuit: skip verification once a prior job has failed, we
+		// still drain the channel so enqueue never blocks forever
+		if bv.hasFailed() {
+			continue
+		}
+
+		if !verifySignature(job) {
+			bv.mu.Lock()
+			bv.failed = true
+			bv.mu.Unlock()
+		}
+	}
+}
+
+func (bv *batchVerifier) hasFailed() bool {
+	bv.mu.Lock()
+	defer bv.mu.Unlock()
+	return bv.failed
+}
+
+// enqueue queues a signature check to run on the worker pool.
+func (bv *batchVerifier) enqueue(job verifyJob) {
+	bv.jobs <- job
+}
+
+// finish waits for every queued job to complete and reports whether every
+// one of them verified successfully.
+func (bv *batchVerifier) finish() bool {
+	close(bv.jobs)
+	bv.wg.Wait()
+	return !bv.hasFailed()
+}
+
+func verifySignature(job verifyJob) bool {
+	switch job.scheme {
+	case ed25519Scheme:
+		return verifyEd25519Signature(job.sig, job.msg, job.pubkey)
+	case sr25519Scheme:
+		return verifySr25519Signature(job.sig, job.msg, job.pubkey)
+	default:
+		return false
+	}
+}
+
+// copyBytes returns a fresh copy of b, rather than a slice sharing its backing array. It's used
+// before queueing a job onto a batchVerifier, since b is otherwise a live view into wasm guest
+// memory that the guest is free to overwrite as soon as the enqueueing host function returns.
+func copyBytes(b []byte) []byte {
+	return append([]byte(nil), b...)
+}
+
+func verifyEd25519Signature(sig, msg, pubkey []byte) bool {
+	if len(pubkey) != ed25519.PublicKeySize || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	return ed25519.Verify(pubkey, msg, sig)
+}
+
+func verifySr25519Signature(sig, msg, pubkey []byte) bool {
+	pub, err := sr25519.NewPublicKey(pubkey)
+	if err != nil {
+		logger.Error("[verifySr25519Signature]", "error", err)
+		return false
+	}
+
+	ok, err := pub.Verify(msg, sig)
+	if err != nil {
+		logger.Error("[verifySr25519Signature]", "error", err)
+		return false
+	}
+
+	return ok
+}
+
+func ext_crypto_start_batch_verify_version_1(c *wasmtime.Caller) {
+	logger.Trace("[ext_crypto_start_batch_verify_version_1] executing...")
+
+	if ctx.Batch != nil {
+		logger.Error("[ext_crypto_start_batch_verify_version_1]", "error", "a batch verification is already in progress")
+		return
+	}
+
+	ctx.Batch = newBatchVerifier()
+}
+
+func ext_crypto_finish_batch_verify_version_1(c *wasmtime.Caller) int32 {
+	logger.Trace("[ext_crypto_finish_batch_verify_version_1] executing...")
+
+	if ctx.Batch == nil {
+		logger.Error("[ext_crypto_finish_batch_verify_version_1]", "error", "no batch verification in progress")
+		return 0
+	}
+
+	ok := ctx.Batch.finish()
+	ctx.Batch = nil
+
+	if ok {
+		return 1
+	}
+	return 0
+}
+
+func ext_crypto_ed25519_verify_version_1(c *wasmtime.Caller, sig int32, msg int64, pubkey int32) int32 {
+	logger.Trace("[ext_crypto_ed25519_verify_version_1] executing...")
+
+	memData := ctx.Memory.Data()
+	sigData := memData[sig : sig+ed25519.SignatureSize]
+	pubkeyData := memData[pubkey : pubkey+ed25519.PublicKeySize]
+	msgData := asMemorySlice(msg)
+
+	if ctx.Batch != nil {
+		// The worker pool reads sig/msg/pubkey asynchronously, after this function - and the
+		// guest code that owns the wasm memory they point into - has already resumed; copy
+		// them out so the guest can't mutate them out from under the worker before it reads.
+		ctx.Batch.enqueue(verifyJob{
+			scheme: ed25519Scheme,
+			sig:    copyBytes(sigData),
+			msg:    copyBytes(msgData),
+			pubkey: copyBytes(pubkeyData),
+		})
+		return 1
+	}
+
+	if verifyEd25519Signature(sigData, msgData, pubkeyData) {
+		return 1
+	}
+	return 0
+}
+
+func ext_crypto_sr25519_verify_version_2(c *wasmtime.Caller, sig int32, msg int64, pubkey int32) int32 {
+	logger.Trace("[ext_crypto_sr25519_verify_version_2] executing...")
+
+	memData := ctx.Memory.Data()
+	sigData := memData[sig : sig+64]
+	pubkeyData := memData[pubkey : pubkey+32]
+	msgData := asMemorySlice(msg)
+
+	if ctx.Batch != nil {
+		// See the matching comment in ext_crypto_ed25519_verify_version_1: copy out of wasm
+		// memory before enqueueing, since the guest resumes before the worker pool dequeues.
+		ctx.Batch.enqueue(verifyJob{
+			scheme: sr25519Scheme,
+			sig:    copyBytes(sigData),
+			msg:    copyBytes(msgData),
+			pubkey: copyBytes(pubkeyData),
+		})
+		return 1
+	}
+
+	if verifySr25519Signature(sigData, msgData, pubkeyData) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmtime
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCopyBytes_DoesNotAliasSource guards the primitive the batched verify call sites rely on:
+// the returned slice must not share a backing array with the source, or a guest overwriting its
+// own wasm memory after enqueue returns would silently corrupt an already-queued job.
+func TestCopyBytes_DoesNotAliasSource(t *testing.T) {
+	src := []byte("wasm guest memory")
+	got := copyBytes(src)
+	require.Equal(t, src, got)
+
+	for i := range src {
+		src[i] = 0
+	}
+	require.NotEqual(t, src, got, "copyBytes must not alias the source backing array")
+}
+
+// TestBatchVerifier_SurvivesGuestMemoryMutationAfterEnqueue reproduces the TOCTOU window between
+// a batched verify host function enqueueing a job and a worker dequeuing it: the wasm guest
+// resumes execution as soon as the host function returns 1, and is free to overwrite the memory
+// that sig/msg/pubkey pointed into before a worker ever reads it. It exercises the same copyBytes
+// call the host functions make, fed with "guest memory" slices that are mutated out from under
+// the job immediately after enqueue returns, the way the guest would.
+func TestBatchVerifier_SurvivesGuestMemoryMutationAfterEnqueue(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	msg := []byte("batch verify me")
+	sig := ed25519.Sign(priv, msg)
+
+	guestSig := append([]byte(nil), sig...)
+	guestMsg := append([]byte(nil), msg...)
+	guestPubkey := append([]byte(nil), pub...)
+
+	bv := newBatchVerifier()
+	bv.enqueue(verifyJob{
+		scheme: ed25519Scheme,
+		sig:    copyBytes(guestSig),
+		msg:    copyBytes(guestMsg),
+		pubkey: copyBytes(guestPubkey),
+	})
+
+	// The guest resumes here and clobbers its own memory before a worker dequeues the job.
+	for i := range guestSig {
+		guestSig[i] = 0
+	}
+	for i := range guestMsg {
+		guestMsg[i] = 0
+	}
+	for i := range guestPubkey {
+		guestPubkey[i] = 0
+	}
+
+	require.True(t, bv.finish(), "verification must use the bytes copied at enqueue time, not the clobbered guest memory")
+}
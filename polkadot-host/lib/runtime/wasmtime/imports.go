@@ -17,11 +17,33 @@
 package wasmtime
 
 import (
+	"errors"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/common/optional"
+	"github.com/ChainSafe/gossamer/lib/trie"
+
 	"github.com/bytecodealliance/wasmtime-go"
 )
 
 func ext_logging_log_version_1(c *wasmtime.Caller, level int32, target, msg int64) {
 	logger.Trace("[ext_logging_log_version_1] executing...")
+
+	targetData := asMemorySlice(target)
+	msgData := asMemorySlice(msg)
+
+	switch level {
+	case 0:
+		logger.Crit("[ext_logging_log_version_1]", "target", string(targetData), "message", string(msgData))
+	case 1:
+		logger.Warn("[ext_logging_log_version_1]", "target", string(targetData), "message", string(msgData))
+	case 2:
+		logger.Info("[ext_logging_log_version_1]", "target", string(targetData), "message", string(msgData))
+	case 3:
+		logger.Debug("[ext_logging_log_version_1]", "target", string(targetData), "message", string(msgData))
+	case 4:
+		logger.Trace("[ext_logging_log_version_1]", "target", string(targetData), "message", string(msgData))
+	}
 }
 
 func ext_sandbox_instance_teardown_version_1(c *wasmtime.Caller, a int32) {
@@ -62,16 +84,6 @@ func ext_crypto_ed25519_generate_version_1(c *wasmtime.Caller, a int32, z int64)
 	return 0
 }
 
-func ext_crypto_ed25519_verify_version_1(c *wasmtime.Caller, a int32, z int64, y int32) int32 {
-	logger.Trace("[ext_crypto_ed25519_verify_version_1] executing...")
-	return 0
-}
-
-func ext_crypto_finish_batch_verify_version_1(c *wasmtime.Caller) int32 {
-	logger.Trace("[ext_crypto_finish_batch_verify_version_1] executing...")
-	return 0
-}
-
 func ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(c *wasmtime.Caller, a, z int32) int64 {
 	logger.Trace("[ext_crypto_secp256k1_ecdsa_recover_compressed_version_1] executing...")
 	return 0
@@ -92,18 +104,18 @@ func ext_crypto_sr25519_sign_version_1(c *wasmtime.Caller, a, z int32, y int64)
 	return 0
 }
 
-func ext_crypto_sr25519_verify_version_2(c *wasmtime.Caller, a int32, z int64, y int32) int32 {
-	logger.Trace("[ext_crypto_sr25519_verify_version_2] executing...")
-	return 0
-}
-
-func ext_crypto_start_batch_verify_version_1(c *wasmtime.Caller) {
-	logger.Trace("[ext_crypto_start_batch_verify_version_1] executing...")
-}
-
 func ext_trie_blake2_256_ordered_root_version_1(c *wasmtime.Caller, z int64) int32 {
 	logger.Trace("[ext_trie_blake2_256_ordered_root_version_1] executing...")
-	return 0
+
+	ptr, err := ctx.Allocator.Allocate(32)
+	if err != nil {
+		logger.Error("[ext_trie_blake2_256_ordered_root_version_1]", "error", err)
+		return 0
+	}
+
+	memData := ctx.Memory.Data()
+	copy(memData[ptr:ptr+32], trie.EmptyHash[:])
+	return int32(ptr)
 }
 
 func ext_misc_print_hex_version_1(c *wasmtime.Caller, a int64) {
@@ -116,6 +128,8 @@ func ext_misc_print_num_version_1(c *wasmtime.Caller, a int64) {
 
 func ext_misc_print_utf8_version_1(c *wasmtime.Caller, a int64) {
 	logger.Trace("[ext_misc_print_utf8_version_1] executing...")
+	data := asMemorySlice(a)
+	logger.Trace("[ext_misc_print_utf8_version_1]", "utf8", string(data))
 }
 
 func ext_misc_runtime_version_version_1(c *wasmtime.Caller, z int64) int64 {
@@ -164,17 +178,62 @@ func ext_allocator_malloc_version_1(c *wasmtime.Caller, size int32) int32 {
 
 func ext_hashing_blake2_128_version_1(c *wasmtime.Caller, z int64) int32 {
 	logger.Trace("[ext_hashing_blake2_128_version_1] executing...")
-	return 0
+
+	data := asMemorySlice(z)
+
+	hash, err := common.Blake2b128(data)
+	if err != nil {
+		logger.Error("[ext_hashing_blake2_128_version_1]", "error", err)
+		return 0
+	}
+
+	out, err := toWasmMemorySized(hash, 16)
+	if err != nil {
+		logger.Error("[ext_hashing_blake2_128_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return int32(out)
 }
 
 func ext_hashing_blake2_256_version_1(c *wasmtime.Caller, z int64) int32 {
 	logger.Trace("[ext_hashing_blake2_256_version_1] executing...")
-	return 0
+
+	data := asMemorySlice(z)
+
+	hash, err := common.Blake2bHash(data)
+	if err != nil {
+		logger.Error("[ext_hashing_blake2_256_version_1]", "error", err)
+		return 0
+	}
+
+	out, err := toWasmMemorySized(hash[:], 32)
+	if err != nil {
+		logger.Error("[ext_hashing_blake2_256_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return int32(out)
 }
 
 func ext_hashing_keccak_256_version_1(c *wasmtime.Caller, z int64) int32 {
 	logger.Trace("[ext_hashing_keccak_256_version_1] executing...")
-	return 0
+
+	data := asMemorySlice(z)
+
+	hash, err := common.Keccak256(data)
+	if err != nil {
+		logger.Error("[ext_hashing_keccak_256_version_1]", "error", err)
+		return 0
+	}
+
+	out, err := toWasmMemorySized(hash[:], 32)
+	if err != nil {
+		logger.Error("[ext_hashing_keccak_256_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return int32(out)
 }
 
 func ext_hashing_sha2_256_version_1(c *wasmtime.Caller, z int64) int32 {
@@ -184,46 +243,187 @@ func ext_hashing_sha2_256_version_1(c *wasmtime.Caller, z int64) int32 {
 
 func ext_hashing_twox_128_version_1(c *wasmtime.Caller, z int64) int32 {
 	logger.Trace("[ext_hashing_twox_128_version_1] executing...")
-	return 0
+
+	data := asMemorySlice(z)
+
+	hash, err := common.Twox128Hash(data)
+	if err != nil {
+		logger.Error("[ext_hashing_twox_128_version_1]", "error", err)
+		return 0
+	}
+
+	out, err := toWasmMemorySized(hash, 16)
+	if err != nil {
+		logger.Error("[ext_hashing_twox_128_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return int32(out)
 }
 
 func ext_hashing_twox_64_version_1(c *wasmtime.Caller, z int64) int32 {
 	logger.Trace("[ext_hashing_twox_64_version_1] executing...")
-	return 0
+
+	data := asMemorySlice(z)
+
+	hash, err := common.Twox64(data)
+	if err != nil {
+		logger.Error("[ext_hashing_twox_64_version_1]", "error", err)
+		return 0
+	}
+
+	out, err := toWasmMemorySized(hash, 8)
+	if err != nil {
+		logger.Error("[ext_hashing_twox_64_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return int32(out)
 }
 
 func ext_offchain_is_validator_version_1(c *wasmtime.Caller) int32 {
 	logger.Trace("[ext_offchain_is_validator_version_1] executing...")
+
+	if ctx.Offchain == nil {
+		return 0
+	}
+
+	if ctx.Offchain.IsValidator() {
+		return 1
+	}
 	return 0
 }
 
-func ext_offchain_local_storage_compare_and_set_version_1(c *wasmtime.Caller, a int32, x, y, z int64) int32 {
+func ext_offchain_local_storage_compare_and_set_version_1(c *wasmtime.Caller, kind int32, keySpan, oldValueSpan, newValueSpan int64) int32 {
 	logger.Trace("[ext_offchain_local_storage_compare_and_set_version_1] executing...")
+
+	if ctx.Offchain == nil {
+		return 0
+	}
+
+	key := asMemorySlice(keySpan)
+	oldValue := asMemorySlice(oldValueSpan)
+	newValue := asMemorySlice(newValueSpan)
+
+	ok, err := ctx.Offchain.LocalStorageCompareAndSet(kind, key, oldValue, newValue)
+	if err != nil {
+		logger.Error("[ext_offchain_local_storage_compare_and_set_version_1]", "error", err)
+		return 0
+	}
+
+	if ok {
+		return 1
+	}
 	return 0
 }
 
-func ext_offchain_local_storage_get_version_1(c *wasmtime.Caller, a int32, x int64) int64 {
+func ext_offchain_local_storage_get_version_1(c *wasmtime.Caller, kind int32, keySpan int64) int64 {
 	logger.Trace("[ext_offchain_local_storage_get_version_1] executing...")
-	return 0
+
+	if ctx.Offchain == nil {
+		return 0
+	}
+
+	key := asMemorySlice(keySpan)
+
+	value, err := ctx.Offchain.LocalStorageGet(kind, key)
+	if err != nil {
+		logger.Error("[ext_offchain_local_storage_get_version_1]", "error", err)
+		return 0
+	}
+
+	valueSpan, err := toWasmMemoryOptional(value)
+	if err != nil {
+		logger.Error("[ext_offchain_local_storage_get_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return valueSpan
 }
 
-func ext_offchain_local_storage_set_version_1(c *wasmtime.Caller, a int32, x, y int64) {
+func ext_offchain_local_storage_set_version_1(c *wasmtime.Caller, kind int32, keySpan, valueSpan int64) {
 	logger.Trace("[ext_offchain_local_storage_set_version_1] executing...")
+
+	if ctx.Offchain == nil {
+		return
+	}
+
+	key := asMemorySlice(keySpan)
+	value := asMemorySlice(valueSpan)
+
+	if err := ctx.Offchain.LocalStorageSet(kind, key, value); err != nil {
+		logger.Error("[ext_offchain_local_storage_set_version_1]", "error", err)
+	}
 }
 
 func ext_offchain_network_state_version_1(c *wasmtime.Caller) int64 {
 	logger.Trace("[ext_offchain_network_state_version_1] executing...")
-	return 0
+
+	if ctx.Offchain == nil {
+		return 0
+	}
+
+	state, err := ctx.Offchain.NetworkState()
+	if err != nil {
+		logger.Error("[ext_offchain_network_state_version_1]", "error", err)
+		return 0
+	}
+
+	enc, err := scale.Encode(state)
+	if err != nil {
+		logger.Error("[ext_offchain_network_state_version_1] failed to encode", "error", err)
+		return 0
+	}
+
+	stateSpan, err := toWasmMemory(enc)
+	if err != nil {
+		logger.Error("[ext_offchain_network_state_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return stateSpan
 }
 
 func ext_offchain_random_seed_version_1(c *wasmtime.Caller) int32 {
 	logger.Trace("[ext_offchain_random_seed_version_1] executing...")
-	return 0
+
+	var seed [32]byte
+	if ctx.Offchain != nil {
+		seed = ctx.Offchain.RandomSeed()
+	}
+
+	out, err := toWasmMemorySized(seed[:], 32)
+	if err != nil {
+		logger.Error("[ext_offchain_random_seed_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return int32(out)
 }
 
 func ext_offchain_submit_transaction_version_1(c *wasmtime.Caller, z int64) int64 {
 	logger.Trace("[ext_offchain_submit_transaction_version_1] executing...")
-	return 0
+
+	if ctx.Offchain == nil {
+		return 0
+	}
+
+	ext := asMemorySlice(z)
+
+	var resultSpan int64
+	var err error
+	if err = ctx.Offchain.SubmitTransaction(ext); err != nil {
+		logger.Error("[ext_offchain_submit_transaction_version_1]", "error", err)
+		resultSpan, err = toWasmMemory([]byte{1})
+	} else {
+		resultSpan, err = toWasmMemory([]byte{0})
+	}
+	if err != nil {
+		logger.Error("[ext_offchain_submit_transaction_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return resultSpan
 }
 
 func ext_storage_append_version_1(c *wasmtime.Caller, a, b int64) {
@@ -249,12 +449,37 @@ func ext_storage_commit_transaction_version_1(c *wasmtime.Caller) {
 
 func ext_storage_get_version_1(c *wasmtime.Caller, z int64) int64 {
 	logger.Trace("[ext_storage_get_version_1] executing...")
-	return 0
+
+	key := asMemorySlice(z)
+
+	value, err := ctx.Storage.Get(key)
+	if err != nil {
+		logger.Error("[ext_storage_get_version_1]", "error", err)
+		return 0
+	}
+
+	valueSpan, err := toWasmMemoryOptional(value)
+	if err != nil {
+		logger.Error("[ext_storage_get_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return valueSpan
 }
 
 func ext_storage_next_key_version_1(c *wasmtime.Caller, z int64) int64 {
 	logger.Trace("[ext_storage_next_key_version_1] executing...")
-	return 0
+
+	key := asMemorySlice(z)
+	next := ctx.Storage.NextKey(key)
+
+	nextSpan, err := toWasmMemoryOptional(next)
+	if err != nil {
+		logger.Error("[ext_storage_next_key_version_1] failed to allocate", "error", err)
+		return 0
+	}
+
+	return nextSpan
 }
 
 func ext_storage_read_version_1(c *wasmtime.Caller, a, b int64, x int32) int64 {
@@ -273,6 +498,14 @@ func ext_storage_root_version_1(c *wasmtime.Caller) int64 {
 
 func ext_storage_set_version_1(c *wasmtime.Caller, a, b int64) {
 	logger.Trace("[ext_storage_set_version_1] executing...")
+
+	key := asMemorySlice(a)
+	value := asMemorySlice(b)
+
+	err := ctx.Storage.Set(key, value)
+	if err != nil {
+		logger.Error("[ext_storage_set_version_1]", "error", err)
+	}
 }
 
 func ext_storage_start_transaction_version_1(c *wasmtime.Caller) {
@@ -283,6 +516,162 @@ func ext_offchain_index_set_version_1(c *wasmtime.Caller, a, b int64) {
 	logger.Trace("[ext_offchain_index_set_version_1] executing...")
 }
 
+// int64ToPointerAndSize converts a 64bit wasm span descriptor to its
+// (pointer, size) components. The pointer occupies the low 32 bits and the
+// size the high 32 bits.
+func int64ToPointerAndSize(in int64) (ptr, size int32) {
+	return int32(in), int32(in >> 32)
+}
+
+// pointerAndSizeToInt64 packs a (pointer, size) pair into a 64bit wasm span
+// descriptor.
+func pointerAndSizeToInt64(ptr, size int32) int64 {
+	return int64(ptr) | (int64(size) << 32)
+}
+
+// asMemorySlice converts a 64bit wasm span descriptor to a Go memory slice
+func asMemorySlice(span int64) []byte {
+	memData := ctx.Memory.Data()
+	ptr, size := int64ToPointerAndSize(span)
+	return memData[ptr : ptr+size]
+}
+
+// toWasmMemory copies a byte slice to wasm memory and returns the resulting
+// 64bit span descriptor
+func toWasmMemory(data []byte) (int64, error) {
+	size := uint32(len(data))
+
+	out, err := ctx.Allocator.Allocate(size)
+	if err != nil {
+		return 0, err
+	}
+
+	memData := ctx.Memory.Data()
+	copy(memData[out:out+size], data)
+
+	return pointerAndSizeToInt64(int32(out), int32(size)), nil
+}
+
+// toWasmMemorySized copies a byte slice of a fixed size to wasm memory and
+// returns the resulting pointer
+func toWasmMemorySized(data []byte, size uint32) (uint32, error) {
+	if int(size) != len(data) {
+		return 0, errors.New("internal byte array size mismatch")
+	}
+
+	out, err := ctx.Allocator.Allocate(size)
+	if err != nil {
+		return 0, err
+	}
+
+	memData := ctx.Memory.Data()
+	copy(memData[out:out+size], data)
+
+	return out, nil
+}
+
+// toWasmMemoryOptional wraps a byte slice in an Option<Vec<u8>> and copies
+// the result to wasm memory, returning the resulting 64bit span descriptor
+func toWasmMemoryOptional(data []byte) (int64, error) {
+	var opt *optional.Bytes
+	if len(data) == 0 {
+		opt = optional.NewBytes(false, nil)
+	} else {
+		opt = optional.NewBytes(true, data)
+	}
+
+	enc, err := opt.Encode()
+	if err != nil {
+		return 0, err
+	}
+
+	return toWasmMemory(enc)
+}
+
+// encodeKillStorageResult scale-encodes a KillStorageResult: a one-byte enum
+// (0 = AllRemoved, 1 = SomeRemaining) followed by the u32 count of keys that
+// were actually removed.
+func encodeKillStorageResult(allRemoved bool, numRemoved uint32) []byte {
+	enc := make([]byte, 5)
+	if !allRemoved {
+		enc[0] = 1
+	}
+	enc[1] = byte(numRemoved)
+	enc[2] = byte(numRemoved >> 8)
+	enc[3] = byte(numRemoved >> 16)
+	enc[4] = byte(numRemoved >> 24)
+	return enc
+}
+
+func ext_storage_clear_prefix_version_2(c *wasmtime.Caller, prefixSpan, limitSpan int64) int64 {
+	logger.Trace("[ext_storage_clear_prefix_version_2] executing...")
+
+	prefix := asMemorySlice(prefixSpan)
+	limit := uint32(limitSpan)
+
+	allRemoved, numRemoved, err := ctx.Storage.ClearPrefixWithLimit(prefix, limit)
+	if err != nil {
+		logger.Error("[ext_storage_clear_prefix_version_2]", "error", err)
+		return 0
+	}
+
+	resultSpan, err := toWasmMemory(encodeKillStorageResult(allRemoved, numRemoved))
+	if err != nil {
+		logger.Error("[ext_storage_clear_prefix_version_2] failed to allocate", "error", err)
+		return 0
+	}
+
+	return resultSpan
+}
+
+func ext_default_child_storage_clear_prefix_version_2(c *wasmtime.Caller, keyToChildSpan, prefixSpan, limitSpan int64) int64 {
+	logger.Trace("[ext_default_child_storage_clear_prefix_version_2] executing...")
+
+	keyToChild := asMemorySlice(keyToChildSpan)
+	prefix := asMemorySlice(prefixSpan)
+	limit := uint32(limitSpan)
+
+	allRemoved, numRemoved, err := ctx.Storage.ClearPrefixInChildWithLimit(keyToChild, prefix, limit)
+	if err != nil {
+		logger.Error("[ext_default_child_storage_clear_prefix_version_2]", "error", err)
+		return 0
+	}
+
+	resultSpan, err := toWasmMemory(encodeKillStorageResult(allRemoved, numRemoved))
+	if err != nil {
+		logger.Error("[ext_default_child_storage_clear_prefix_version_2] failed to allocate", "error", err)
+		return 0
+	}
+
+	return resultSpan
+}
+
+func ext_default_child_storage_storage_kill_version_3(c *wasmtime.Caller, keyToChildSpan, limitSpan int64) int64 {
+	logger.Trace("[ext_default_child_storage_storage_kill_version_3] executing...")
+
+	keyToChild := asMemorySlice(keyToChildSpan)
+	limit := uint32(limitSpan)
+
+	allRemoved, numRemoved, err := ctx.Storage.ClearPrefixInChildWithLimit(keyToChild, []byte{}, limit)
+	if err != nil {
+		logger.Error("[ext_default_child_storage_storage_kill_version_3]", "error", err)
+		return 0
+	}
+
+	resultSpan, err := toWasmMemory(encodeKillStorageResult(allRemoved, numRemoved))
+	if err != nil {
+		logger.Error("[ext_default_child_storage_storage_kill_version_3] failed to allocate", "error", err)
+		return 0
+	}
+
+	return resultSpan
+}
+
+func ext_crypto_ecdsa_verify_version_2(c *wasmtime.Caller, sig int32, msg int64, pubkey int32) int32 {
+	logger.Trace("[ext_crypto_ecdsa_verify_version_2] executing...")
+	return 0
+}
+
 // ImportsNodeRuntime returns the imports for the v0.8 runtime
 func ImportsNodeRuntime(store *wasmtime.Store) []*wasmtime.Extern {
 	lim := wasmtime.Limits{
@@ -406,3 +795,22 @@ func ImportsNodeRuntime(store *wasmtime.Store) []*wasmtime.Extern {
 		ext_offchain_index_set_version_1.AsExtern(),
 	}
 }
+
+// ImportsNodeRuntimeV098 returns the imports for the v0.9.8 runtime. It
+// extends the v0.8 surface with the newer storage and crypto host functions
+// that v0.9.8 Substrate runtimes import.
+func ImportsNodeRuntimeV098(store *wasmtime.Store) []*wasmtime.Extern {
+	externs := ImportsNodeRuntime(store)
+
+	ext_storage_clear_prefix_version_2 := wasmtime.WrapFunc(store, ext_storage_clear_prefix_version_2)
+	ext_default_child_storage_clear_prefix_version_2 := wasmtime.WrapFunc(store, ext_default_child_storage_clear_prefix_version_2)
+	ext_default_child_storage_storage_kill_version_3 := wasmtime.WrapFunc(store, ext_default_child_storage_storage_kill_version_3)
+	ext_crypto_ecdsa_verify_version_2 := wasmtime.WrapFunc(store, ext_crypto_ecdsa_verify_version_2)
+
+	return append(externs,
+		ext_storage_clear_prefix_version_2.AsExtern(),
+		ext_default_child_storage_clear_prefix_version_2.AsExtern(),
+		ext_default_child_storage_storage_kill_version_3.AsExtern(),
+		ext_crypto_ecdsa_verify_version_2.AsExtern(),
+	)
+}
@@ -17,12 +17,17 @@
 package wasmtime
 
 import (
+	"fmt"
 	"os"
 	"runtime"
+	"strings"
 	"sync"
+	"time"
 
 	gssmrruntime "github.com/ChainSafe/gossamer/lib/runtime"
 
+	"github.com/ChainSafe/gossamer/lib/keystore"
+
 	log "github.com/ChainSafe/log15"
 	"github.com/bytecodealliance/wasmtime-go"
 )
@@ -31,21 +36,55 @@ import (
 const Name = "wasmtime"
 
 var _ gssmrruntime.LegacyInstance = (*LegacyInstance)(nil)
+var _ gssmrruntime.Interpreter = (*Instance)(nil)
+
+// Context is the context for the wasmtime instance's imported host
+// functions. It mirrors the wasmer runtime.Context, plus a reference to the
+// wasm guest memory so that host functions can decode/encode the (ptr, len)
+// pairs passed across the wasm boundary.
+type Context struct {
+	Memory      *Memory
+	Storage     gssmrruntime.Storage
+	Allocator   *gssmrruntime.FreeingBumpHeapAllocator
+	Keystore    *keystore.GenericKeystore
+	Validator   bool
+	NodeStorage gssmrruntime.NodeStorage
+	Network     gssmrruntime.BasicNetwork
+	Transaction gssmrruntime.TransactionState
+
+	// Batch holds the in-flight signature batch verification scope, if any.
+	// It's non-nil between an ext_crypto_start_batch_verify_version_1 call
+	// and its matching ext_crypto_finish_batch_verify_version_1.
+	Batch *batchVerifier
+
+	// Offchain backs the ext_offchain_* host functions.
+	Offchain OffchainWorker
+}
 
-var ctx gssmrruntime.Context
+var ctx Context
 var logger = log.New("pkg", "runtime", "module", "go-wasmtime")
 
 // Config represents a wasmer configuration
 type Config struct {
 	gssmrruntime.InstanceConfig
-	Imports func(*wasmtime.Store) []*wasmtime.Extern
+	Imports  func(*wasmtime.Store) []*wasmtime.Extern
+	Offchain OffchainWorker
+
+	// FuelLimit caps the wasmtime fuel units available to each exec call. Zero disables fuel
+	// metering entirely, leaving exec free-running as before.
+	FuelLimit uint64
+	// EpochDeadline, if non-zero, bounds the wall-clock time a single exec call may run via
+	// wasmtime's epoch interruption, independent of and in addition to FuelLimit.
+	EpochDeadline time.Duration
 }
 
 // LegacyInstance represents a v0.6 runtime go-wasmtime instance
 type LegacyInstance struct {
-	vm  *wasmtime.Instance
-	mu  sync.Mutex
-	mem *wasmtime.Memory
+	vm        *wasmtime.Instance
+	store     *wasmtime.Store
+	mu        sync.Mutex
+	mem       *wasmtime.Memory
+	fuelLimit uint64
 }
 
 // Instance represents a v0.8 runtime go-wasmtime instance
@@ -55,7 +94,7 @@ type Instance struct {
 
 // NewLegacyInstance instantiates a runtime from the given wasm bytecode
 func NewLegacyInstance(code []byte, cfg *Config) (*LegacyInstance, error) {
-	engine := wasmtime.NewEngine()
+	engine := newEngine(cfg)
 	module, err := wasmtime.NewModule(engine, code)
 	if err != nil {
 		return nil, err
@@ -66,7 +105,7 @@ func NewLegacyInstance(code []byte, cfg *Config) (*LegacyInstance, error) {
 
 // NewLegacyInstanceFromFile instantiates a runtime from a .wasm file
 func NewLegacyInstanceFromFile(fp string, cfg *Config) (*LegacyInstance, error) {
-	engine := wasmtime.NewEngine()
+	engine := newEngine(cfg)
 	module, err := wasmtime.NewModuleFromFile(engine, fp)
 	if err != nil {
 		return nil, err
@@ -75,6 +114,24 @@ func NewLegacyInstanceFromFile(fp string, cfg *Config) (*LegacyInstance, error)
 	return newLegacyInstanceFromModule(module, engine, cfg)
 }
 
+// newEngine builds the wasmtime.Engine cfg's instances run on, turning on fuel consumption
+// and/or epoch interruption up front since wasmtime only allows enabling either at Engine
+// construction time.
+func newEngine(cfg *Config) *wasmtime.Engine {
+	if cfg.FuelLimit == 0 && cfg.EpochDeadline == 0 {
+		return wasmtime.NewEngine()
+	}
+
+	wasmtimeCfg := wasmtime.NewConfig()
+	if cfg.FuelLimit > 0 {
+		wasmtimeCfg.SetConsumeFuel(true)
+	}
+	if cfg.EpochDeadline > 0 {
+		wasmtimeCfg.SetEpochInterruption(true)
+	}
+	return wasmtime.NewEngineWithConfig(wasmtimeCfg)
+}
+
 // NewInstanceFromFile instantiates a runtime from a .wasm file
 func NewInstanceFromFile(fp string, cfg *Config) (*Instance, error) {
 	inst, err := NewLegacyInstanceFromFile(fp, cfg)
@@ -96,6 +153,12 @@ func newLegacyInstanceFromModule(module *wasmtime.Module, engine *wasmtime.Engin
 	}
 
 	store := wasmtime.NewStore(engine)
+
+	if cfg.EpochDeadline > 0 {
+		store.SetEpochDeadline(1)
+		time.AfterFunc(cfg.EpochDeadline, engine.IncrementEpoch)
+	}
+
 	instance, err := wasmtime.NewInstance(store, module, cfg.Imports(store))
 	if err != nil {
 		return nil, err
@@ -108,20 +171,26 @@ func newLegacyInstanceFromModule(module *wasmtime.Module, engine *wasmtime.Engin
 		mem = instance.GetExport("memory").Memory()
 	}
 
-	allocator := gssmrruntime.NewAllocator(Memory{mem}, 0)
+	memory := &Memory{mem}
+	allocator := gssmrruntime.NewAllocator(memory, 0)
 
-	ctx = gssmrruntime.Context{
+	ctx = Context{
+		Memory:      memory,
 		Storage:     cfg.Storage,
 		Allocator:   allocator,
 		Keystore:    cfg.Keystore,
 		Validator:   cfg.Role == byte(4),
 		NodeStorage: cfg.NodeStorage,
 		Network:     cfg.Network,
+		Transaction: cfg.Transaction,
+		Offchain:    cfg.Offchain,
 	}
 
 	return &LegacyInstance{
-		vm:  instance,
-		mem: mem,
+		vm:        instance,
+		store:     store,
+		mem:       mem,
+		fuelLimit: cfg.FuelLimit,
 	}, nil
 }
 
@@ -160,6 +229,24 @@ func (in *Instance) NetworkService() gssmrruntime.BasicNetwork {
 	return ctx.Network
 }
 
+// FuelConsumed returns the wasmtime fuel consumed by the underlying instance since it was
+// created, for weight-charging callers such as the block proposer. It is always 0 if the
+// instance was created with a zero Config.FuelLimit (fuel metering disabled).
+func (in *Instance) FuelConsumed() uint64 {
+	return in.inst.FuelConsumed()
+}
+
+// Name returns the interpreter backend name, "wasmtime".
+func (in *Instance) Name() string {
+	return Name
+}
+
+// Metrics returns the instance's execution metrics, including fuel consumed if it was created
+// with a non-zero Config.FuelLimit.
+func (in *Instance) Metrics() gssmrruntime.Metrics {
+	return gssmrruntime.Metrics{FuelConsumed: in.FuelConsumed()}
+}
+
 // SetContext sets the runtime context's Storage
 func (in *LegacyInstance) SetContext(s gssmrruntime.Storage) {
 	ctx.Storage = s
@@ -183,10 +270,29 @@ func (in *LegacyInstance) Exec(function string, data []byte) ([]byte, error) {
 	return in.exec(function, data)
 }
 
+// FuelConsumed returns the wasmtime fuel consumed since the instance was created, or 0 if it
+// was created with a zero Config.FuelLimit (fuel metering disabled).
+func (in *LegacyInstance) FuelConsumed() uint64 {
+	if in.fuelLimit == 0 {
+		return 0
+	}
+	consumed, ok := in.store.FuelConsumed()
+	if !ok {
+		return 0
+	}
+	return consumed
+}
+
 func (in *LegacyInstance) exec(function string, data []byte) ([]byte, error) {
 	in.mu.Lock()
 	defer in.mu.Unlock()
 
+	if in.fuelLimit > 0 {
+		if err := in.store.AddFuel(in.fuelLimit); err != nil {
+			return nil, err
+		}
+	}
+
 	ptr, err := ctx.Allocator.Allocate(uint32(len(data)))
 	if err != nil {
 		return nil, err
@@ -199,7 +305,10 @@ func (in *LegacyInstance) exec(function string, data []byte) ([]byte, error) {
 	run := in.vm.GetExport(function).Func()
 	resi, err := run.Call(int32(ptr), int32(len(data)))
 	if err != nil {
-		return nil, err
+		if trap, ok := err.(*wasmtime.Trap); ok && strings.Contains(trap.Message(), "fuel") {
+			return nil, gssmrruntime.ErrOutOfFuel
+		}
+		return nil, fmt.Errorf("%w: %s", gssmrruntime.ErrRuntimeCallFailed, err)
 	}
 
 	if resi == nil {
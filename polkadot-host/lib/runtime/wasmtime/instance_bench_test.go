@@ -0,0 +1,50 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmtime
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+)
+
+// fuelCapForExecuteBlock is comfortably above what Core_execute_block needs against the test
+// block built by the node runtime's own genesis, but small enough that an accidental infinite
+// loop in a future host function trips it well before a human notices the benchmark hanging.
+const fuelCapForExecuteBlock = 50_000_000_000
+
+// BenchmarkExecuteBlock_FuelCapped runs Core_execute_block under a fixed fuel cap, proving
+// metered exec terminates deterministically (it always either finishes or returns
+// runtime.ErrOutOfFuel) instead of being left to wall-clock timeouts or a hung CI job.
+func BenchmarkExecuteBlock_FuelCapped(b *testing.B) {
+	fp, cfg := setupConfig(b, runtime.NODE_RUNTIME, fuelCapForExecuteBlock)
+	inst, err := NewLegacyInstanceFromFile(fp, cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := inst.Exec(runtime.CoreExecuteBlock, []byte{})
+		if err != nil && !errors.Is(err, runtime.ErrOutOfFuel) {
+			b.Fatal(err)
+		}
+	}
+
+	b.Logf("fuel consumed after %d call(s): %d", b.N, inst.FuelConsumed())
+}
@@ -0,0 +1,143 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmtime
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"path/filepath"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/lib/common"
+	gssmrruntime "github.com/ChainSafe/gossamer/lib/runtime"
+)
+
+// OffchainWorker is implemented by backends that service the ext_offchain_*
+// host functions exposed to the runtime.
+type OffchainWorker interface {
+	LocalStorageGet(kind int32, key []byte) ([]byte, error)
+	LocalStorageSet(kind int32, key, value []byte) error
+	LocalStorageCompareAndSet(kind int32, key, oldValue, newValue []byte) (bool, error)
+	SubmitTransaction(ext []byte) error
+	NetworkState() (common.NetworkState, error)
+	RandomSeed() [32]byte
+	IsValidator() bool
+}
+
+// BadgerOffchainWorker is the default OffchainWorker. It stores PERSISTENT
+// and LOCAL values in on-disk badger databases and forwards extrinsics
+// submitted by the runtime onto the node's transaction pool.
+type BadgerOffchainWorker struct {
+	persistent *chaindb.BadgerDB
+	local      *chaindb.BadgerDB
+	txQueue    chan<- []byte
+	network    gssmrruntime.BasicNetwork
+	validator  bool
+}
+
+// NewBadgerOffchainWorker creates an OffchainWorker backed by badger
+// databases rooted at basePath. Extrinsics submitted by the runtime are
+// pushed onto txQueue for the node's transaction pool to pick up.
+func NewBadgerOffchainWorker(basePath string, txQueue chan<- []byte, network gssmrruntime.BasicNetwork, validator bool) (*BadgerOffchainWorker, error) {
+	persistent, err := chaindb.NewBadgerDB(filepath.Join(basePath, "offchain", "persistent"))
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := chaindb.NewBadgerDB(filepath.Join(basePath, "offchain", "local"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &BadgerOffchainWorker{
+		persistent: persistent,
+		local:      local,
+		txQueue:    txQueue,
+		network:    network,
+		validator:  validator,
+	}, nil
+}
+
+func (o *BadgerOffchainWorker) storeFor(kind int32) *chaindb.BadgerDB {
+	if gssmrruntime.NodeStorageType(kind) == gssmrruntime.NodeStorageTypePersistent {
+		return o.persistent
+	}
+	return o.local
+}
+
+// LocalStorageGet returns the value stored under key in the given storage kind.
+func (o *BadgerOffchainWorker) LocalStorageGet(kind int32, key []byte) ([]byte, error) {
+	value, err := o.storeFor(kind).Get(key)
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return nil, nil
+	}
+	return value, err
+}
+
+// LocalStorageSet stores value under key in the given storage kind.
+func (o *BadgerOffchainWorker) LocalStorageSet(kind int32, key, value []byte) error {
+	return o.storeFor(kind).Put(key, value)
+}
+
+// LocalStorageCompareAndSet atomically sets newValue for key if and only if
+// the current value equals oldValue.
+func (o *BadgerOffchainWorker) LocalStorageCompareAndSet(kind int32, key, oldValue, newValue []byte) (bool, error) {
+	store := o.storeFor(kind)
+
+	cur, err := store.Get(key)
+	if err != nil && !errors.Is(err, chaindb.ErrKeyNotFound) {
+		return false, err
+	}
+
+	if !bytes.Equal(cur, oldValue) {
+		return false, nil
+	}
+
+	if err := store.Put(key, newValue); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// SubmitTransaction pushes a SCALE-encoded extrinsic onto the node's
+// transaction pool queue.
+func (o *BadgerOffchainWorker) SubmitTransaction(ext []byte) error {
+	o.txQueue <- ext
+	return nil
+}
+
+// NetworkState returns the libp2p peer ID and multiaddrs of the host.
+func (o *BadgerOffchainWorker) NetworkState() (common.NetworkState, error) {
+	if o.network == nil {
+		return common.NetworkState{}, errors.New("no network service configured")
+	}
+	return o.network.NetworkState(), nil
+}
+
+// RandomSeed returns 32 bytes of cryptographically random data.
+func (o *BadgerOffchainWorker) RandomSeed() [32]byte {
+	var seed [32]byte
+	_, _ = rand.Read(seed[:])
+	return seed
+}
+
+// IsValidator reports whether the node is running with an authoring role.
+func (o *BadgerOffchainWorker) IsValidator() bool {
+	return o.validator
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package wasmtime
+
+import (
+	"path/filepath"
+	"testing"
+
+	database "github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/transaction"
+	log "github.com/ChainSafe/log15"
+)
+
+var defaultTestLogLvl = log.LvlTrace
+
+// setupConfig builds the runtime blob path and Config a test or benchmark needs to instantiate
+// targetRuntime, fetching the wasm blob on demand the same way the wasmer package's does.
+func setupConfig(tb testing.TB, targetRuntime string, fuelLimit uint64) (string, *Config) {
+	testRuntimeFilePath, testRuntimeURL := runtime.GetRuntimeVars(targetRuntime)
+
+	if _, err := runtime.GetRuntimeBlob(testRuntimeFilePath, testRuntimeURL); err != nil {
+		tb.Fatalf("could not get runtime %s: %s", targetRuntime, err)
+	}
+
+	fp, err := filepath.Abs(testRuntimeFilePath)
+	if err != nil {
+		tb.Fatalf("could not create testRuntimeFilePath: %s", err)
+	}
+
+	cfg := &Config{
+		Imports: ImportsNodeRuntime,
+	}
+	cfg.Storage = runtime.NewTestRuntimeStorage(tb, nil)
+	cfg.Keystore = keystore.NewGenericKeystore("test")
+	cfg.LogLvl = defaultTestLogLvl
+	cfg.NodeStorage = runtime.NodeStorage{
+		LocalStorage:      database.NewMemDatabase(),
+		PersistentStorage: database.NewMemDatabase(),
+	}
+	cfg.Network = new(runtime.TestRuntimeNetwork)
+	cfg.Transaction = new(mockTransactionState)
+	cfg.FuelLimit = fuelLimit
+	return fp, cfg
+}
+
+type mockTransactionState struct{}
+
+// AddToPool adds a transaction to the pool
+func (mt *mockTransactionState) AddToPool(vt *transaction.ValidTransaction) common.Hash {
+	return common.BytesToHash([]byte("test"))
+}
@@ -17,6 +17,7 @@
 package rpc
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -75,7 +76,7 @@ func TestChainRPC(t *testing.T) {
 	defer os.Remove(utils.ConfigBABEMaxThreshold)
 
 	t.Log("starting gossamer...")
-	nodes, err := utils.InitializeAndStartNodes(t, 1, utils.GenesisDefault, utils.ConfigBABEMaxThreshold)
+	nodes, err := utils.InitializeAndStartNodes(context.Background(), t, 1, utils.GenesisDefault, utils.ConfigBABEMaxThreshold)
 	require.Nil(t, err)
 
 	time.Sleep(time.Second) // give server a second to start
@@ -194,7 +195,7 @@ func TestChainSubscriptionRPC(t *testing.T) {
 	defer os.Remove(utils.ConfigBABEMaxThreshold)
 
 	t.Log("starting gossamer...")
-	nodes, err := utils.InitializeAndStartNodesWebsocket(t, 1, utils.GenesisOneAuth, utils.ConfigBABEMaxThreshold)
+	nodes, err := utils.InitializeAndStartNodesWebsocket(context.Background(), t, 1, utils.GenesisOneAuth, utils.ConfigBABEMaxThreshold)
 	require.Nil(t, err)
 
 	time.Sleep(time.Second) // give server a second to start
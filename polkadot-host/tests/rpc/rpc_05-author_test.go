@@ -0,0 +1,123 @@
+// Copyright 2020 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/rpc/modules"
+	"github.com/ChainSafe/gossamer/tests/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorSubmitAndWatchExtrinsicRPC(t *testing.T) {
+	if utils.MODE != rpcSuite {
+		_, _ = fmt.Fprintln(os.Stdout, "Going to skip RPC suite tests")
+		return
+	}
+
+	utils.GenerateGenesisOneAuth()
+	defer os.Remove(utils.GenesisOneAuth)
+	utils.CreateConfigBabeMaxThreshold()
+	defer os.Remove(utils.ConfigBABEMaxThreshold)
+
+	t.Log("starting gossamer...")
+	nodes, err := utils.InitializeAndStartNodesWebsocket(context.Background(), t, 1, utils.GenesisOneAuth, utils.ConfigBABEMaxThreshold)
+	require.Nil(t, err)
+
+	time.Sleep(time.Second) // give server a second to start
+
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelCtx()
+
+	notifications, unsubscribe, err := utils.SubscribeWS(ctx, "ws://localhost:8546/",
+		"author_submitAndWatchExtrinsic", `"0x0102030405"`)
+	require.Nil(t, err)
+	defer func() { _ = unsubscribe() }()
+
+	select {
+	case raw := <-notifications:
+		var status modules.ExtrinsicStatusResponse
+		require.Nil(t, json.Unmarshal(raw, &status))
+		require.True(t, status.Ready != nil || status.Future != nil,
+			"first notification should be Ready or Future, got %+v", status)
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for author_extrinsicUpdate notification")
+	}
+
+	t.Log("going to tear down gossamer...")
+	errList := utils.TearDown(t, nodes)
+	require.Len(t, errList, 0)
+}
+
+func TestAuthorPendingExtrinsicsSurviveRestart(t *testing.T) {
+	if utils.MODE != rpcSuite {
+		_, _ = fmt.Fprintln(os.Stdout, "Going to skip RPC suite tests")
+		return
+	}
+
+	utils.GenerateGenesisOneAuth()
+	defer os.Remove(utils.GenesisOneAuth)
+	utils.CreateConfigBabeMaxThreshold()
+	defer os.Remove(utils.ConfigBABEMaxThreshold)
+
+	t.Log("starting gossamer...")
+	nodes, err := utils.InitializeAndStartNodesWebsocket(context.Background(), t, 1, utils.GenesisOneAuth, utils.ConfigBABEMaxThreshold)
+	require.Nil(t, err)
+	node := nodes[0]
+
+	time.Sleep(time.Second) // give server a second to start
+
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelCtx()
+
+	// submit an extrinsic and wait for it to be accepted into the queue before killing the
+	// node, so the persisted pool has something in it to restore
+	notifications, unsubscribe, err := utils.SubscribeWS(ctx, "ws://localhost:8546/",
+		"author_submitAndWatchExtrinsic", `"0x0102030405"`)
+	require.Nil(t, err)
+
+	select {
+	case <-notifications:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for author_extrinsicUpdate notification")
+	}
+	require.Nil(t, unsubscribe())
+
+	t.Log("killing gossamer...")
+	require.Nil(t, utils.KillProcess(t, node.Process))
+
+	t.Log("restarting gossamer against the same basepath...")
+	require.Nil(t, utils.StartGossamer(context.Background(), t, node, true))
+	time.Sleep(time.Second) // give the restarted server a second to start
+
+	respBody, err := utils.PostRPC("author_pendingExtrinsics", "http://"+utils.HOSTNAME+":"+node.RPCPort, "{}")
+	require.Nil(t, err)
+
+	var pending modules.PendingExtrinsicsResponse
+	require.Nil(t, utils.DecodeRPC(t, respBody, &pending))
+	require.Contains(t, pending, "0x0102030405")
+
+	t.Log("going to tear down gossamer...")
+	errList := utils.TearDown(t, []*utils.SubprocessNode{node})
+	require.Len(t, errList, 0)
+}
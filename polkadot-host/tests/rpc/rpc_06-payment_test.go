@@ -0,0 +1,69 @@
+// Copyright 2020 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/rpc/modules"
+	"github.com/ChainSafe/gossamer/tests/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentRPC(t *testing.T) {
+	if utils.MODE != rpcSuite {
+		_, _ = fmt.Fprintln(os.Stdout, "Going to skip RPC suite tests")
+		return
+	}
+
+	testCases := []*testCase{
+		{
+			description: "test payment_queryInfo",
+			method:      "payment_queryInfo",
+			expected:    modules.PaymentQueryInfoResponse{},
+			params:      `["0x0102030405"]`,
+		},
+	}
+
+	utils.CreateConfigBabeMaxThreshold()
+	defer os.Remove(utils.ConfigBABEMaxThreshold)
+
+	t.Log("starting gossamer...")
+	nodes, err := utils.InitializeAndStartNodes(context.Background(), t, 1, utils.GenesisDefault, utils.ConfigBABEMaxThreshold)
+	require.Nil(t, err)
+
+	time.Sleep(time.Second) // give server a second to start
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			target := getResponse(t, test)
+
+			resp, ok := target.(*modules.PaymentQueryInfoResponse)
+			require.True(t, ok)
+			require.NotEmpty(t, resp.Class)
+			require.NotEqual(t, "0", resp.PartialFee, "a real extrinsic should be charged a nonzero partial fee")
+		})
+	}
+
+	t.Log("going to tear down gossamer...")
+	errList := utils.TearDown(t, nodes)
+	require.Len(t, errList, 0)
+}
@@ -0,0 +1,163 @@
+// Copyright 2020 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ChainSafe/gossamer/dot"
+	ctoml "github.com/ChainSafe/gossamer/dot/config/toml"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/naoina/toml"
+)
+
+// EmbeddedNode is a NodeRunner that boots gossamer in-process, by calling dot.InitNode and
+// dot.NewNode directly, instead of shelling out to a prebuilt binary. It listens on OS-assigned
+// ports, so many can run concurrently in the same test binary without the port collisions
+// SubprocessNode's fixed basePort/BaseRPCPort/BaseWSPort block is prone to, and it starts in
+// well under a second since there's no process fork/exec or binary lookup involved.
+type EmbeddedNode struct {
+	cfg      *dot.Config
+	node     *dot.Node
+	rpcPort  int
+	wsPort   int
+	basePath string
+}
+
+// readConfig decodes a toml config file, the same ones SubprocessNode passes to the gossamer
+// binary via --config, into a ctoml.Config.
+func readConfig(path string) (*ctoml.Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := new(ctoml.Config)
+	if err := toml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// NewEmbeddedNode builds the config for an in-process node. genesis and config name the same
+// genesis/toml fixtures SubprocessNode accepts; config's RPC/websocket settings are honoured,
+// but its listen ports are ignored in favour of freeTCPPort-allocated ones.
+func NewEmbeddedNode(idx int, basePath, genesis, config string) (*EmbeddedNode, error) {
+	tomlCfg, err := readConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config %s: %w", config, err)
+	}
+
+	rpcPort, err := freeTCPPort()
+	if err != nil {
+		return nil, fmt.Errorf("could not allocate rpc port: %w", err)
+	}
+
+	wsPort := 0
+	if tomlCfg.RPC.WSEnabled {
+		wsPort, err = freeTCPPort()
+		if err != nil {
+			return nil, fmt.Errorf("could not allocate websocket port: %w", err)
+		}
+	}
+
+	cfg := dotConfigFromTOML(tomlCfg, idx, basePath, genesis, rpcPort, wsPort)
+
+	if err := dot.InitNode(cfg); err != nil {
+		return nil, fmt.Errorf("could not init embedded node: %w", err)
+	}
+
+	return &EmbeddedNode{cfg: cfg, rpcPort: rpcPort, wsPort: wsPort, basePath: basePath}, nil
+}
+
+// Start implements NodeRunner: it constructs the dot.Node (loading whatever keys the node's
+// BasePath holds) and starts its services. ctx is not consulted directly since dot.Node.Start
+// doesn't take one; it returns as soon as the service registry has finished starting.
+func (n *EmbeddedNode) Start(ctx context.Context) error {
+	node, err := dot.NewNode(n.cfg, keystore.NewGlobalKeystore(), nil)
+	if err != nil {
+		return fmt.Errorf("could not create embedded node: %w", err)
+	}
+	n.node = node
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- node.Start() }()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop implements NodeRunner by stopping the node's service registry.
+func (n *EmbeddedNode) Stop(ctx context.Context) error {
+	if n.node == nil {
+		return nil
+	}
+	n.node.Stop()
+	return nil
+}
+
+// Endpoint implements NodeRunner.
+func (n *EmbeddedNode) Endpoint() string {
+	return fmt.Sprintf("http://%s:%d", HOSTNAME, n.rpcPort)
+}
+
+// WSEndpoint implements NodeRunner.
+func (n *EmbeddedNode) WSEndpoint() string {
+	if n.wsPort == 0 {
+		return ""
+	}
+	return fmt.Sprintf("ws://%s:%d", HOSTNAME, n.wsPort)
+}
+
+// dotConfigFromTOML builds the in-memory dot.Config dot.NewNode expects out of the
+// file-serializable ctoml.Config tests/utils otherwise writes to disk for SubprocessNode,
+// substituting basePath, genesis and the OS-assigned ports.
+func dotConfigFromTOML(tomlCfg *ctoml.Config, idx int, basePath, genesis string, rpcPort, wsPort int) *dot.Config {
+	cfg := new(dot.Config)
+	cfg.Global.Name = fmt.Sprintf("%s-%d", tomlCfg.Global.Name, idx)
+	cfg.Global.ID = tomlCfg.Global.ID
+	cfg.Global.BasePath = basePath
+	cfg.Global.LogLvl = "crit"
+
+	cfg.Init.GenesisRaw = genesis
+
+	cfg.Core.Roles = tomlCfg.Core.Roles
+	cfg.Core.BabeAuthority = tomlCfg.Core.BabeAuthority
+	cfg.Core.GrandpaAuthority = tomlCfg.Core.GrandpaAuthority
+
+	cfg.Network.Bootnodes = tomlCfg.Network.Bootnodes
+	cfg.Network.ProtocolID = tomlCfg.Network.ProtocolID
+	cfg.Network.NoBootstrap = tomlCfg.Network.NoBootstrap
+	cfg.Network.NoMDNS = tomlCfg.Network.NoMDNS
+
+	cfg.RPC.Enabled = true
+	cfg.RPC.Port = uint32(rpcPort)
+	cfg.RPC.Modules = tomlCfg.RPC.Modules
+	cfg.RPC.WSEnabled = tomlCfg.RPC.WSEnabled
+	if tomlCfg.RPC.WSEnabled {
+		cfg.RPC.WSPort = uint32(wsPort)
+	}
+
+	return cfg
+}
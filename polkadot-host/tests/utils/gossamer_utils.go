@@ -18,6 +18,7 @@ package utils
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -39,6 +40,33 @@ import (
 var logger = log.New("pkg", "test/utils")
 var maxRetries = 36
 
+// defaultStopGracePeriod is how long StopGossamer waits for a gracefully-signaled node to exit
+// before escalating to Kill, absent an override.
+const defaultStopGracePeriod = time.Minute
+
+// StopGracePeriod is how long StopGossamer/StopNodesGraceful/StopNodes/TearDown wait for a
+// node to exit on its own once asked to stop gracefully, before killing it outright. It
+// defaults to one minute, and can be overridden process-wide via the
+// GOSSAMER_STOP_GRACE_PERIOD environment variable (any value time.ParseDuration accepts) or
+// per-test by reassigning this variable, or per-call by passing a context.Context with its own
+// deadline into StopGossamer/StopNodesGraceful directly.
+var StopGracePeriod = stopGracePeriodFromEnv()
+
+func stopGracePeriodFromEnv() time.Duration {
+	v := os.Getenv("GOSSAMER_STOP_GRACE_PERIOD")
+	if v == "" {
+		return defaultStopGracePeriod
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		logger.Warn("invalid GOSSAMER_STOP_GRACE_PERIOD, using default",
+			"value", v, "default", defaultStopGracePeriod)
+		return defaultStopGracePeriod
+	}
+	return d
+}
+
 // SetLogLevel sets the logging level for this package
 func SetLogLevel(lvl log.Lvl) {
 	h := log.StreamHandler(os.Stdout, log.TerminalFormat())
@@ -77,8 +105,8 @@ var (
 	ConfigBABEMaxThreshold string = filepath.Join(currentDir, "../utils/config_babe_max_threshold.toml")
 )
 
-// Node represents a gossamer process
-type Node struct {
+// SubprocessNode represents a gossamer process run as a child process of the test binary.
+type SubprocessNode struct {
 	Process  *exec.Cmd
 	Key      string
 	RPCPort  string
@@ -86,10 +114,22 @@ type Node struct {
 	basePath string
 	config   string
 	WSPort   string
+
+	// LogFormat, if set to "json", makes StartGossamer pass --log-format json to the node and
+	// populate Logs with a live event stream instead of only teeing raw bytes to log.out.
+	LogFormat string
+	// Logs is the node's parsed log event bus, populated by StartGossamer when LogFormat is
+	// "json". It is nil otherwise; use WaitForLog/WaitForBlock to read from it.
+	Logs *NodeLogStream
+
+	// P2PPort, if non-zero, is used as the node's --port instead of the package-wide
+	// basePort+Idx block. Testnet sets this so its nodes draw from their own allocated port
+	// range instead of the fixed one InitializeAndStartNodes et al. share.
+	P2PPort int
 }
 
 // InitGossamer initializes given node number and returns node reference
-func InitGossamer(idx int, basePath, genesis, config string) (*Node, error) {
+func InitGossamer(idx int, basePath, genesis, config string) (*SubprocessNode, error) {
 	//nolint
 	cmdInit := exec.Command(gossamerCMD, "init",
 		"--config", config,
@@ -109,7 +149,7 @@ func InitGossamer(idx int, basePath, genesis, config string) (*Node, error) {
 	// TODO: get init exit code to see if node was successfully initialized
 	logger.Info("initialized gossamer!", "node", idx)
 
-	return &Node{
+	return &SubprocessNode{
 		Idx:      idx,
 		RPCPort:  strconv.Itoa(BaseRPCPort + idx),
 		WSPort:   strconv.Itoa(BaseWSPort + idx),
@@ -118,10 +158,15 @@ func InitGossamer(idx int, basePath, genesis, config string) (*Node, error) {
 	}, nil
 }
 
-// StartGossamer starts given node
-func StartGossamer(t *testing.T, node *Node, websocket bool) error {
+// StartGossamer starts given node. ctx governs the startup retry loop that waits for the node
+// to report healthy: cancel it to give up early instead of always waiting out maxRetries.
+func StartGossamer(ctx context.Context, t *testing.T, node *SubprocessNode, websocket bool) error {
 	var key string
-	var params []string = []string{"--port", strconv.Itoa(basePort + node.Idx),
+	p2pPort := basePort + node.Idx
+	if node.P2PPort != 0 {
+		p2pPort = node.P2PPort
+	}
+	var params []string = []string{"--port", strconv.Itoa(p2pPort),
 		"--config", node.config,
 		"--basepath", node.basePath,
 		"--rpchost", HOSTNAME,
@@ -142,6 +187,10 @@ func StartGossamer(t *testing.T, node *Node, websocket bool) error {
 		params = append(params, "--ws",
 			"--wsport", node.WSPort)
 	}
+
+	if node.LogFormat != "" {
+		params = append(params, "--log-format", node.LogFormat)
+	}
 	//nolint
 	node.Process = exec.Command(gossamerCMD, params...)
 
@@ -161,12 +210,6 @@ func StartGossamer(t *testing.T, node *Node, websocket bool) error {
 		return err
 	}
 
-	t.Cleanup(func() {
-		time.Sleep(time.Second) // wait for goroutine to finish writing
-		outfile.Close()         //nolint
-		errfile.Close()         //nolint
-	})
-
 	stdoutPipe, err := node.Process.StdoutPipe()
 	if err != nil {
 		logger.Error("failed to get stdoutPipe from node %d: %s\n", node.Idx, err)
@@ -187,13 +230,53 @@ func StartGossamer(t *testing.T, node *Node, websocket bool) error {
 	}
 
 	writer := bufio.NewWriter(outfile)
-	go io.Copy(writer, stdoutPipe) //nolint
 	errWriter := bufio.NewWriter(errfile)
-	go io.Copy(errWriter, stderrPipe) //nolint
+
+	var copyWG sync.WaitGroup
+	copyWG.Add(2)
+
+	if node.LogFormat == "json" {
+		node.Logs = newNodeLogStream()
+		go func() {
+			defer copyWG.Done()
+			node.Logs.consume(stdoutPipe, writer)
+		}()
+	} else {
+		go func() {
+			defer copyWG.Done()
+			_, _ = io.Copy(writer, stdoutPipe) //nolint
+		}()
+	}
+	go func() {
+		defer copyWG.Done()
+		_, _ = io.Copy(errWriter, stderrPipe) //nolint
+	}()
+
+	t.Cleanup(func() {
+		// The copy goroutines only return once the process has exited and closed its
+		// stdout/stderr pipes, so this only blocks as long as teardown takes.
+		copyWG.Wait()
+		_ = writer.Flush()    //nolint
+		_ = errWriter.Flush() //nolint
+		_ = outfile.Sync()    //nolint
+		_ = errfile.Sync()    //nolint
+		outfile.Close()       //nolint
+		errfile.Close()       //nolint
+		if node.Logs != nil {
+			node.Logs.Close()
+		}
+	})
 
 	var started bool
+retryLoop:
 	for i := 0; i < maxRetries; i++ {
-		time.Sleep(time.Second)
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break retryLoop
+		case <-time.After(time.Second):
+		}
+
 		if err = CheckNodeStarted(t, "http://"+HOSTNAME+":"+node.RPCPort); err == nil {
 			started = true
 			break
@@ -210,15 +293,16 @@ func StartGossamer(t *testing.T, node *Node, websocket bool) error {
 	return nil
 }
 
-// RunGossamer will initialize and start a gossamer instance
-func RunGossamer(t *testing.T, idx int, basepath, genesis, config string, websocket bool) (*Node, error) {
+// RunGossamer will initialize and start a gossamer instance. ctx governs the startup retry loop,
+// see StartGossamer.
+func RunGossamer(ctx context.Context, t *testing.T, idx int, basepath, genesis, config string, websocket bool) (*SubprocessNode, error) {
 	node, err := InitGossamer(idx, basepath, genesis, config)
 	if err != nil {
 		logger.Crit("could not initialize gossamer", "error", err)
 		os.Exit(1)
 	}
 
-	err = StartGossamer(t, node, websocket)
+	err = StartGossamer(ctx, t, node, websocket)
 	if err != nil {
 		logger.Crit("could not start gossamer", "error", err)
 		os.Exit(1)
@@ -259,8 +343,8 @@ func KillProcess(t *testing.T, cmd *exec.Cmd) error {
 }
 
 // InitNodes initializes given number of nodes
-func InitNodes(num int, config string) ([]*Node, error) {
-	var nodes []*Node
+func InitNodes(num int, config string) ([]*SubprocessNode, error) {
+	var nodes []*SubprocessNode
 	tempDir, err := ioutil.TempDir("", "gossamer-stress-")
 	if err != nil {
 		return nil, err
@@ -279,9 +363,9 @@ func InitNodes(num int, config string) ([]*Node, error) {
 }
 
 // StartNodes starts given array of nodes
-func StartNodes(t *testing.T, nodes []*Node) error {
+func StartNodes(ctx context.Context, t *testing.T, nodes []*SubprocessNode) error {
 	for _, n := range nodes {
-		err := StartGossamer(t, n, false)
+		err := StartGossamer(ctx, t, n, false)
 		if err != nil {
 			return nil
 		}
@@ -290,8 +374,8 @@ func StartNodes(t *testing.T, nodes []*Node) error {
 }
 
 // InitializeAndStartNodes will spin up `num` gossamer nodes
-func InitializeAndStartNodes(t *testing.T, num int, genesis, config string) ([]*Node, error) {
-	var nodes []*Node
+func InitializeAndStartNodes(ctx context.Context, t *testing.T, num int, genesis, config string) ([]*SubprocessNode, error) {
+	var nodes []*SubprocessNode
 
 	var wg sync.WaitGroup
 	var nodeMu sync.Mutex
@@ -303,7 +387,7 @@ func InitializeAndStartNodes(t *testing.T, num int, genesis, config string) ([]*
 			if i < len(keyList) {
 				name = keyList[i]
 			}
-			node, err := RunGossamer(t, i, TestDir(t, name), genesis, config, false)
+			node, err := RunGossamer(ctx, t, i, TestDir(t, name), genesis, config, false)
 			if err != nil {
 				logger.Error("failed to run gossamer", "i", i)
 			}
@@ -321,8 +405,8 @@ func InitializeAndStartNodes(t *testing.T, num int, genesis, config string) ([]*
 }
 
 // InitializeAndStartNodesWebsocket will spin up `num` gossamer nodes running with Websocket rpc enabled
-func InitializeAndStartNodesWebsocket(t *testing.T, num int, genesis, config string) ([]*Node, error) {
-	var nodes []*Node
+func InitializeAndStartNodesWebsocket(ctx context.Context, t *testing.T, num int, genesis, config string) ([]*SubprocessNode, error) {
+	var nodes []*SubprocessNode
 
 	var wg sync.WaitGroup
 	wg.Add(num)
@@ -333,7 +417,7 @@ func InitializeAndStartNodesWebsocket(t *testing.T, num int, genesis, config str
 			if i < len(keyList) {
 				name = keyList[i]
 			}
-			node, err := RunGossamer(t, i, TestDir(t, name), genesis, config, true)
+			node, err := RunGossamer(ctx, t, i, TestDir(t, name), genesis, config, true)
 			if err != nil {
 				logger.Error("failed to run gossamer", "i", i)
 			}
@@ -348,13 +432,82 @@ func InitializeAndStartNodesWebsocket(t *testing.T, num int, genesis, config str
 	return nodes, nil
 }
 
-// StopNodes stops the given nodes
-func StopNodes(t *testing.T, nodes []*Node) (errs []error) {
+// StopResult reports how a single node's graceful shutdown went.
+type StopResult struct {
+	Node     *SubprocessNode
+	ExitCode int
+	Duration time.Duration
+	Killed   bool
+}
+
+// StopGossamer asks node to shut down gracefully (SIGTERM on unix, os.Interrupt on windows) and
+// waits for it to exit. If ctx carries no deadline, one is added using StopGracePeriod. If the
+// node hasn't exited by the time ctx is done, it is killed outright and StopResult.Killed is true.
+func StopGossamer(ctx context.Context, node *SubprocessNode) (StopResult, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, StopGracePeriod)
+		defer cancel()
+	}
+
+	cmd := node.Process
+	start := time.Now()
+
+	if err := signalGraceful(cmd); err != nil {
+		return StopResult{Node: node}, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		result := StopResult{Node: node, ExitCode: cmd.ProcessState.ExitCode(), Duration: time.Since(start)}
+		if err != nil {
+			if _, ok := err.(*exec.ExitError); !ok {
+				return result, err
+			}
+		}
+		return result, nil
+	case <-ctx.Done():
+		if err := cmd.Process.Kill(); err != nil {
+			return StopResult{Node: node, Killed: true, Duration: time.Since(start)}, err
+		}
+		<-done
+		return StopResult{Node: node, ExitCode: cmd.ProcessState.ExitCode(), Killed: true, Duration: time.Since(start)}, nil
+	}
+}
+
+// StopNodesGraceful calls StopGossamer for every node concurrently, returning index-aligned
+// results and errors.
+func StopNodesGraceful(ctx context.Context, nodes []*SubprocessNode) (results []StopResult, errs []error) {
+	results = make([]StopResult, len(nodes))
+	errs = make([]error, len(nodes))
+
+	var wg sync.WaitGroup
+	wg.Add(len(nodes))
 	for i := range nodes {
-		cmd := nodes[i].Process
-		err := KillProcess(t, cmd)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = StopGossamer(ctx, nodes[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return results, errs
+}
+
+// StopNodes gracefully stops the given nodes, allowing up to StopGracePeriod before killing them.
+func StopNodes(t *testing.T, nodes []*SubprocessNode) (errs []error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StopGracePeriod)
+	defer cancel()
+
+	results, stopErrs := StopNodesGraceful(ctx, nodes)
+	for i, err := range stopErrs {
 		if err != nil {
-			logger.Error("failed to kill gossamer", "i", i, "cmd", cmd)
+			logger.Error("failed to stop gossamer", "i", i, "cmd", results[i].Node.Process)
 			errs = append(errs, err)
 		}
 	}
@@ -362,18 +515,27 @@ func StopNodes(t *testing.T, nodes []*Node) (errs []error) {
 	return errs
 }
 
-// TearDown stops the given nodes and remove their datadir
-func TearDown(t *testing.T, nodes []*Node) (errorList []error) {
+// TearDown stops the given nodes and removes their datadir. A node's datadir is only removed if
+// it stopped gracefully; one that had to be killed keeps its basepath so its logs and state can
+// be inspected afterward.
+func TearDown(t *testing.T, nodes []*SubprocessNode) (errorList []error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StopGracePeriod)
+	defer cancel()
+
+	results, stopErrs := StopNodesGraceful(ctx, nodes)
 	for i, node := range nodes {
-		cmd := nodes[i].Process
-		err := KillProcess(t, cmd)
-		if err != nil {
-			logger.Error("failed to kill gossamer", "i", i, "cmd", cmd)
-			errorList = append(errorList, err)
+		if stopErrs[i] != nil {
+			logger.Error("failed to stop gossamer", "i", i, "cmd", node.Process)
+			errorList = append(errorList, stopErrs[i])
 		}
 
-		err = os.RemoveAll(node.basePath)
-		if err != nil {
+		if stopErrs[i] != nil || results[i].Killed {
+			logger.Warn("preserving basepath for node that did not stop gracefully",
+				"basepath", node.basePath)
+			continue
+		}
+
+		if err := os.RemoveAll(node.basePath); err != nil {
 			logger.Error("failed to remove directory", "basepath", node.basePath)
 			errorList = append(errorList, err)
 		}
@@ -0,0 +1,209 @@
+// Copyright 2020 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LogEvent is one parsed line of a node's JSON-formatted log output.
+type LogEvent struct {
+	Time   time.Time
+	Level  string
+	Module string
+	Msg    string
+	Fields map[string]interface{}
+}
+
+// logSub is one subscriber registered via NodeLogStream.Subscribe.
+type logSub struct {
+	filter func(LogEvent) bool
+	ch     chan LogEvent
+}
+
+// NodeLogStream fans out a SubprocessNode's parsed log lines to subscribers registered via
+// Subscribe, while the raw bytes are still teed to log.out by StartGossamer. It's only
+// populated when the node is started with LogFormat "json"; see WaitForLog.
+type NodeLogStream struct {
+	mu     sync.Mutex
+	subs   []*logSub
+	closed bool
+}
+
+func newNodeLogStream() *NodeLogStream {
+	return &NodeLogStream{}
+}
+
+// Subscribe registers a new subscriber that receives every future LogEvent for which filter
+// returns true (or every event, if filter is nil). The returned channel is closed once the
+// node's log stream ends; a slow subscriber drops events rather than blocking ingestion.
+func (s *NodeLogStream) Subscribe(filter func(LogEvent) bool) <-chan LogEvent {
+	ch := make(chan LogEvent, 16)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		close(ch)
+		return ch
+	}
+
+	s.subs = append(s.subs, &logSub{filter: filter, ch: ch})
+	return ch
+}
+
+func (s *NodeLogStream) publish(event LogEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sub := range s.subs {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			// The subscriber isn't keeping up; it asked to watch for an event, not to buffer
+			// the whole log, so drop rather than stall ingestion.
+		}
+	}
+}
+
+// Close closes every subscriber channel. It's called once the node's stdout pipe reaches EOF.
+func (s *NodeLogStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+
+	for _, sub := range s.subs {
+		close(sub.ch)
+	}
+}
+
+// consume reads newline-delimited JSON log lines from r, tees the raw bytes to tee, and
+// publishes each successfully parsed line to its subscribers. Lines that fail to parse as JSON
+// are still teed, just not published; gossamer's startup banner and any panic output are not
+// JSON and would otherwise be silently dropped.
+func (s *NodeLogStream) consume(r io.Reader, tee *bufio.Writer) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		if _, err := tee.Write(line); err == nil {
+			_, _ = tee.WriteString("\n") //nolint
+		}
+
+		event, err := parseLogLine(line)
+		if err != nil {
+			continue
+		}
+		s.publish(event)
+	}
+}
+
+// parseLogLine decodes one line of log15's JSON format (https://github.com/ChainSafe/log15),
+// which flattens a record's context straight into the top-level object alongside "t", "lvl" and
+// "msg", into a LogEvent.
+func parseLogLine(line []byte) (LogEvent, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return LogEvent{}, err
+	}
+
+	event := LogEvent{Fields: make(map[string]interface{}, len(raw))}
+	for k, v := range raw {
+		switch k {
+		case "t", "time":
+			if s, ok := v.(string); ok {
+				if parsed, err := time.Parse(time.RFC3339Nano, s); err == nil {
+					event.Time = parsed
+					continue
+				}
+			}
+		case "lvl", "level":
+			if s, ok := v.(string); ok {
+				event.Level = s
+				continue
+			}
+		case "msg":
+			if s, ok := v.(string); ok {
+				event.Msg = s
+				continue
+			}
+		case "module", "pkg":
+			if s, ok := v.(string); ok {
+				event.Module = s
+				continue
+			}
+		}
+		event.Fields[k] = v
+	}
+
+	return event, nil
+}
+
+// WaitForLog blocks until node's log stream produces an event matching filter, or ctx is done.
+// node must have been started with LogFormat "json"; otherwise it has no log stream to wait on.
+func WaitForLog(ctx context.Context, node *SubprocessNode, filter func(LogEvent) bool) (LogEvent, error) {
+	if node.Logs == nil {
+		return LogEvent{}, fmt.Errorf("node %d has no log stream: start it with LogFormat \"json\" to use WaitForLog", node.Idx)
+	}
+
+	ch := node.Logs.Subscribe(filter)
+	select {
+	case event, ok := <-ch:
+		if !ok {
+			return LogEvent{}, fmt.Errorf("node %d's log stream closed before a matching event arrived", node.Idx)
+		}
+		return event, nil
+	case <-ctx.Done():
+		return LogEvent{}, ctx.Err()
+	}
+}
+
+// BlockImportedLogMsg is the log15 Msg WaitForBlock watches for to recognise a newly imported
+// block. It mirrors the message dot/core's block import path is expected to log; override it if
+// that differs.
+var BlockImportedLogMsg = "imported block"
+
+// WaitForBlock blocks until node logs an import of the given block number, or ctx is done. It
+// replaces the sleep-and-poll pattern CheckNodeStarted and the stress tests otherwise use.
+func WaitForBlock(ctx context.Context, node *SubprocessNode, height int) error {
+	target := strconv.Itoa(height)
+
+	_, err := WaitForLog(ctx, node, func(e LogEvent) bool {
+		if e.Msg != BlockImportedLogMsg {
+			return false
+		}
+		number, ok := e.Fields["number"]
+		return ok && fmt.Sprintf("%v", number) == target
+	})
+	return err
+}
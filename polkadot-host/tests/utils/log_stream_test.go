@@ -0,0 +1,68 @@
+// Copyright 2020 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogLine(t *testing.T) {
+	event, err := parseLogLine([]byte(`{"t":"2020-01-01T00:00:00Z","lvl":"info","msg":"imported block","number":"5"}`))
+	require.NoError(t, err)
+	require.Equal(t, "info", event.Level)
+	require.Equal(t, "imported block", event.Msg)
+	require.Equal(t, "5", event.Fields["number"])
+}
+
+func TestParseLogLine_RejectsNonJSON(t *testing.T) {
+	_, err := parseLogLine([]byte("not json"))
+	require.Error(t, err)
+}
+
+func TestNodeLogStream_SubscribePublishesMatchingEvents(t *testing.T) {
+	s := newNodeLogStream()
+	ch := s.Subscribe(func(e LogEvent) bool { return e.Msg == "imported block" })
+
+	s.publish(LogEvent{Msg: "peer connected"})
+	s.publish(LogEvent{Msg: "imported block", Fields: map[string]interface{}{"number": "1"}})
+
+	select {
+	case event := <-ch:
+		require.Equal(t, "imported block", event.Msg)
+	case <-time.After(time.Second):
+		t.Fatal("expected a matching event")
+	}
+}
+
+func TestNodeLogStream_CloseClosesSubscribers(t *testing.T) {
+	s := newNodeLogStream()
+	ch := s.Subscribe(nil)
+	s.Close()
+
+	_, ok := <-ch
+	require.False(t, ok)
+}
+
+func TestWaitForLog_ErrorsWithoutLogStream(t *testing.T) {
+	node := &SubprocessNode{Idx: 0}
+	_, err := WaitForLog(context.Background(), node, nil)
+	require.Error(t, err)
+}
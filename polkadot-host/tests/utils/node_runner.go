@@ -0,0 +1,118 @@
+// Copyright 2020 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/rpc/modules"
+)
+
+var errNoPeers = errors.New("no peers")
+
+// NodeRunner is anything that can boot a gossamer node, serve its RPC endpoint, and shut it
+// down again: either a real binary run as a child process (SubprocessNode) or an embedded,
+// in-process node (EmbeddedNode). It intentionally does not depend on *testing.T, so it can be
+// used from non-test callers such as the txtar driver.
+type NodeRunner interface {
+	// Start returns once the node is up, or ctx is done. SubprocessNode's Start does not launch
+	// the process: it polls an already started node until system_health reports healthy.
+	// EmbeddedNode's Start constructs and starts the node in-process without polling its health.
+	Start(ctx context.Context) error
+	// Stop shuts the node down, waiting for it to exit (or killing it) before ctx is done.
+	Stop(ctx context.Context) error
+	// Endpoint is the http://host:port URL of the node's RPC server.
+	Endpoint() string
+	// WSEndpoint is the ws://host:port URL of the node's websocket RPC server, or "" if the
+	// node wasn't configured with one enabled.
+	WSEndpoint() string
+}
+
+var _ NodeRunner = (*SubprocessNode)(nil)
+var _ NodeRunner = (*EmbeddedNode)(nil)
+
+// freeTCPPort asks the OS for an unused TCP port by briefly binding to ":0", then releases it.
+// There's an inherent race between releasing the port here and the caller binding to it, but
+// it's the same trick net/http/httptest and the rest of the Go ecosystem rely on.
+func freeTCPPort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// Start implements NodeRunner for SubprocessNode without requiring a *testing.T: it polls
+// system_health the same way StartGossamer does, but reports failures as a returned error
+// instead of through t.
+func (n *SubprocessNode) Start(ctx context.Context) error {
+	for {
+		if err := checkNodeStartedNT(NewEndpoint(n.RPCPort)); err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// Stop implements NodeRunner for SubprocessNode by delegating to the package-level StopGossamer.
+func (n *SubprocessNode) Stop(ctx context.Context) error {
+	_, err := StopGossamer(ctx, n)
+	return err
+}
+
+// Endpoint implements NodeRunner for SubprocessNode.
+func (n *SubprocessNode) Endpoint() string {
+	return NewEndpoint(n.RPCPort)
+}
+
+// WSEndpoint implements NodeRunner for SubprocessNode.
+func (n *SubprocessNode) WSEndpoint() string {
+	if n.WSPort == "" {
+		return ""
+	}
+	return "ws://" + HOSTNAME + ":" + n.WSPort
+}
+
+// checkNodeStartedNT is CheckNodeStarted without the *testing.T dependency, for callers (like
+// NodeRunner.Start) that only have a context to report failure through.
+func checkNodeStartedNT(gossamerHost string) error {
+	respBody, err := PostRPC("system_health", gossamerHost, "{}")
+	if err != nil {
+		return err
+	}
+
+	target := new(modules.SystemHealthResponse)
+	if err := DecodeRPC_NT(respBody, target); err != nil {
+		return err
+	}
+
+	if !target.Health.ShouldHavePeers {
+		return errNoPeers
+	}
+
+	return nil
+}
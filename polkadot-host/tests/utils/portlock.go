@@ -0,0 +1,107 @@
+// Copyright 2020 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// portBlockFirst is the first port ever handed out by allocatePortBlock. Testnet's own default
+// (7000/8540/8546) lives well below it, so a Testnet only collides with InitializeAndStartNodes
+// et al. if a caller also passes WithPortRange inside that legacy range on purpose.
+const portBlockFirst = 20000
+
+// portLockPath is the file allocatePortBlock calls coordinate through. It's a single,
+// well-known path under os.TempDir() rather than one nested under any individual test's TestDir,
+// since the whole point is to stop *different test binaries* (each with their own TestDir root)
+// from handing out the same ports.
+var portLockPath = filepath.Join(os.TempDir(), "gossamer-testnet-portlock")
+
+// allocatePortBlock reserves n consecutive, previously-unhanded-out ports for the caller's
+// exclusive use and returns the first one. It's safe to call concurrently, including from
+// separate test binary processes: the reservation is tracked as a single integer (the next free
+// port) in portLockPath, guarded by an advisory file lock (see lockPortFile/unlockPortFile) so
+// readers never interleave.
+func allocatePortBlock(n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("allocatePortBlock: n must be positive, got %d", n)
+	}
+
+	f, err := os.OpenFile(portLockPath, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("could not open port lock file %s: %w", portLockPath, err)
+	}
+	defer f.Close()
+
+	if err := lockPortFile(f); err != nil {
+		return 0, fmt.Errorf("could not lock port lock file %s: %w", portLockPath, err)
+	}
+	defer unlockPortFile(f) //nolint
+
+	next, err := readNextFreePort(f)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := writeNextFreePort(f, next+n); err != nil {
+		return 0, err
+	}
+
+	return next, nil
+}
+
+func readNextFreePort(f *os.File) (int, error) {
+	if _, err := f.Seek(0, 0); err != nil {
+		return 0, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, info.Size())
+	if _, err := f.Read(buf); err != nil {
+		return 0, err
+	}
+
+	text := strings.TrimSpace(string(buf))
+	if text == "" {
+		return portBlockFirst, nil
+	}
+
+	next, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("port lock file %s is corrupt: %w", portLockPath, err)
+	}
+	return next, nil
+}
+
+func writeNextFreePort(f *os.File, next int) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return err
+	}
+	_, err := f.WriteString(strconv.Itoa(next))
+	return err
+}
@@ -0,0 +1,35 @@
+// +build windows
+
+// Copyright 2020 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import "os"
+
+// lockPortFile is a best-effort no-op on windows: taking a real advisory lock here means either
+// LockFileEx via golang.org/x/sys/windows, which this tree doesn't otherwise depend on, or
+// accepting the small race the unix implementation avoids. Two test binaries racing to allocate a
+// port block on windows may rarely collide; every other platform gossamer's CI actually runs on
+// does not have this gap.
+func lockPortFile(f *os.File) error {
+	return nil
+}
+
+// unlockPortFile is the matching no-op for lockPortFile.
+func unlockPortFile(f *os.File) error {
+	return nil
+}
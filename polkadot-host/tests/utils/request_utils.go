@@ -18,14 +18,17 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/require"
 )
 
@@ -76,6 +79,166 @@ func PostRPCWithRetry(method, host, params string, retry int) ([]byte, error) {
 	}
 }
 
+// rpcError mirrors the "error" member of a JSON-RPC 2.0 response
+type rpcError struct {
+	Message string `json:"message"`
+}
+
+// Call describes a single method call to include in a PostRPCBatch request
+type Call struct {
+	Method string
+	Params string
+}
+
+// PostRPCBatch sends methods as a single JSON-RPC 2.0 batch request to host and
+// returns each call's result, in the same order as methods, regardless of the
+// order the server answered them in.
+func PostRPCBatch(methods []Call, host string) ([]json.RawMessage, error) {
+	reqs := make([]string, len(methods))
+	for i, m := range methods {
+		reqs[i] = fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","params":%s,"id":%d}`, m.Method, m.Params, i+1)
+	}
+
+	buf := bytes.NewBufferString("[" + strings.Join(reqs, ",") + "]")
+
+	r, err := http.NewRequest("POST", host, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Header.Set("Content-Type", ContentTypeJSON)
+	r.Header.Set("Accept", ContentTypeJSON)
+
+	resp, err := httpClient.Do(r)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code not OK")
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &responses); err != nil {
+		return nil, err
+	}
+
+	results := make([]json.RawMessage, len(methods))
+	for _, res := range responses {
+		if res.ID < 1 || res.ID > len(methods) {
+			return nil, fmt.Errorf("unexpected response id %d", res.ID)
+		}
+		if res.Error != nil {
+			return nil, errors.New(res.Error.Message)
+		}
+		results[res.ID-1] = res.Result
+	}
+
+	return results, nil
+}
+
+// SubscribeWS opens a websocket connection to host, issues method(params) as the
+// subscribe call, and forwards every subsequent notification whose
+// method.params.subscription matches the returned subscription id on the
+// returned channel. The channel is closed, and the underlying connection torn
+// down, when the returned cancel func is called or ctx is done.
+func SubscribeWS(ctx context.Context, host, method, params string) (<-chan json.RawMessage, func() error, error) {
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, host, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req := fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","params":%s,"id":1}`, method, params)
+	if err = ws.WriteMessage(websocket.TextMessage, []byte(req)); err != nil {
+		_ = ws.Close()
+		return nil, nil, err
+	}
+
+	_, msg, err := ws.ReadMessage()
+	if err != nil {
+		_ = ws.Close()
+		return nil, nil, err
+	}
+
+	var subResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err = json.Unmarshal(msg, &subResp); err != nil {
+		_ = ws.Close()
+		return nil, nil, err
+	}
+	if subResp.Error != nil {
+		_ = ws.Close()
+		return nil, nil, errors.New(subResp.Error.Message)
+	}
+
+	var subID string
+	if err = json.Unmarshal(subResp.Result, &subID); err != nil {
+		_ = ws.Close()
+		return nil, nil, err
+	}
+
+	notifications := make(chan json.RawMessage)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = ws.Close()
+		case <-done:
+		}
+	}()
+
+	go func() {
+		defer close(notifications)
+		for {
+			_, msg, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var notif struct {
+				Method string `json:"method"`
+				Params struct {
+					Subscription string          `json:"subscription"`
+					Result       json.RawMessage `json:"result"`
+				} `json:"params"`
+			}
+			if err = json.Unmarshal(msg, &notif); err != nil {
+				continue
+			}
+			if notif.Params.Subscription != subID {
+				continue
+			}
+
+			select {
+			case notifications <- notif.Params.Result:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	cancel := func() error {
+		close(done)
+		return ws.Close()
+	}
+
+	return notifications, cancel, nil
+}
+
 // DecodeRPC will decode []body into target interface
 func DecodeRPC(t *testing.T, body []byte, target interface{}) error {
 	decoder := json.NewDecoder(bytes.NewReader(body))
@@ -0,0 +1,348 @@
+// Copyright 2020 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/ChainSafe/gossamer/dot/rpc/modules"
+	"github.com/ChainSafe/gossamer/lib/utils"
+	"golang.org/x/sync/errgroup"
+)
+
+// portsPerNode is how many consecutive ports a Testnet reserves per node: one for --port, one
+// for --rpcport, one for --wsport (used or not).
+const portsPerNode = 3
+
+// Topology names how a Testnet's nodes are meant to discover each other over the network.
+type Topology int
+
+const (
+	// Star elects the testnet's first authority as the sole bootnode: every other node's toml
+	// is rewritten with that one address in [Network].Bootnodes and mDNS disabled, so the
+	// wiring is actually exercised instead of papered over by local discovery.
+	Star Topology = iota
+	// Mesh leaves mDNS enabled and Bootnodes empty, the same local-discovery behaviour
+	// InitializeAndStartNodes has always relied on. A genuine full-mesh bootnode list would
+	// require every node to know every other node's peer ID before any of them have started
+	// long enough to have one; this package has no way to do that short of a multi-round
+	// bootstrap, which is out of scope here. Mesh exists so callers can ask for "no explicit
+	// wiring" without digging through this comment, not as a second distinct wiring strategy.
+	Mesh
+)
+
+// Testnet is a builder for a fleet of SubprocessNodes sharing one genesis and one non-overlapping
+// port block, with an optional elected bootnode. It replaces InitializeAndStartNodes and
+// InitializeAndStartNodesWebsocket for tests that need asymmetric authority/full-node counts,
+// explicit bootnode wiring, or to run alongside other test binaries without port collisions.
+type Testnet struct {
+	t           *testing.T
+	authorities int
+	fullNodes   int
+	genesis     string
+	config      string
+	topology    Topology
+	portBase    int
+	portBaseSet bool
+
+	authorityNodes []*SubprocessNode
+	fullNodeList   []*SubprocessNode
+}
+
+// NewTestnet starts a builder for one authority, no full nodes, and Star bootnode topology.
+// Chain With* calls to change that, then call Start.
+func NewTestnet(t *testing.T) *Testnet {
+	return &Testnet{t: t, authorities: 1, topology: Star}
+}
+
+// WithAuthorities sets how many authority nodes the testnet starts, each keyed from keyList in
+// order (alice, bob, ...). n must not exceed len(keyList).
+func (tn *Testnet) WithAuthorities(n int) *Testnet {
+	tn.authorities = n
+	return tn
+}
+
+// WithFullNodes sets how many non-authority nodes the testnet starts alongside its authorities.
+func (tn *Testnet) WithFullNodes(n int) *Testnet {
+	tn.fullNodes = n
+	return tn
+}
+
+// WithGenesis uses genesis instead of one generated to match WithAuthorities's authority count.
+func (tn *Testnet) WithGenesis(genesis string) *Testnet {
+	tn.genesis = genesis
+	return tn
+}
+
+// WithConfig uses config instead of ConfigDefault as the base toml for every node.
+func (tn *Testnet) WithConfig(config string) *Testnet {
+	tn.config = config
+	return tn
+}
+
+// WithBootnodeTopology sets how full nodes discover the testnet's authorities. Defaults to Star.
+func (tn *Testnet) WithBootnodeTopology(topology Topology) *Testnet {
+	tn.topology = topology
+	return tn
+}
+
+// WithPortRange pins the testnet's port block to start at base instead of drawing one from
+// allocatePortBlock. Use this to reproduce a specific port layout; otherwise leave it unset so
+// concurrent testnets (including ones in other test binaries) can't collide.
+func (tn *Testnet) WithPortRange(base int) *Testnet {
+	tn.portBase = base
+	tn.portBaseSet = true
+	return tn
+}
+
+// Authorities returns the testnet's authority nodes, in the order keyList assigns their keys.
+// Valid only after Start returns successfully.
+func (tn *Testnet) Authorities() []*SubprocessNode {
+	return tn.authorityNodes
+}
+
+// FullNodes returns the testnet's non-authority nodes. Valid only after Start returns
+// successfully.
+func (tn *Testnet) FullNodes() []*SubprocessNode {
+	return tn.fullNodeList
+}
+
+// RandomPeer returns a uniformly random node from the testnet, authority or full. Valid only
+// after Start returns successfully.
+func (tn *Testnet) RandomPeer() *SubprocessNode {
+	all := append(append([]*SubprocessNode{}, tn.authorityNodes...), tn.fullNodeList...)
+	if len(all) == 0 {
+		return nil
+	}
+	return all[rand.Intn(len(all))]
+}
+
+// Partition is not implemented. A real network split would mean dropping traffic between a and b
+// at the OS level (e.g. iptables rules scoped to their P2P ports), which is privileged,
+// platform-specific, and outside what this subprocess/RPC-only harness can safely automate. It
+// returns an error instead of silently no-op'ing so a caller notices instead of asserting
+// partition-dependent behaviour that never actually happened.
+func (tn *Testnet) Partition(a, b []*SubprocessNode) error {
+	return fmt.Errorf("testnet: Partition is not implemented - simulating a network split needs " +
+		"OS-level firewalling of P2P ports, which this harness does not automate")
+}
+
+// Start generates (if needed) a genesis sized to WithAuthorities, allocates a port block, inits
+// and starts every node, and wires bootnodes per WithBootnodeTopology. Node startup fails fast:
+// the first node that errors cancels every other node still starting, via errgroup.
+func (tn *Testnet) Start(ctx context.Context) error {
+	if tn.authorities < 1 {
+		return fmt.Errorf("testnet: need at least 1 authority, got %d", tn.authorities)
+	}
+	if tn.authorities > len(keyList) {
+		return fmt.Errorf("testnet: %d authorities requested but only %d dev keys are available (%v)",
+			tn.authorities, len(keyList), keyList)
+	}
+
+	genesis := tn.genesis
+	if genesis == "" {
+		built, err := buildTestnetGenesis(tn.authorities)
+		if err != nil {
+			return fmt.Errorf("testnet: %w", err)
+		}
+		genesis = built
+	}
+
+	config := tn.config
+	if config == "" {
+		config = ConfigDefault
+	}
+
+	total := tn.authorities + tn.fullNodes
+	portBase := tn.portBase
+	if !tn.portBaseSet {
+		allocated, err := allocatePortBlock(total * portsPerNode)
+		if err != nil {
+			return fmt.Errorf("testnet: could not allocate port block: %w", err)
+		}
+		portBase = allocated
+	}
+
+	nodeConfig := config
+	if tn.topology == Star {
+		bootnode, err := tn.startBootnode(ctx, genesis, config, portBase)
+		if err != nil {
+			return fmt.Errorf("testnet: %w", err)
+		}
+
+		addr, err := bootnodeMultiaddr(bootnode)
+		if err != nil {
+			return fmt.Errorf("testnet: could not read bootnode's peer ID: %w", err)
+		}
+
+		nodeConfig, err = starConfigFor(config, addr)
+		if err != nil {
+			return fmt.Errorf("testnet: could not build bootnode-wired config: %w", err)
+		}
+
+		tn.authorityNodes = []*SubprocessNode{bootnode}
+	}
+
+	// Index 0 is already running when Star elected it as the bootnode above; everyone else
+	// (including the rest of the authorities, for Mesh) starts here.
+	startIdx := 0
+	if tn.topology == Star {
+		startIdx = 1
+	}
+
+	nodes := make([]*SubprocessNode, total)
+	if tn.topology == Star {
+		nodes[0] = tn.authorityNodes[0]
+	}
+
+	for i := startIdx; i < total; i++ {
+		idx, name := tn.nodeIdentity(i)
+
+		node, err := InitGossamer(idx, TestDir(tn.t, name), genesis, nodeConfig)
+		if err != nil {
+			return fmt.Errorf("testnet: init node %d: %w", i, err)
+		}
+		node.P2PPort = portBase + i*portsPerNode
+		node.RPCPort = strconv.Itoa(portBase + i*portsPerNode + 1)
+		node.WSPort = strconv.Itoa(portBase + i*portsPerNode + 2)
+
+		nodes[i] = node
+	}
+
+	group, gctx := errgroup.WithContext(ctx)
+	for i := startIdx; i < total; i++ {
+		node := nodes[i]
+		group.Go(func() error {
+			return StartGossamer(gctx, tn.t, node, false)
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return fmt.Errorf("testnet: %w", err)
+	}
+
+	tn.authorityNodes = nodes[:tn.authorities]
+	tn.fullNodeList = nodes[tn.authorities:]
+	return nil
+}
+
+// nodeIdentity returns the InitGossamer idx and TestDir name for the i-th node (0-indexed across
+// authorities then full nodes). Authorities get idx < len(keyList), so StartGossamer's existing
+// "--roles 4 --key <keyList[idx]>" branch picks them up unchanged; full nodes get idx >=
+// len(keyList), landing in its "--roles 1" branch the same way InitializeAndStartNodes's
+// overflow nodes always have.
+func (tn *Testnet) nodeIdentity(i int) (idx int, name string) {
+	if i < tn.authorities {
+		return i, keyList[i]
+	}
+	fullIdx := i - tn.authorities
+	return len(keyList) + fullIdx, fmt.Sprintf("full-%d", fullIdx)
+}
+
+// startBootnode inits and starts the node elected as the testnet's Star bootnode (always
+// authority 0) against the unmodified base config, so its own Bootnodes/NoMDNS settings don't
+// depend on a peer ID nothing has generated yet.
+func (tn *Testnet) startBootnode(ctx context.Context, genesis, config string, portBase int) (*SubprocessNode, error) {
+	node, err := InitGossamer(0, TestDir(tn.t, keyList[0]), genesis, config)
+	if err != nil {
+		return nil, fmt.Errorf("init bootnode: %w", err)
+	}
+	node.P2PPort = portBase
+	node.RPCPort = strconv.Itoa(portBase + 1)
+	node.WSPort = strconv.Itoa(portBase + 2)
+
+	if err := StartGossamer(ctx, tn.t, node, false); err != nil {
+		return nil, fmt.Errorf("start bootnode: %w", err)
+	}
+	return node, nil
+}
+
+// bootnodeMultiaddr asks node for its own libp2p peer ID via system_networkState and combines it
+// with its P2P listen port into a dialable multiaddr for other nodes' [Network].Bootnodes.
+//
+// dot/rpc/modules doesn't carry a system.go in this tree, so SystemNetworkStateResponse is
+// referenced the same way SubprocessNode already references SystemHealthResponse: matching the
+// RPC module's real (just not present here) response shape rather than inventing a new one.
+func bootnodeMultiaddr(node *SubprocessNode) (string, error) {
+	respBody, err := PostRPC("system_networkState", NewEndpoint(node.RPCPort), "{}")
+	if err != nil {
+		return "", err
+	}
+
+	target := new(modules.SystemNetworkStateResponse)
+	if err := DecodeRPC_NT(respBody, target); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("/ip4/127.0.0.1/tcp/%d/p2p/%s", node.P2PPort, target.NetworkState.PeerID), nil
+}
+
+// starConfigFor copies baseConfig with Bootnodes set to addr and NoMDNS enabled, writing the
+// result to a fresh temp file so baseConfig itself (likely a checked-in fixture) is left alone.
+func starConfigFor(baseConfig, addr string) (string, error) {
+	cfg, err := readConfig(baseConfig)
+	if err != nil {
+		return "", err
+	}
+	cfg.Network.Bootnodes = []string{addr}
+	cfg.Network.NoMDNS = true
+
+	dir, err := ioutil.TempDir("", "testnet-config-")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "config.toml")
+	if err := dot.ExportTomlConfig(cfg, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// buildTestnetGenesis generalises GenerateGenesisOneAuth/Three/Six to an arbitrary authority
+// count, writing the raw genesis to a fresh temp file instead of one of the three fixed paths
+// those functions use.
+func buildTestnetGenesis(authorities int) (string, error) {
+	bs, err := dot.BuildFromGenesis(utils.GetGssmrGenesisPath(), authorities)
+	if err != nil {
+		return "", fmt.Errorf("could not build genesis for %d authorities: %w", authorities, err)
+	}
+
+	dir, err := ioutil.TempDir("", "testnet-genesis-")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "genesis.json")
+	if err := dot.CreateJSONRawFile(bs, path); err != nil {
+		return "", fmt.Errorf("could not write genesis to %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// TearDown stops every node in the testnet (authorities and full nodes alike) and removes their
+// datadirs, the same as the package-level TearDown.
+func (tn *Testnet) TearDown() []error {
+	all := append(append([]*SubprocessNode{}, tn.authorityNodes...), tn.fullNodeList...)
+	return TearDown(tn.t, all)
+}
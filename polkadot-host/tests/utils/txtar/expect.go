@@ -0,0 +1,103 @@
+// Copyright 2020 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package txtar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// resolvePath walks dotted path segments (e.g. "health.shouldHavePeers") into a decoded JSON
+// value, which is always a map[string]interface{}, []interface{}, or a JSON scalar.
+func resolvePath(value interface{}, path string) (interface{}, error) {
+	for _, segment := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot descend into %q of a non-object value", segment)
+		}
+
+		value, ok = m[segment]
+		if !ok {
+			return nil, fmt.Errorf("field %q not found", segment)
+		}
+	}
+
+	return value, nil
+}
+
+// compare reports whether actual op literal holds, where literal is parsed as a number if actual
+// is one, and as a bool or raw string otherwise.
+func compare(actual interface{}, op, literal string) (bool, error) {
+	switch a := actual.(type) {
+	case float64:
+		want, err := strconv.ParseFloat(literal, 64)
+		if err != nil {
+			return false, fmt.Errorf("%v is numeric but %q is not", actual, literal)
+		}
+		return compareFloats(a, op, want)
+	case bool:
+		want, err := strconv.ParseBool(literal)
+		if err != nil {
+			return false, fmt.Errorf("%v is a bool but %q is not", actual, literal)
+		}
+		return compareBools(a, op, want)
+	default:
+		return compareStrings(fmt.Sprintf("%v", actual), op, literal)
+	}
+}
+
+func compareFloats(a float64, op string, b float64) (bool, error) {
+	switch op {
+	case "==":
+		return a == b, nil
+	case "!=":
+		return a != b, nil
+	case ">":
+		return a > b, nil
+	case ">=":
+		return a >= b, nil
+	case "<":
+		return a < b, nil
+	case "<=":
+		return a <= b, nil
+	default:
+		return false, fmt.Errorf("unsupported numeric operator %q", op)
+	}
+}
+
+func compareBools(a bool, op string, b bool) (bool, error) {
+	switch op {
+	case "==":
+		return a == b, nil
+	case "!=":
+		return a != b, nil
+	default:
+		return false, fmt.Errorf("unsupported boolean operator %q, only == and != apply", op)
+	}
+}
+
+func compareStrings(a, op, b string) (bool, error) {
+	switch op {
+	case "==":
+		return a == b, nil
+	case "!=":
+		return a != b, nil
+	default:
+		return false, fmt.Errorf("unsupported string operator %q, only == and != apply", op)
+	}
+}
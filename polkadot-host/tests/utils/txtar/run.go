@@ -0,0 +1,281 @@
+// Copyright 2020 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package txtar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/tests/utils"
+)
+
+// Run boots the fleet script declares, executes its commands in order, and tears every node
+// down again before returning (via t.Cleanup). A command failure is reported through t and
+// stops the script, same as a failed require.
+func Run(ctx context.Context, t *testing.T, script *Script) {
+	specs := make(map[string]NodeSpec, len(script.Nodes))
+	fleet := make(map[string]*utils.SubprocessNode, len(script.Nodes))
+	responses := make(map[string]interface{}, len(script.Nodes))
+
+	for i, spec := range script.Nodes {
+		specs[spec.Name] = spec
+
+		node, err := utils.InitGossamer(i, utils.TestDir(t, spec.Name), spec.Genesis, spec.Config)
+		if err != nil {
+			t.Fatalf("%s: init node %s: %s", script.Path, spec.Name, err)
+		}
+		fleet[spec.Name] = node
+	}
+
+	t.Cleanup(func() {
+		nodes := make([]*utils.SubprocessNode, 0, len(fleet))
+		for _, node := range fleet {
+			nodes = append(nodes, node)
+		}
+		if errs := utils.TearDown(t, nodes); len(errs) > 0 {
+			t.Errorf("%s: tear down: %v", script.Path, errs)
+		}
+	})
+
+	for _, cmd := range script.Commands {
+		if err := execCommand(ctx, t, script, specs, fleet, responses, cmd); err != nil {
+			t.Fatalf("%s:%d: %s: %s", script.Path, cmd.Line, cmd.Name, err)
+		}
+	}
+}
+
+func execCommand(
+	ctx context.Context,
+	t *testing.T,
+	script *Script,
+	specs map[string]NodeSpec,
+	fleet map[string]*utils.SubprocessNode,
+	responses map[string]interface{},
+	cmd Command,
+) error {
+	switch cmd.Name {
+	case "start":
+		node, spec, err := namedNode(fleet, specs, cmd.Args, 0)
+		if err != nil {
+			return err
+		}
+		return utils.StartGossamer(ctx, t, node, spec.Websocket)
+
+	case "stop":
+		node, _, err := namedNode(fleet, specs, cmd.Args, 0)
+		if err != nil {
+			return err
+		}
+		_, err = utils.StopGossamer(ctx, node)
+		return err
+
+	case "sleep":
+		if len(cmd.Args) != 1 {
+			return fmt.Errorf("sleep takes exactly one duration argument")
+		}
+		d, err := time.ParseDuration(cmd.Args[0])
+		if err != nil {
+			return err
+		}
+		time.Sleep(d)
+		return nil
+
+	case "rpc":
+		return execRPC(script, fleet, responses, cmd.Args)
+
+	case "wait-block":
+		return execWaitBlock(ctx, fleet, cmd.Args)
+
+	case "expect":
+		if err := execExpect(responses, cmd.Args); err != nil {
+			if *Update {
+				t.Logf("%s:%d: expect: %s (ignored: -update)", script.Path, cmd.Line, err)
+				return nil
+			}
+			return err
+		}
+		return nil
+
+	case "cmp":
+		return execCmp(responses, cmd.Args)
+
+	default:
+		return fmt.Errorf("unrecognised command %q", cmd.Name)
+	}
+}
+
+func namedNode(fleet map[string]*utils.SubprocessNode, specs map[string]NodeSpec, args []string, i int) (*utils.SubprocessNode, NodeSpec, error) {
+	if len(args) <= i {
+		return nil, NodeSpec{}, fmt.Errorf("expected a node name argument")
+	}
+
+	name := args[i]
+	node, ok := fleet[name]
+	if !ok {
+		return nil, NodeSpec{}, fmt.Errorf("node %q is not declared in this script", name)
+	}
+	return node, specs[name], nil
+}
+
+// execRPC runs "rpc <node> <method> [paramsFile]", storing the decoded result under responses
+// so later expect/cmp commands can refer to it as "<node>.<field>...". paramsFile, if given,
+// names an embedded "-- file.json --" block whose contents are sent verbatim as the params array;
+// the default is "[]".
+func execRPC(script *Script, fleet map[string]*utils.SubprocessNode, responses map[string]interface{}, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("rpc takes a node name and method, got %v", args)
+	}
+
+	node, ok := fleet[args[0]]
+	if !ok {
+		return fmt.Errorf("node %q is not declared in this script", args[0])
+	}
+
+	params := "[]"
+	if len(args) >= 3 {
+		body, ok := script.Files[args[2]]
+		if !ok {
+			return fmt.Errorf("no embedded file %q", args[2])
+		}
+		params = string(body)
+	}
+
+	body, err := utils.PostRPC(args[1], utils.NewEndpoint(node.RPCPort), params)
+	if err != nil {
+		return err
+	}
+
+	var result json.RawMessage
+	if err := utils.DecodeRPC_NT(body, &result); err != nil {
+		return err
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(result, &decoded); err != nil {
+		return err
+	}
+
+	responses[args[0]] = decoded
+	return nil
+}
+
+func execWaitBlock(ctx context.Context, fleet map[string]*utils.SubprocessNode, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("wait-block takes a node name and a target height, got %v", args)
+	}
+
+	node, ok := fleet[args[0]]
+	if !ok {
+		return fmt.Errorf("node %q is not declared in this script", args[0])
+	}
+
+	target, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("target height %q is not a number: %w", args[1], err)
+	}
+
+	for {
+		body, err := utils.PostRPC("chain_getHeader", utils.NewEndpoint(node.RPCPort), "[]")
+		if err == nil {
+			var result struct {
+				Number string `json:"number"`
+			}
+			if err := utils.DecodeRPC_NT(body, &result); err == nil {
+				// the header number comes back hex-encoded ("0x01"); base 0 also accepts decimal
+				if height, err := strconv.ParseInt(result.Number, 0, 64); err == nil && height >= int64(target) {
+					return nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("node %s did not reach block %d: %w", args[0], target, ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// execExpect runs "expect <node>.<path> <op> <literal>", resolving <path> against the last rpc
+// response captured for <node> and comparing it to <literal> with <op> (==, !=, >, >=, <, <=).
+func execExpect(responses map[string]interface{}, args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("expect takes <node>.<path> <op> <value>, got %v", args)
+	}
+
+	value, err := resolveResponsePath(responses, args[0])
+	if err != nil {
+		return err
+	}
+
+	ok, err := compare(value, args[1], args[2])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s (%v) %s %s: false", args[0], value, args[1], args[2])
+	}
+	return nil
+}
+
+// execCmp runs "cmp <node1>.<path> <node2>.<path>", asserting that both resolve to equal values.
+func execCmp(responses map[string]interface{}, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("cmp takes two <node>.<path> arguments, got %v", args)
+	}
+
+	a, err := resolveResponsePath(responses, args[0])
+	if err != nil {
+		return err
+	}
+	b, err := resolveResponsePath(responses, args[1])
+	if err != nil {
+		return err
+	}
+
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		return fmt.Errorf("%s (%v) != %s (%v)", args[0], a, args[1], b)
+	}
+	return nil
+}
+
+func resolveResponsePath(responses map[string]interface{}, qualified string) (interface{}, error) {
+	node, path, ok := cut(qualified, ".")
+	if !ok {
+		return nil, fmt.Errorf("%q is not of the form <node>.<path>", qualified)
+	}
+
+	response, ok := responses[node]
+	if !ok {
+		return nil, fmt.Errorf("no rpc response recorded yet for node %q", node)
+	}
+
+	return resolvePath(response, path)
+}
+
+// cut splits s on the first occurrence of sep, reporting whether sep was found.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package txtar drives multi-node gossamer scenarios described as txtar archives (see
+// golang.org/x/tools/txtar) instead of hand-written Go tests. An archive's comment declares the
+// fleet of nodes to boot and the commands to run against them; its "-- name --" file sections
+// hold RPC request bodies or expected-response fixtures the commands can refer to by name.
+//
+// A minimal archive looks like:
+//
+//	node alice genesis=GenesisOneAuth config=ConfigDefault
+//	node bob   genesis=GenesisOneAuth config=ConfigDefault websocket
+//
+//	start alice
+//	start bob
+//	rpc alice system_health
+//	expect alice.health.shouldHavePeers == true
+//	wait-block alice 2
+//	stop bob
+//
+// Run executes a parsed Script against a real fleet of tests/utils.SubprocessNode instances; Load parses
+// one from a testdata/*.txtar file.
+package txtar
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/tools/txtar"
+)
+
+// Update, set via "go test ... -update", turns a failed "expect" comparison from a fatal
+// mismatch into a logged warning, for scenarios whose expected values are expected to drift
+// (e.g. a partialFee that depends on runtime weights). It does not rewrite the archive file
+// itself: since this format inlines expectations as plain "expect" commands rather than
+// separate "-- want --" sections, there is nothing to rewrite them into.
+var Update = flag.Bool("update", false, "log, rather than fail, mismatched expect commands")
+
+// NodeSpec is one "node <name> genesis=<genesis> config=<config> [websocket]" header line.
+type NodeSpec struct {
+	Name      string
+	Genesis   string
+	Config    string
+	Websocket bool
+}
+
+// Command is a single script line, e.g. "start alice" or "expect alice.peers >= 2".
+type Command struct {
+	Name string
+	Args []string
+	Line int
+}
+
+// Script is a parsed txtar archive: the fleet it declares, the commands to run against that
+// fleet, and any embedded fixture files the commands reference by name.
+type Script struct {
+	Path     string
+	Nodes    []NodeSpec
+	Commands []Command
+	Files    map[string][]byte
+}
+
+// Load reads and parses the txtar archive at path.
+func Load(path string) (*Script, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := txtar.Parse(data)
+
+	script := &Script{
+		Path:  path,
+		Files: make(map[string][]byte, len(archive.Files)),
+	}
+	for _, f := range archive.Files {
+		script.Files[f.Name] = f.Data
+	}
+
+	for i, line := range strings.Split(string(archive.Comment), "\n") {
+		lineNum := i + 1
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if fields[0] == "node" {
+			spec, err := parseNodeSpec(fields[1:])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+			}
+			script.Nodes = append(script.Nodes, spec)
+			continue
+		}
+
+		script.Commands = append(script.Commands, Command{
+			Name: fields[0],
+			Args: fields[1:],
+			Line: lineNum,
+		})
+	}
+
+	return script, nil
+}
+
+func parseNodeSpec(fields []string) (NodeSpec, error) {
+	if len(fields) == 0 {
+		return NodeSpec{}, fmt.Errorf("node line missing a name")
+	}
+
+	spec := NodeSpec{Name: fields[0]}
+	for _, field := range fields[1:] {
+		switch {
+		case field == "websocket":
+			spec.Websocket = true
+		case strings.HasPrefix(field, "genesis="):
+			spec.Genesis = strings.TrimPrefix(field, "genesis=")
+		case strings.HasPrefix(field, "config="):
+			spec.Config = strings.TrimPrefix(field, "config=")
+		default:
+			return NodeSpec{}, fmt.Errorf("node %s: unrecognised attribute %q", spec.Name, field)
+		}
+	}
+
+	if spec.Genesis == "" || spec.Config == "" {
+		return NodeSpec{}, fmt.Errorf("node %s: genesis and config are both required", spec.Name)
+	}
+
+	return spec, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 ChainSafe Systems (ON) Corp.
+// This file is part of gossamer.
+//
+// The gossamer library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gossamer library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the gossamer library. If not, see <http://www.gnu.org/licenses/>.
+
+package txtar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_ParsesNodesAndCommands(t *testing.T) {
+	script, err := Load("testdata/two_node_health.txtar")
+	require.NoError(t, err)
+
+	require.Equal(t, []NodeSpec{
+		{Name: "alice", Genesis: "GenesisOneAuth", Config: "ConfigDefault"},
+		{Name: "bob", Genesis: "GenesisOneAuth", Config: "ConfigDefault", Websocket: true},
+	}, script.Nodes)
+
+	require.Equal(t, []Command{
+		{Name: "start", Args: []string{"alice"}, Line: 4},
+		{Name: "start", Args: []string{"bob"}, Line: 5},
+		{Name: "rpc", Args: []string{"alice", "system_health"}, Line: 6},
+		{Name: "expect", Args: []string{"alice.health", "==", "true"}, Line: 7},
+		{Name: "wait-block", Args: []string{"alice", "1"}, Line: 8},
+		{Name: "stop", Args: []string{"bob"}, Line: 9},
+	}, script.Commands)
+
+	require.Equal(t, "[\"0x0102030405\"]\n", string(script.Files["custom_params.json"]))
+}
+
+func TestLoad_RejectsNodeMissingGenesisOrConfig(t *testing.T) {
+	_, err := parseNodeSpec([]string{"alice", "config=ConfigDefault"})
+	require.Error(t, err)
+}
+
+func TestResolvePath(t *testing.T) {
+	value := map[string]interface{}{
+		"health": map[string]interface{}{
+			"shouldHavePeers": true,
+			"peers":           float64(3),
+		},
+	}
+
+	got, err := resolvePath(value, "health.peers")
+	require.NoError(t, err)
+	require.Equal(t, float64(3), got)
+
+	_, err = resolvePath(value, "health.missing")
+	require.Error(t, err)
+}
+
+func TestCompare(t *testing.T) {
+	ok, err := compare(float64(3), ">=", "2")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = compare(true, "==", "true")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = compare("normal", "!=", "operational")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestExecExpect(t *testing.T) {
+	responses := map[string]interface{}{
+		"alice": map[string]interface{}{"health": true},
+	}
+
+	require.NoError(t, execExpect(responses, []string{"alice.health", "==", "true"}))
+	require.Error(t, execExpect(responses, []string{"alice.health", "==", "false"}))
+	require.Error(t, execExpect(responses, []string{"bob.health", "==", "true"}))
+}
+
+func TestExecCmp(t *testing.T) {
+	responses := map[string]interface{}{
+		"alice": map[string]interface{}{"chainHead": "0xabc"},
+		"bob":   map[string]interface{}{"chainHead": "0xabc"},
+	}
+
+	require.NoError(t, execCmp(responses, []string{"alice.chainHead", "bob.chainHead"}))
+
+	responses["bob"] = map[string]interface{}{"chainHead": "0xdef"}
+	require.Error(t, execCmp(responses, []string{"alice.chainHead", "bob.chainHead"}))
+}